@@ -27,7 +27,7 @@ const (
 	// CHAT
 	CMD_CHAT_SEND_TEXT    = "chat.send_text"
 	CMD_CHAT_SEND_GIF     = "chat.send_gif"
-	CMD_CHAT_SEND_CALL    = "chat.send_call"
+	CMD_CHAT_SEND_CALL    = "chat.send_call" // Arama başlat (WebRTC offer)
 	CMD_CHAT_SEND_STICKER = "chat.send_sticker"
 
 	// USER
@@ -58,6 +58,7 @@ const (
 	CMD_USER_BLOCK        = "user.block"
 	CMD_USER_UNBLOCK      = "user.unblock"
 	CMD_USER_TOGGLE_BLOCK = "user.block.toggle"
+	CMD_USER_TOGGLE_MUTE  = "user.mute.toggle" // Kullanıcıyı akışta sessize al
 	CMD_USER_REPORT       = "user.report"
 
 	CMD_USER_FETCH_NEARBY_USERS      = "user.fetch.nearby.users"
@@ -84,6 +85,19 @@ const (
 	CMD_POST_VIEW     = "post.view"
 	CMD_POST_BANANA   = "post.banana"
 
+	CMD_POST_TIMELINE_FOLLOWING = "post.timeline.following" // Takip edilenlerin akışı
+	CMD_POST_TIMELINE_FOR_YOU   = "post.timeline.for_you"   // Sıralanmış keşfet akışı
+
+	CMD_POST_REVISIONS = "post.revisions" // Postun düzenleme geçmişi
+
+	CMD_POST_REPOST = "post.repost" // Postu yeniden paylaşma / geri alma
+
+	CMD_POST_THREAD = "post.thread" // Ata zinciri ve sayfalı yanıt ağacı
+
+	CMD_POST_DRAFTS          = "post.drafts"          // Kullanıcının taslakları
+	CMD_POST_SCHEDULED       = "post.scheduled"       // Kullanıcının zamanlanmış postları
+	CMD_POST_SCHEDULE_CANCEL = "post.schedule.cancel" // Zamanlanmış yayını iptal edip taslağa çevirme
+
 	//MATCH EKRANI
 	CMD_MATCH_CREATE = "match.create" // Yeni eşleşme oluşturma (örneğin karşılıklı like)
 	CMD_MATCH_DELETE = "match.delete" // Eşleşmeyi kaldırma
@@ -99,13 +113,70 @@ const (
 	CMD_SEARCH_LOOKUP_USER = "search.user.lookup"
 	CMD_SEARCH_TRENDS      = "search.trends"
 
-	CMD_CHAT_CREATE    = "chat.create" // Chat olustur
-	CMD_TYPING         = "chat.typing"
-	CMD_SEND_MESSAGE   = "chat.send_message"   // Mesaj gönder
-	CMD_DELETE_CHAT    = "chat.delete_chat"    // Sohbeti sil
-	CMD_FETCH_CHATS    = "chat.fetch_chats"    // Sohbetleri getir
-	CMD_DELETE_MESSAGE = "chat.delete_message" // Mesajı sil
-	CMD_FETCH_MESSAGES = "chat.fetch_messages" // Mesajları getir
+	CMD_HASHTAG_FETCH_POSTS = "hashtag.fetch_posts" // Etiketin postları ve bilgileri
+	CMD_HASHTAG_FOLLOW      = "hashtag.follow"      // Etiketi takip et
+	CMD_HASHTAG_UNFOLLOW    = "hashtag.unfollow"    // Etiketi takipten çık
+	CMD_HASHTAG_FOLLOWED    = "hashtag.followed"    // Takip edilen etiketler
+
+	CMD_CHAT_CREATE     = "chat.create" // Chat olustur
+	CMD_TYPING          = "chat.typing"
+	CMD_SEND_MESSAGE    = "chat.send_message"    // Mesaj gönder
+	CMD_DELETE_CHAT     = "chat.delete_chat"     // Sohbeti sil
+	CMD_FETCH_CHATS     = "chat.fetch_chats"     // Sohbetleri getir
+	CMD_DELETE_MESSAGE  = "chat.delete_message"  // Mesajı sil
+	CMD_FETCH_MESSAGES  = "chat.fetch_messages"  // Mesajları getir
+	CMD_MARK_DELIVERED  = "chat.mark_delivered"  // Mesajlar iletildi (socket)
+	CMD_MARK_READ       = "chat.mark_read"       // Mesajları okundu işaretle
+	CMD_RECEIPT         = "chat.receipt"         // İletildi / okundu bildirimi
+	CMD_PIN_MESSAGE     = "chat.pin_message"     // Mesajı sabitle / kaldır
+	CMD_EDIT_MESSAGE    = "chat.edit_message"    // Mesajı düzenle
+	CMD_MESSAGE_EDITS   = "chat.message_edits"   // Mesajın düzenleme geçmişi
+	CMD_MESSAGE_UPDATED = "chat.message_updated" // Mesaj güncellendi bildirimi
+	CMD_REACT_MESSAGE   = "chat.react"           // Mesaja emoji tepkisi ekle / kaldır
+	CMD_FORWARD_MESSAGE = "chat.forward_message" // Mesajı başka sohbetlere ilet
+
+	// Kullanıcıya özel sohbet ayarları
+	CMD_CHAT_MUTE      = "chat.mute" // Sohbeti sessize al (until verilmezse süresiz)
+	CMD_CHAT_UNMUTE    = "chat.unmute"
+	CMD_CHAT_ARCHIVE   = "chat.archive" // Sohbeti arşivle
+	CMD_CHAT_UNARCHIVE = "chat.unarchive"
+	CMD_CHAT_PIN       = "chat.pin" // Sohbeti listenin başına sabitle
+	CMD_CHAT_UNPIN     = "chat.unpin"
+
+	CMD_CHAT_SEARCH = "chat.search" // Sohbet geçmişinde tam metin arama
+
+	// Arama sinyalleri (socket)
+	CMD_CALL_STARTED = "chat.call.started" // Arayana: arama kaydı oluşturuldu
+	CMD_CALL_RINGING = "chat.call.ringing"
+	CMD_CALL_ACCEPT  = "chat.call.accept"
+	CMD_CALL_ANSWER  = "chat.call.answer" // SDP answer
+	CMD_CALL_OFFER   = "chat.call.offer"  // Devam eden aramada yeniden müzakere
+	CMD_CALL_ICE     = "chat.call.ice"
+	CMD_CALL_REJECT  = "chat.call.reject"
+	CMD_CALL_HANGUP  = "chat.call.hangup"
+	CMD_CALL_ENDED   = "chat.call.ended"
+	CMD_CALL_ERROR   = "chat.call.error"
+
+	CMD_LIVE_LOCATION_START  = "chat.live_location.start"
+	CMD_LIVE_LOCATION_UPDATE = "chat.live_location.update" // socket: konum güncellemesi
+	CMD_LIVE_LOCATION_STOP   = "chat.live_location.stop"
+	CMD_LIVE_LOCATION_FETCH  = "chat.live_location.fetch" // Sohbetteki aktif paylaşımlar
+
+	CMD_SET_DISAPPEARING = "chat.set_disappearing" // Kaybolan mesaj süresini ayarla
+	CMD_OPEN_VIEW_ONCE   = "chat.open_view_once"   // Tek seferlik eki aç
+	CMD_VIEW_ONCE_OPENED = "chat.view_once_opened" // Gönderene: tek seferlik ek açıldı
+
+	CMD_FETCH_CHAT_REQUESTS = "chat.fetch_requests" // Mesaj istekleri kutusu
+	CMD_CHAT_REQUEST_ACCEPT = "chat.request.accept" // Mesaj isteğini kabul et
+	CMD_CHAT_REQUEST_DELETE = "chat.request.delete" // Mesaj isteğini sil
+	CMD_CHAT_REQUEST_BLOCK  = "chat.request.block"  // Göndereni engelle ve isteği sil
+
+	CMD_GROUP_CREATE   = "chat.group.create"             // Grup oluştur
+	CMD_GROUP_INVITE   = "chat.group.invite"             // Gruba üye ekle
+	CMD_GROUP_REMOVE   = "chat.group.remove"             // Gruptan üye çıkar
+	CMD_GROUP_PROMOTE  = "chat.group.promote"            // Üyeyi admin yap
+	CMD_GROUP_LEAVE    = "chat.group.leave"              // Gruptan ayrıl
+	CMD_GROUP_TRANSFER = "chat.group.transfer_ownership" // Sahipliği devret
 
 )
 
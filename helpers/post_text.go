@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&])#([\p{L}\p{N}_]+)`)
+	mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])@([\p{L}\p{N}_.]+)`)
+)
+
+// ExtractHashtags metindeki #etiketleri ve formdan gelen etiketleri tekrarsız
+// döndürür; "#" öneki atılır, karşılaştırma büyük/küçük harf duyarsızdır
+func ExtractHashtags(text string, explicit []string) []string {
+	return mergeTokens(explicit, hashtagPattern.FindAllStringSubmatch(text, -1), "#")
+}
+
+// NormalizeHashtag "#" önekini ve boşlukları atar, etiketi küçük harfe çevirir.
+// Metinde etiket olarak yakalanamayacak değerler için false döner.
+func NormalizeHashtag(raw string) (string, bool) {
+	tag := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(raw), "#"))
+	matches := hashtagPattern.FindAllStringSubmatch("#"+tag, -1)
+	if len(matches) != 1 || matches[0][1] != tag || len([]rune(tag)) > 100 {
+		return "", false
+	}
+	return tag, true
+}
+
+// ExtractMentions metindeki @kullanıcı adlarını ve formdan gelenleri tekrarsız döndürür
+func ExtractMentions(text string, explicit []string) []string {
+	return mergeTokens(explicit, mentionPattern.FindAllStringSubmatch(text, -1), "@")
+}
+
+func mergeTokens(explicit []string, matches [][]string, prefix string) []string {
+	seen := map[string]bool{}
+	tokens := make([]string, 0, len(explicit)+len(matches))
+	add := func(token string) {
+		token = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(token), prefix), ".")
+		key := strings.ToLower(token)
+		if token == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		tokens = append(tokens, token)
+	}
+	for _, token := range explicit {
+		add(token)
+	}
+	for _, match := range matches {
+		add(match[1])
+	}
+	return tokens
+}
@@ -28,6 +28,8 @@ type Chat struct {
 	LastMessage          *post.Post `gorm:"foreignKey:LastMessageID;references:ID" json:"last_message,omitempty"`
 	LastMessageTimestamp *time.Time `gorm:"last_message_timestamp" json:"last_message_timestamp,omitempty"`
 
+	DisappearAfter *int64 `gorm:"null" json:"disappear_after,omitempty"` // Kaybolan mesajlar: saniye cinsinden süre, nil ise kapalı
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -0,0 +1,35 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LiveLocation sohbette süre sınırlı canlı konum paylaşımı. Konum güncellemeleri
+// socket üzerinden yayınlanır; tabloda sadece son bilinen konum tutulur.
+type LiveLocation struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	ChatID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"chat_id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	MessageID uuid.UUID  `gorm:"type:uuid;index;not null" json:"message_id"` // Paylaşımı gösteren sohbet mesajı
+	StartedAt time.Time  `json:"started_at"`
+	ExpiresAt time.Time  `gorm:"index" json:"expires_at"`
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+
+	Latitude  float64  `gorm:"type:numeric(10,6)" json:"latitude"`
+	Longitude float64  `gorm:"type:numeric(10,6)" json:"longitude"`
+	Accuracy  *float64 `json:"accuracy,omitempty"` // metre
+	Heading   *float64 `json:"heading,omitempty"`  // derece
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (LiveLocation) TableName() string {
+	return "chats_live_locations"
+}
+
+// IsActive paylaşım hâlâ sürüyor mu
+func (l *LiveLocation) IsActive(now time.Time) bool {
+	return l.StoppedAt == nil && now.Before(l.ExpiresAt)
+}
@@ -30,8 +30,6 @@ type Message struct {
 	ReplyTo       *Message `gorm:"foreignKey:ReplyToID"`
 	ForwardedFrom *models.User
 
-	Reads []MessageRead `gorm:"foreignKey:MessageID"`
-
 	CreatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
@@ -0,0 +1,97 @@
+package chat
+
+import (
+	"coolvibes/models/post"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Alıntılanan mesaj önizlemesinin maksimum uzunluğu (karakter)
+const replyPreviewLength = 120
+
+func extraEquals(p *post.Post, key, value string) bool {
+	v, ok := p.GetExtra(key)
+	return ok && fmt.Sprint(v) == value
+}
+
+// IsSystemMessage grup olayları için oluşturulan sistem mesajı mı
+func IsSystemMessage(p *post.Post) bool {
+	return extraEquals(p, ExtraMessageType, string(System))
+}
+
+// IsTombstoned mesaj herkesten silinmiş mi
+func IsTombstoned(p *post.Post) bool {
+	return extraEquals(p, ExtraMessageStatus, string(Deleted))
+}
+
+// IsViewOnce mesajın ekleri tek seferlik mi
+func IsViewOnce(p *post.Post) bool {
+	return extraEquals(p, ExtraViewOnce, "true")
+}
+
+// ViewOnceOpenedBy tek seferlik ekleri açmış kullanıcılar
+func ViewOnceOpenedBy(p *post.Post) []string {
+	v, ok := p.GetExtra(ExtraViewOnceOpenedBy)
+	if !ok {
+		return nil
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	ids := make([]string, 0, len(list))
+	for _, id := range list {
+		ids = append(ids, fmt.Sprint(id))
+	}
+	return ids
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n]) + "…"
+}
+
+// ReplyPreview alıntılı cevaplarda Extras["reply_to"] içine yazılan önizleme.
+// Orijinal mesaj düzenlense de cevap bağlamını korur; mesaj silinirse
+// içerik boşaltılır ve "deleted" işaretlenir.
+func ReplyPreview(original *post.Post) map[string]any {
+	content := map[string]string{}
+	if original.Content != nil {
+		for lang, text := range *original.Content {
+			content[lang] = truncateRunes(text, replyPreviewLength)
+		}
+	}
+
+	return map[string]any{
+		"id":                 original.ID,
+		"public_id":          strconv.FormatInt(original.PublicID, 10),
+		"author_id":          original.AuthorID,
+		"author_username":    original.Author.UserName,
+		"author_displayname": original.Author.DisplayName,
+		"content":            content,
+		"attachment_count":   len(original.Attachments),
+	}
+}
+
+// ForwardAttribution iletilen mesajın orijinal yazarını tutar. Mesaj zaten
+// iletilmiş bir mesajsa ilk yazarın bilgisi korunur.
+func ForwardAttribution(original *post.Post) any {
+	if v, ok := original.GetExtra(ExtraForwardedFrom); ok && v != nil {
+		return v
+	}
+
+	return map[string]any{
+		"message_id":         original.ID,
+		"public_id":          strconv.FormatInt(original.PublicID, 10),
+		"chat_id":            original.ContentableID,
+		"author_id":          original.AuthorID,
+		"author_public_id":   strconv.FormatInt(original.Author.PublicID, 10),
+		"author_username":    original.Author.UserName,
+		"author_displayname": original.Author.DisplayName,
+		"sent_at":            original.CreatedAt,
+	}
+}
@@ -0,0 +1,21 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageReaction bir kullanıcının sohbet mesajına bıraktığı emoji
+type MessageReaction struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MessageID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_messages_reactions_unique;not null" json:"message_id"`
+	ChatID    uuid.UUID `gorm:"type:uuid;index;not null" json:"chat_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_messages_reactions_unique;not null" json:"user_id"`
+	Emoji     string    `gorm:"type:varchar(32);uniqueIndex:idx_messages_reactions_unique;not null" json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (MessageReaction) TableName() string {
+	return "messages_reactions"
+}
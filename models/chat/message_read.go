@@ -7,14 +7,17 @@ import (
 	"github.com/google/uuid"
 )
 
+// MessageRead bir sohbet mesajının (posts tablosu) katılımcıya iletildiği ve
+// katılımcı tarafından okunduğu zamanı tutar
 type MessageRead struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
-	MessageID uuid.UUID `gorm:"type:uuid;index;not null"`
-	UserID    uuid.UUID `gorm:"type:uuid;index;not null"`
-	ReadAt    time.Time `gorm:"autoCreateTime"`
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MessageID   uuid.UUID  `gorm:"type:uuid;uniqueIndex:idx_messages_reads_message_user;not null" json:"message_id"`
+	ChatID      uuid.UUID  `gorm:"type:uuid;index" json:"chat_id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;uniqueIndex:idx_messages_reads_message_user;index;not null" json:"user_id"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	ReadAt      *time.Time `gorm:"index" json:"read_at,omitempty"`
 
-	Message Message
-	User    models.User
+	User models.User `json:"user,omitempty"`
 }
 
 func (MessageRead) TableName() string {
@@ -0,0 +1,33 @@
+package chat
+
+import (
+	"coolvibes/models/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageRevision düzenlenen sohbet mesajının önceki içeriği
+type MessageRevision struct {
+	ID        uuid.UUID              `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MessageID uuid.UUID              `gorm:"type:uuid;index;not null" json:"message_id"`
+	EditorID  uuid.UUID              `gorm:"type:uuid;not null" json:"editor_id"`
+	Content   *utils.LocalizedString `gorm:"type:jsonb" json:"content,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+func (MessageRevision) TableName() string {
+	return "messages_revisions"
+}
+
+// MessageHidden "benden sil" ile kullanıcının göremeyeceği mesajlar
+type MessageHidden struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MessageID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_messages_hidden_message_user;not null" json:"message_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_messages_hidden_message_user;index;not null" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (MessageHidden) TableName() string {
+	return "messages_hidden"
+}
@@ -13,6 +13,7 @@ type ChatParticipant struct {
 	UserID      uuid.UUID       `gorm:"type:uuid;index;not null" json:"user_id"`
 	Role        ParticipantRole `gorm:"type:varchar(32);default:'member'" json:"role"`
 	IsMuted     bool            `json:"is_muted"`
+	MutedUntil  *time.Time      `json:"muted_until,omitempty"` // nil ise süresiz sessiz
 	JoinedAt    time.Time       `json:"joined_at"`
 	LeftAt      *time.Time      `json:"left_at,omitempty"`
 	UnreadCount int             `gorm:"default:0" json:"unread_count"`
@@ -22,4 +23,17 @@ type ChatParticipant struct {
 	CreatedAt  time.Time  `json:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at"`
 	LastReadAt *time.Time `json:"last_read_at,omitempty"`
+	HiddenAt   *time.Time `json:"hidden_at,omitempty"` // "sohbeti benden sil" zamanı; öncesindeki mesajlar gösterilmez
+
+	// Takip etmediği ve eşleşmediği birinden gelen sohbet: mesaj istekleri kutusunda durur
+	IsRequest         bool       `gorm:"default:false;index" json:"is_request"`
+	RequestAcceptedAt *time.Time `json:"request_accepted_at,omitempty"`
+
+	ArchivedAt *time.Time `gorm:"index" json:"archived_at,omitempty"`
+	PinnedAt   *time.Time `gorm:"index" json:"pinned_at,omitempty"` // Sabitlenen sohbetler listenin başında durur
+}
+
+// MutedAt sohbet verilen anda sessize alınmış mı
+func (p *ChatParticipant) MutedAt(now time.Time) bool {
+	return p.IsMuted && (p.MutedUntil == nil || now.Before(*p.MutedUntil))
 }
@@ -10,6 +10,7 @@ import (
 type CallStatus string
 
 const (
+	CallRinging  CallStatus = "ringing"
 	CallMissed   CallStatus = "missed"
 	CallRejected CallStatus = "rejected"
 	CallEnded    CallStatus = "ended"
@@ -18,13 +19,14 @@ const (
 
 type Call struct {
 	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ChatID     uuid.UUID  `gorm:"type:uuid;not null;index"`
 	CallerID   uuid.UUID  `gorm:"type:uuid;not null;index"`
 	ReceiverID uuid.UUID  `gorm:"type:uuid;not null;index"`
 	CallType   string     `gorm:"type:varchar(8);not null"` // audio, video
 	Status     CallStatus `gorm:"type:varchar(16);not null"`
 	Duration   int        `gorm:"not null"` // saniye cinsinden
 
-	StartedAt *time.Time
+	StartedAt *time.Time // Cevaplandığı an
 	EndedAt   *time.Time
 	Note      *string
 
@@ -34,3 +36,16 @@ type Call struct {
 func (Call) TableName() string {
 	return "messages_call"
 }
+
+// IsActive arama hâlâ çalıyor veya devam ediyor mu
+func (c *Call) IsActive() bool {
+	return c.Status == CallRinging || c.Status == CallOngoing
+}
+
+// OtherParty aramadaki karşı tarafın id'si
+func (c *Call) OtherParty(userID uuid.UUID) uuid.UUID {
+	if userID == c.CallerID {
+		return c.ReceiverID
+	}
+	return c.CallerID
+}
@@ -4,6 +4,7 @@ type MessageType string
 type ParticipantRole string
 type MessageStatus string
 type ChatType string
+type SystemEvent string
 
 const (
 	Text      MessageType = "text"
@@ -28,8 +29,64 @@ const (
 	Deleted   MessageStatus = "deleted"
 )
 
+// ReceiptStatus mesajın iletim durumu: alıcıların tamamı gördüyse seen,
+// tamamına iletildiyse delivered, aksi halde pending
+func ReceiptStatus(recipients int64, delivered int64, seen int64) MessageStatus {
+	switch {
+	case recipients > 0 && seen >= recipients:
+		return Seen
+	case recipients > 0 && delivered >= recipients:
+		return Delivered
+	}
+	return Pending
+}
+
 const (
 	ChatTypePrivate ChatType = "private"
 	ChatTypeGroup   ChatType = "group"
 	ChatTypeChannel ChatType = "channel"
 )
+
+const (
+	RoleOwner  ParticipantRole = "owner"
+	RoleAdmin  ParticipantRole = "admin"
+	RoleMember ParticipantRole = "member"
+)
+
+// Sistem mesajlarında (MessageType System) Post.Extras içine yazılan olaylar
+const (
+	SystemEventGroupCreated         SystemEvent = "group_created"
+	SystemEventMemberAdded          SystemEvent = "member_added"
+	SystemEventMemberRemoved        SystemEvent = "member_removed"
+	SystemEventMemberLeft           SystemEvent = "member_left"
+	SystemEventAdminPromoted        SystemEvent = "admin_promoted"
+	SystemEventOwnershipTransferred SystemEvent = "ownership_transferred"
+	SystemEventMessagePinned        SystemEvent = "message_pinned"
+	SystemEventMessageUnpinned      SystemEvent = "message_unpinned"
+	SystemEventDisappearingChanged  SystemEvent = "disappearing_changed"
+)
+
+// Sohbet mesajlarının Post.Extras anahtarları
+const (
+	ExtraMessageType = "message_type"
+	ExtraSystemEvent = "system_event"
+	ExtraActorID     = "actor_id"
+	ExtraTargetID    = "target_id"
+
+	ExtraMessageStatus = "message_status" // Deleted: herkesten silinmiş mesaj (tombstone)
+	ExtraDeletedBy     = "deleted_by"
+	ExtraReplyTo       = "reply_to"
+	ExtraForwardedFrom = "forwarded_from"
+
+	ExtraLiveLocation = "live_location" // {session_id, expires_at, live}
+
+	ExtraCallID       = "call_id"
+	ExtraCallStatus   = "call_status"
+	ExtraCallDuration = "call_duration" // saniye
+
+	ExtraViewOnce         = "view_once"           // Ekler alıcı başına bir kez açılabilir
+	ExtraViewOnceMedia    = "view_once_media"     // Gizlenen ek sayısı
+	ExtraViewOnceOpenedBy = "view_once_opened_by" // Açan kullanıcıların id listesi
+	ExtraViewOncePurgeAt  = "view_once_purge_at"  // Dosyaların diskten silineceği zaman
+	ExtraViewOnceConsumed = "view_once_consumed"  // Dosyalar silindi
+)
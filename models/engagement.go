@@ -35,10 +35,13 @@ const (
 
 	EngagementKindPost      EngagementKind = "post"
 	EngagementKindComment   EngagementKind = "comment"
+	EngagementKindRepost    EngagementKind = "repost" // postu yeniden paylaşanlar
+	EngagementKindQuote     EngagementKind = "quote"  // postu alıntılayanlar
 	EngagementKindFollower  EngagementKind = "follower"
 	EngagementKindFollowing EngagementKind = "following"
 	EngagementKindBlockedBy EngagementKind = "blocked_by" // seni engelleyenler
 	EngagementKindBlocking  EngagementKind = "blocking"   // senin engellediklerin
+	EngagementKindMuting    EngagementKind = "muting"     // senin sessize aldıkların (akışta gösterilmez)
 	EngagementKindView      EngagementKind = "view"
 
 	EngagementKindBookmark EngagementKind = "bookmark"
@@ -72,11 +75,14 @@ var EngagementCountKeys = map[EngagementKind]struct {
 
 	EngagementKindPost:      {"post_count", ""},
 	EngagementKindComment:   {"comment_count", ""},
+	EngagementKindRepost:    {"repost_count", ""},
+	EngagementKindQuote:     {"quote_count", ""},
 	EngagementKindFollower:  {"follower_count", ""},
 	EngagementKindFollowing: {"following_count", ""},
 
 	EngagementKindBlockedBy: {"blocked_by_count", ""},
 	EngagementKindBlocking:  {"blocking_count", ""},
+	EngagementKindMuting:    {"muting_count", ""},
 
 	EngagementKindView:     {"view_count", ""},
 	EngagementKindBookmark: {"bookmark_count", ""},
@@ -18,3 +18,30 @@ type Hashtag struct {
 
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// HashtagTrendBucket bir hashtag'in bir saatteki kullanım sayısı. Trendler ham
+// hashtag tablosu yerine periyodik hesaplanan bu özetlerden okunur. Scope
+// global için boş, ülke için ülke kodu, şehir için "ülke/şehir"dir.
+type HashtagTrendBucket struct {
+	Tag       string    `gorm:"size:100;primaryKey" json:"tag"`
+	ScopeType string    `gorm:"size:16;primaryKey" json:"scope_type"`
+	Scope     string    `gorm:"size:600;primaryKey" json:"scope"`
+	Bucket    time.Time `gorm:"primaryKey;index" json:"bucket"` // Saat başı
+	Count     int64     `gorm:"not null" json:"count"`
+}
+
+func (HashtagTrendBucket) TableName() string {
+	return "hashtag_trend_buckets"
+}
+
+// HashtagFollow kullanıcının takip ettiği hashtag. Takip edilen etiketlerdeki
+// postlar takip akışına girer. Tag küçük harfle saklanır.
+type HashtagFollow struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Tag       string    `gorm:"size:100;primaryKey;index" json:"tag"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (HashtagFollow) TableName() string {
+	return "hashtag_follows"
+}
@@ -15,6 +15,7 @@ const (
 	RoleChatImage MediaRole = "chat_image"
 	RoleChatMedia MediaRole = "chat_media"
 	RoleChatVideo MediaRole = "chat_video"
+	RoleChatAudio MediaRole = "chat_audio" // Sesli mesaj
 	RoleOther     MediaRole = "other"
 
 	// Owner Type
@@ -23,4 +24,6 @@ const (
 	OwnerBlog OwnerType = "blog"
 	OwnerChat OwnerType = "chat"
 	OwnerPage OwnerType = "page"
+
+	OwnerPostRevision OwnerType = "post_revision" // Düzenlemede posttan çıkarılan ekler
 )
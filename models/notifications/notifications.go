@@ -24,6 +24,10 @@ const (
 	NotificationTypeSuperLike     = "super_like"     // Özel beğeni bildirimi (örn. Tinder’daki gibi)
 	NotificationTypeMessageRead   = "message_read"   // Mesaj okundu bildirimi
 	NotificationTypeMatchUnmatch  = "match_unmatch"  // Eşleşme iptali bildirimi
+	NotificationTypeMissedCall    = "missed_call"    // Cevapsız arama bildirimi
+	NotificationTypeMention       = "mention"        // Postta bahsedilme bildirimi
+	NotificationTypeRepost        = "repost"         // Postun yeniden paylaşılma bildirimi
+	NotificationTypeQuote         = "quote"          // Postun alıntılanma bildirimi
 )
 
 type Notification struct {
@@ -0,0 +1,46 @@
+package post
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// FeedItem çok sayıda hesabı takip eden kullanıcılar için önceden hesaplanan
+// takip akışı kaydı. Yeni postlar yazılırken takipçilerin akışına eklenir.
+type FeedItem struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey;index:idx_feed_items_user_public,priority:1" json:"user_id"`
+	PostID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"post_id"`
+	AuthorID  uuid.UUID `gorm:"type:uuid;index;not null" json:"author_id"`
+	PublicID  int64     `gorm:"not null;index:idx_feed_items_user_public,priority:2,sort:desc" json:"public_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (FeedItem) TableName() string {
+	return "feed_items"
+}
+
+// FeedState kullanıcının akışı önceden hesaplanıyor mu; BuiltAt sonrasında
+// yeni bir takip varsa akış yeniden oluşturulur
+type FeedState struct {
+	UserID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	BuiltAt time.Time `json:"built_at"`
+}
+
+func (FeedState) TableName() string {
+	return "feed_states"
+}
+
+// ForYouSnapshot "Senin İçin" akışının ilk sayfada sıralanan post listesi.
+// Sonraki sayfalar bu listeden okunur; sıralama sayfalar arasında değişmez.
+type ForYouSnapshot struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID      `gorm:"type:uuid;index;not null" json:"user_id"`
+	PostIDs   pq.StringArray `gorm:"type:text[]" json:"post_ids"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func (ForYouSnapshot) TableName() string {
+	return "feed_for_you_snapshots"
+}
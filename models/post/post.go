@@ -1,6 +1,7 @@
 package post
 
 import (
+	"coolvibes/constants"
 	"coolvibes/models"
 	"coolvibes/models/media"
 	"coolvibes/models/utils"
@@ -28,6 +29,8 @@ const (
 	PostTypeStory      PostType = "story"
 	PostTypeChat       PostType = "chat"
 	PostTypePost       PostType = "post"
+	PostTypeRepost     PostType = "repost" // Başka bir postun içeriksiz paylaşımı
+	PostTypeQuote      PostType = "quote"  // Başka bir postu yorumla alıntılama
 )
 
 const (
@@ -61,6 +64,12 @@ type Post struct {
 	// 🔹 İçerik kategorisi
 	ContentCategory ContentCategory `gorm:"size:50;not null;index;default:'normal'" json:"content_category"`
 
+	// 🔹 Postu kimlerin görebileceği
+	Audience constants.PrivacyLevel `gorm:"type:varchar(20);not null;index;default:'public'" json:"audience"`
+
+	// 🔹 Repost ve alıntılarda paylaşılan orijinal post
+	RepostOfID *uuid.UUID `gorm:"type:uuid;index" json:"repost_of_id,omitempty"`
+
 	ContentableID   *uuid.UUID `gorm:"type:uuid;index" json:"contentable_id,omitempty"`
 	ContentableType *string    `gorm:"size:50;index" json:"contentable_type,omitempty"`
 
@@ -73,6 +82,9 @@ type Post struct {
 
 	Published   bool           `gorm:"default:false;index" json:"published"`
 	PublishedAt *time.Time     `gorm:"index" json:"published_at,omitempty"`
+	ScheduledAt *time.Time     `gorm:"index" json:"scheduled_at,omitempty"` // Zamanlanmış postun yayınlanacağı zaman; taslaklarda boş
+	EditedAt    *time.Time     `json:"edited_at,omitempty"`
+	ExpiresAt   *time.Time     `gorm:"index" json:"expires_at,omitempty"` // Kaybolan sohbet mesajları bu zamanda silinir
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -88,8 +100,18 @@ type Post struct {
 	Poll  []*payloads.Poll `gorm:"polymorphic:Contentable;polymorphicValue:post;constraint:OnDelete:CASCADE" json:"poll,omitempty"`
 	Event *payloads.Event  `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" json:"event,omitempty"`
 
-	Location    *utils.Location `gorm:"polymorphic:Contentable;polymorphicValue:post;constraint:OnDelete:CASCADE;" json:"location,omitempty"`
-	Contentable any             `gorm:"-" json:"contentable,omitempty"`
+	Location    *utils.Location   `gorm:"polymorphic:Contentable;polymorphicValue:post;constraint:OnDelete:CASCADE;" json:"location,omitempty"`
+	Contentable any               `gorm:"-" json:"contentable,omitempty"`
+	Receipt     *MessageReceipt   `gorm:"-" json:"receipt,omitempty"`
+	Reactions   []ReactionSummary `gorm:"-" json:"reactions,omitempty"`
+
+	// Orijinal post silindiyse ya da izleyiciye kapalıysa RepostOf boş kalır
+	// ve RepostUnavailable işaretlenir
+	RepostOf          *Post `gorm:"-" json:"repost_of,omitempty"`
+	RepostUnavailable bool  `gorm:"-" json:"repost_unavailable,omitempty"`
+
+	// Konuşma görünümünde bu postun sonraki yanıt sayfası
+	RepliesCursor *string `gorm:"-" json:"replies_cursor,omitempty"`
 
 	//	Engagements *models.Engagement `gorm:"polymorphic:Contentable;constraint:OnDelete:CASCADE" json:"engagements,omitempty"`
 	Engagements *models.Engagement `gorm:"polymorphic:Contentable;polymorphicValue:post;constraint:OnDelete:CASCADE" json:"engagements,omitempty"`
@@ -103,11 +125,73 @@ func (u Post) MarshalJSON() ([]byte, error) {
 	type Alias Post // recursive çağrıyı önlemek için alias
 	aux := struct {
 		PublicID string `json:"public_id"`
+		Edited   bool   `json:"edited"` // "düzenlendi" işareti
 		Alias
 	}{
 		PublicID: strconv.FormatInt(u.PublicID, 10),
+		Edited:   u.EditedAt != nil,
 		Alias:    (Alias)(u),
 	}
 
 	return json.Marshal(aux)
 }
+
+// Bağlantı önizlemesinin Extras anahtarı
+const ExtraLinkPreview = "link_preview"
+
+// Taslak ve zamanlanmış postlarda formdan gelen hashtag ve mention'lar; yayın
+// anında içerikten çıkarılanlarla birlikte kaydedilir
+const (
+	ExtraPendingHashtags = "pending_hashtags"
+	ExtraPendingMentions = "pending_mentions"
+)
+
+// Silinen postların (tombstone) Extras anahtarları
+const (
+	ExtraDeletedBy   = "deleted_by"   // Silen kullanıcı; yazar ya da moderatör
+	ExtraDeletedWith = "deleted_with" // Birlikte silindiği üst post; yanıtlar için
+)
+
+// Yanıt, repost ve alıntının hedef postta oluşturduğu etkileşim kaydının
+// Extras anahtarları; post silinince tam bu kayıt kaldırılır
+const (
+	ExtraParentEngagementID = "parent_engagement_id" // Üst posttaki yorum kaydı
+	ExtraRepostEngagementID = "repost_engagement_id" // Orijinaldeki repost ya da alıntı kaydı
+)
+
+// SetExtra Extras içine tek bir anahtar yazar
+func (u *Post) SetExtra(key string, value any) {
+	if u.Extras == nil {
+		extras := map[string]any{}
+		u.Extras = &extras
+	}
+	(*u.Extras)[key] = value
+}
+
+// GetExtra Extras içindeki bir anahtarı okur
+func (u *Post) GetExtra(key string) (any, bool) {
+	if u.Extras == nil {
+		return nil, false
+	}
+	value, ok := (*u.Extras)[key]
+	return value, ok
+}
+
+// GetExtraStrings Extras içindeki metin listesini okur; veritabanından
+// yüklenen listeler []any olarak gelir
+func (u *Post) GetExtraStrings(key string) []string {
+	value, _ := u.GetExtra(key)
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []any:
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
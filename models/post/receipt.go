@@ -0,0 +1,20 @@
+package post
+
+import "github.com/google/uuid"
+
+// MessageReceipt sohbet mesajları için iletim / okunma özeti.
+// Veritabanında tutulmaz, messages_reads tablosundan hesaplanır.
+type MessageReceipt struct {
+	Status         string      `json:"status"` // chat.MessageStatus: pending, delivered, seen
+	Recipients     int64       `json:"recipients"`
+	DeliveredCount int64       `json:"delivered_count"`
+	SeenCount      int64       `json:"seen_count"` // grup sohbetlerinde "N kişi gördü"
+	SeenBy         []uuid.UUID `json:"seen_by,omitempty"`
+}
+
+// ReactionSummary sohbet mesajındaki bir emojinin sayısı ve bırakanlar
+type ReactionSummary struct {
+	Emoji    string      `json:"emoji"`
+	Count    int64       `json:"count"`
+	Reactors []uuid.UUID `json:"reactors"`
+}
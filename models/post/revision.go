@@ -0,0 +1,34 @@
+package post
+
+import (
+	"coolvibes/models/media"
+	"coolvibes/models/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PostRevision düzenlenen postun düzenlemeden önceki hali
+type PostRevision struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	PostID   uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_post_revisions_post_revision;not null" json:"post_id"`
+	Revision int       `gorm:"uniqueIndex:idx_post_revisions_post_revision;not null" json:"revision"` // 1'den başlar
+	EditorID uuid.UUID `gorm:"type:uuid;not null" json:"editor_id"`
+
+	Title   *utils.LocalizedString `gorm:"type:jsonb" json:"title,omitempty"`
+	Content *utils.LocalizedString `gorm:"type:jsonb" json:"content,omitempty"`
+	Summary *utils.LocalizedString `gorm:"type:jsonb" json:"summary,omitempty"`
+
+	Hashtags      pq.StringArray `gorm:"type:text[]" json:"hashtags"`
+	MentionIDs    pq.StringArray `gorm:"type:text[]" json:"mention_ids"`
+	AttachmentIDs pq.StringArray `gorm:"type:text[]" json:"-"`
+
+	Attachments []*media.Media `gorm:"-" json:"attachments,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (PostRevision) TableName() string {
+	return "post_revisions"
+}
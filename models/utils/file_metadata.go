@@ -27,6 +27,7 @@ type FileMetadata struct {
 type FileVariants struct {
 	Image *ImageVariants `json:"image,omitempty"`
 	Video *VideoVariants `json:"video,omitempty"`
+	Audio *AudioVariants `json:"audio,omitempty"`
 }
 
 // Görsel varyantları
@@ -48,6 +49,13 @@ type VideoVariants struct {
 	Preview *VariantInfo `json:"preview,omitempty"` // Sessiz kısa loop (splash, anim icon vs.)
 }
 
+// Ses varyantları (sesli mesajlar)
+type AudioVariants struct {
+	Opus     *VariantInfo `json:"opus,omitempty"`     // ogg/opus, modern tarayıcılar
+	AAC      *VariantInfo `json:"aac,omitempty"`      // m4a/aac, Safari / iOS
+	Waveform []int        `json:"waveform,omitempty"` // Oynatıcı için 0-100 arası tepe değerleri
+}
+
 // Her varyant için ortak bilgiler
 type VariantInfo struct {
 	URL      string   `json:"url"`
@@ -83,7 +91,7 @@ func (fv *FileVariants) Scan(value interface{}) error {
 
 // Valuer interface implementasyonu
 func (fv FileVariants) Value() (driver.Value, error) {
-	if fv.Image == nil && fv.Video == nil {
+	if fv.Image == nil && fv.Video == nil && fv.Audio == nil {
 		return nil, nil
 	}
 
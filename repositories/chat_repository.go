@@ -2,16 +2,22 @@ package repositories
 
 import (
 	"coolvibes/constants"
+	"coolvibes/extensions"
 	"coolvibes/helpers"
 	"coolvibes/models"
 	"coolvibes/models/chat"
+	chat_payloads "coolvibes/models/chat/payloads"
+	"coolvibes/models/media"
 	"coolvibes/models/notifications"
 	"coolvibes/models/utils"
+	"coolvibes/types"
+	"errors"
 	"fmt"
 
 	"coolvibes/models/post"
 	"log"
 	"mime/multipart"
+	"strconv"
 	"time"
 
 	"github.com/go-playground/form/v4"
@@ -60,16 +66,43 @@ func (r *ChatRepository) GetChatByIDWithoutRelations(id uuid.UUID) (*chat.Chat,
 	return &chatObj, nil
 }
 
-func (r *ChatRepository) GetChatsByUserID(userID uuid.UUID) ([]chat.Chat, error) {
+// GetChatsByUserID sohbet listesini döndürür; archived true ise sadece arşivlenmiş
+// sohbetler gelir. Sabitlenen sohbetler sabitlenme sırasıyla en üstte durur.
+func (r *ChatRepository) GetChatsByUserID(userID uuid.UUID, archived bool) ([]chat.Chat, error) {
+	return r.getChatsForUser(userID, false, &archived)
+}
+
+// GetChatRequestsByUserID mesaj istekleri kutusundaki sohbetler
+func (r *ChatRepository) GetChatRequestsByUserID(userID uuid.UUID) ([]chat.Chat, error) {
+	return r.getChatsForUser(userID, true, nil)
+}
+
+func (r *ChatRepository) getChatsForUser(userID uuid.UUID, requests bool, archived *bool) ([]chat.Chat, error) {
 	var chats []chat.Chat
 
-	err := r.db.
+	query := r.db.
 		Joins("JOIN chat_participants ON chat_participants.chat_id = chats.id").
 		Where("chat_participants.user_id = ?", userID).
+		Where("chat_participants.is_request = ?", requests).
+		// Gizlenen sohbetler yeni mesaj gelene kadar listelenmez
+		Where("chat_participants.hidden_at IS NULL OR chats.last_message_timestamp > chat_participants.hidden_at")
+	if archived != nil {
+		if *archived {
+			query = query.Where("chat_participants.archived_at IS NOT NULL")
+		} else {
+			query = query.Where("chat_participants.archived_at IS NULL")
+		}
+	}
+
+	err := query.
 		Preload("Participants.User").
+		Preload("Avatar").
+		Preload("Avatar.File").
+		Preload("PinnedMsg").
 		Preload("LastMessage").
 		Preload("LastMessage.Author").
-		Order("last_message_timestamp DESC").
+		Order("chat_participants.pinned_at IS NULL, chat_participants.pinned_at DESC").
+		Order("last_message_timestamp DESC NULLS LAST").
 		Find(&chats).Error
 
 	if err != nil {
@@ -101,7 +134,9 @@ func (r *ChatRepository) GetPrivateChatBetweenUsers(fromUser, toUser uuid.UUID)
 	return &chatObj, nil
 }
 
-func (r *ChatRepository) CreatePrivateChat(userID1, userID2 uuid.UUID) (*chat.Chat, error) {
+// CreatePrivateChat userID1 (başlatan) ile userID2 arasında sohbet açar.
+// isRequest true ise sohbet userID2'nin mesaj istekleri kutusuna düşer.
+func (r *ChatRepository) CreatePrivateChat(userID1, userID2 uuid.UUID, isRequest bool) (*chat.Chat, error) {
 	newChat := &chat.Chat{
 		ID:          uuid.New(),
 		Type:        chat.ChatTypePrivate,
@@ -109,8 +144,8 @@ func (r *ChatRepository) CreatePrivateChat(userID1, userID2 uuid.UUID) (*chat.Ch
 		Title:       &utils.LocalizedString{"en": "Private Chat"},
 		Description: &utils.LocalizedString{"en": "A private chat is a secure, invite-only conversation between selected participants."},
 		Participants: []chat.ChatParticipant{
-			{ID: uuid.New(), UserID: userID1},
-			{ID: uuid.New(), UserID: userID2},
+			{ID: uuid.New(), UserID: userID1, Role: chat.RoleMember, JoinedAt: time.Now()},
+			{ID: uuid.New(), UserID: userID2, Role: chat.RoleMember, JoinedAt: time.Now(), IsRequest: isRequest},
 		},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -122,10 +157,14 @@ func (r *ChatRepository) CreatePrivateChat(userID1, userID2 uuid.UUID) (*chat.Ch
 	return newChat, nil
 }
 
-func (r *ChatRepository) AddParticipant(chatID, userID uuid.UUID) error {
+// AddParticipant kullanıcıyı sohbete ekler; daha önce ayrılmışsa tekrar aktif eder
+func (r *ChatRepository) AddParticipant(chatID, userID uuid.UUID, role chat.ParticipantRole) error {
 	participant := chat.ChatParticipant{
-		ChatID: chatID,
-		UserID: userID,
+		ID:       uuid.New(),
+		ChatID:   chatID,
+		UserID:   userID,
+		Role:     role,
+		JoinedAt: time.Now(),
 	}
 	return r.db.FirstOrCreate(&participant, "chat_id = ? AND user_id = ?", chatID, userID).Error
 }
@@ -158,9 +197,14 @@ func (r *ChatRepository) CreateGroupChat(
 		participantIDs = append(participantIDs, creatorID)
 	}
 
+	now := time.Now()
 	participants := make([]chat.ChatParticipant, len(participantIDs))
 	for i, userID := range participantIDs {
-		participants[i] = chat.ChatParticipant{UserID: userID}
+		role := chat.RoleMember
+		if userID == creatorID {
+			role = chat.RoleOwner
+		}
+		participants[i] = chat.ChatParticipant{ID: uuid.New(), UserID: userID, Role: role, JoinedAt: now}
 	}
 
 	newChat := &chat.Chat{
@@ -181,12 +225,202 @@ func (r *ChatRepository) CreateGroupChat(
 	return newChat, nil
 }
 
-func (r *ChatRepository) SendTypingEvent(chatID, userID uuid.UUID, typing bool) (map[string]interface{}, error) {
+func (r *ChatRepository) GetParticipant(chatID, userID uuid.UUID) (*chat.ChatParticipant, error) {
+	var participant chat.ChatParticipant
+	err := r.db.
+		Where("chat_id = ? AND user_id = ?", chatID, userID).
+		First(&participant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+// SetMuted sohbeti until zamanına kadar sessize alır; until nil ise süresiz
+func (r *ChatRepository) SetMuted(chatID, userID uuid.UUID, muted bool, until *time.Time) error {
+	if !muted {
+		until = nil
+	}
+	return r.db.Model(&chat.ChatParticipant{}).
+		Where("chat_id = ? AND user_id = ?", chatID, userID).
+		Updates(map[string]interface{}{
+			"is_muted":    muted,
+			"muted_until": until,
+		}).Error
+}
+
+// SetArchived sohbeti arşive taşır / arşivden çıkarır. Arşivlenen sohbet
+// sabitlenmiş olarak kalamaz.
+func (r *ChatRepository) SetArchived(chatID, userID uuid.UUID, archived bool) error {
+	updates := map[string]interface{}{"archived_at": nil}
+	if archived {
+		updates["archived_at"] = time.Now()
+		updates["pinned_at"] = nil
+	}
+	return r.db.Model(&chat.ChatParticipant{}).
+		Where("chat_id = ? AND user_id = ?", chatID, userID).
+		Updates(updates).Error
+}
+
+// SetPinned sohbeti listenin başına sabitler / sabitlemeyi kaldırır.
+// Sabitlenen sohbet arşivden çıkar.
+func (r *ChatRepository) SetPinned(chatID, userID uuid.UUID, pinned bool) error {
+	updates := map[string]interface{}{"pinned_at": nil}
+	if pinned {
+		updates["pinned_at"] = time.Now()
+		updates["archived_at"] = nil
+	}
+	return r.db.Model(&chat.ChatParticipant{}).
+		Where("chat_id = ? AND user_id = ?", chatID, userID).
+		Updates(updates).Error
+}
+
+// CountPinnedChats kullanıcının sabitlediği sohbet sayısı
+func (r *ChatRepository) CountPinnedChats(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&chat.ChatParticipant{}).
+		Where("user_id = ? AND pinned_at IS NOT NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// AcceptChatRequest sohbeti istekler kutusundan normal sohbetlere taşır
+func (r *ChatRepository) AcceptChatRequest(chatID, userID uuid.UUID) error {
+	return r.db.Model(&chat.ChatParticipant{}).
+		Where("chat_id = ? AND user_id = ? AND is_request = ?", chatID, userID, true).
+		Updates(map[string]interface{}{
+			"is_request":          false,
+			"request_accepted_at": time.Now(),
+		}).Error
+}
+
+func (r *ChatRepository) UpdateParticipantRole(chatID, userID uuid.UUID, role chat.ParticipantRole) error {
+	return r.db.Model(&chat.ChatParticipant{}).
+		Where("chat_id = ? AND user_id = ?", chatID, userID).
+		Update("role", role).Error
+}
+
+// TransferOwnership sahipliği newOwnerID'ye devreder, eski sahip admin olur
+func (r *ChatRepository) TransferOwnership(chatID, ownerID, newOwnerID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&chat.ChatParticipant{}).
+			Where("chat_id = ? AND user_id = ?", chatID, ownerID).
+			Update("role", chat.RoleAdmin).Error; err != nil {
+			return err
+		}
+		return tx.Model(&chat.ChatParticipant{}).
+			Where("chat_id = ? AND user_id = ?", chatID, newOwnerID).
+			Update("role", chat.RoleOwner).Error
+	})
+}
+
+// GetSuccessor sahip ayrılırken devredilecek kişiyi bulur: önce en eski admin, yoksa en eski üye
+func (r *ChatRepository) GetSuccessor(chatID, excludeUserID uuid.UUID) (*chat.ChatParticipant, error) {
+	var participant chat.ChatParticipant
+	err := r.db.
+		Where("chat_id = ? AND user_id <> ?", chatID, excludeUserID).
+		Order("CASE WHEN role = 'admin' THEN 0 ELSE 1 END, joined_at ASC").
+		First(&participant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
+
+func (r *ChatRepository) SetPinnedMessage(chatID uuid.UUID, messageID *uuid.UUID) error {
+	return r.db.Model(&chat.Chat{}).
+		Where("id = ?", chatID).
+		Update("pinned_msg_id", messageID).Error
+}
+
+func (r *ChatRepository) SetAvatar(chatID, avatarID uuid.UUID) error {
+	return r.db.Model(&chat.Chat{}).
+		Where("id = ?", chatID).
+		Update("avatar_id", avatarID).Error
+}
+
+// Süresi dolmuş kaybolan mesajlar sweeper silene kadar da görünmez
+const messageNotExpired = "posts.expires_at IS NULL OR posts.expires_at > now()"
+
+// FindMessageByPublicID sohbete ait mesajı public_id ile bulur
+func (r *ChatRepository) FindMessageByPublicID(chatID uuid.UUID, publicID int64) (*post.Post, error) {
+	var message post.Post
+	err := r.db.
+		Preload("Author").
+		Preload("Attachments").
+		Preload("Attachments.File").
+		Where("contentable_type = ? AND contentable_id = ? AND public_id = ?", "chat", chatID, publicID).
+		Where(messageNotExpired).
+		First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// AddSystemMessage grup olayları (üye ekleme, çıkarma vb.) için sistem mesajı oluşturur.
+// Sistem mesajları okunmamış sayısını artırmaz ve push bildirimi göndermez.
+func (r *ChatRepository) AddSystemMessage(chatID, actorID uuid.UUID, event chat.SystemEvent, text string, data map[string]any) (*post.Post, error) {
+	extras := map[string]any{
+		chat.ExtraMessageType: chat.System,
+		chat.ExtraSystemEvent: event,
+		chat.ExtraActorID:     actorID,
+	}
+	for k, v := range data {
+		extras[k] = v
+	}
+
+	contentableType := "chat"
+	message := &post.Post{
+		ID:              uuid.New(),
+		PublicID:        r.snowFlakeNode.Generate().Int64(),
+		AuthorID:        actorID,
+		Published:       true,
+		PostKind:        post.PostTypeChat,
+		ContentCategory: post.ContentNormal,
+		Content:         utils.MakeLocalizedString("en", text),
+		ContentableType: &contentableType,
+		ContentableID:   &chatID,
+		Extras:          &extras,
+	}
+	now := time.Now()
+	message.PublishedAt = &now
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		return tx.Model(&chat.Chat{}).Where("id = ?", chatID).Updates(map[string]interface{}{
+			"last_message_id":        message.ID,
+			"last_message_timestamp": message.CreatedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.postRepo.GetPostByID(message.ID)
+}
+
+func (r *ChatRepository) IsParticipant(chatID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&chat.ChatParticipant{}).
+		Where("chat_id = ? AND user_id = ?", chatID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ChatRepository) SendTypingEvent(chatID, userID uuid.UUID, typing bool, typingUsers []types.TypingUser, summary string) (map[string]interface{}, error) {
+	if typingUsers == nil {
+		typingUsers = []types.TypingUser{}
+	}
 	message := map[string]interface{}{
-		"action":  constants.CMD_TYPING,
-		"chat_id": chatID.String(),
-		"user_id": userID.String(),
-		"typing":  typing,
+		"action":       constants.CMD_TYPING,
+		"chat_id":      chatID.String(),
+		"user_id":      userID.String(),
+		"typing":       typing,
+		"typing_users": typingUsers,
+		"summary":      summary,
 	}
 	return message, nil
 }
@@ -194,7 +428,12 @@ func (r *ChatRepository) SendTypingEvent(chatID, userID uuid.UUID, typing bool)
 func (r *ChatRepository) AddMessageToChat(request map[string][]string, files []*multipart.FileHeader, author *models.User) (*post.Post, error) {
 
 	type PostForm struct {
-		ChatID string `form:"chat_id"`
+		ChatID   string `form:"chat_id"`
+		ReplyTo  string `form:"reply_to"`  // alıntılanan mesajın public_id'si
+		ViewOnce bool   `form:"view_once"` // ekler alıcı tarafından bir kez açılabilir
+
+		LocationLat *float64 `form:"location[lat]"`
+		LocationLng *float64 `form:"location[lng]"`
 	}
 	decoder := form.NewDecoder()
 	postForm := PostForm{}
@@ -214,31 +453,167 @@ func (r *ChatRepository) AddMessageToChat(request map[string][]string, files []*
 		return nil, err
 	}
 
+	if postForm.LocationLat != nil || postForm.LocationLng != nil {
+		if postForm.LocationLat == nil || postForm.LocationLng == nil || !ValidCoordinates(*postForm.LocationLat, *postForm.LocationLng) {
+			return nil, ErrInvalidCoordinates
+		}
+	}
+
+	var replyTo *post.Post
+	if postForm.ReplyTo != "" {
+		replyPublicID, err := strconv.ParseInt(postForm.ReplyTo, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply_to %s: %w", postForm.ReplyTo, err)
+		}
+		replyTo, err = r.FindMessageByPublicID(chatId, replyPublicID)
+		if err != nil {
+			return nil, fmt.Errorf("reply_to message not found: %w", err)
+		}
+		if chat.IsTombstoned(replyTo) || chat.IsSystemMessage(replyTo) {
+			return nil, fmt.Errorf("cannot reply to this message")
+		}
+	}
+
 	_createdPost, err := r.postRepo.CreateContentablePost(request, files, author, "chat", &chatObj.ID)
 	if err != nil {
 		return nil, err
 	}
+
+	updates := map[string]interface{}{}
+	if replyTo != nil {
+		_createdPost.SetExtra(chat.ExtraReplyTo, chat.ReplyPreview(replyTo))
+		updates["extras"] = _createdPost.Extras
+	}
+	if postForm.LocationLat != nil {
+		_createdPost.SetExtra(chat.ExtraMessageType, chat.Location)
+		updates["extras"] = _createdPost.Extras
+	}
+	for _, attachment := range _createdPost.Attachments {
+		if attachment.Role == media.RoleChatAudio {
+			_createdPost.SetExtra(chat.ExtraMessageType, chat.Audio)
+			updates["extras"] = _createdPost.Extras
+			break
+		}
+	}
+	if postForm.ViewOnce && len(_createdPost.Attachments) > 0 {
+		_createdPost.SetExtra(chat.ExtraViewOnce, true)
+		_createdPost.SetExtra(chat.ExtraViewOnceMedia, len(_createdPost.Attachments))
+		updates["extras"] = _createdPost.Extras
+	}
+	if expiresAt := messageExpiry(chatObj); expiresAt != nil {
+		updates["expires_at"] = expiresAt
+	}
+	if len(updates) > 0 {
+		if err := r.db.Model(&post.Post{}).Where("id = ?", _createdPost.ID).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
 	chatPost, err := r.postRepo.GetPostByID(_createdPost.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	r.db.Model(&chatObj).Updates(map[string]interface{}{
+	if err := r.afterMessageCreated(chatObj, chatPost, author); err != nil {
+		return nil, err
+	}
+	return chatPost, nil
+}
+
+// messageExpiry kaybolan mesajlar açıksa yeni mesajın silineceği zaman
+func messageExpiry(chatObj *chat.Chat) *time.Time {
+	if chatObj.DisappearAfter == nil || *chatObj.DisappearAfter <= 0 {
+		return nil
+	}
+	expiresAt := time.Now().Add(time.Duration(*chatObj.DisappearAfter) * time.Second)
+	return &expiresAt
+}
+
+// afterMessageCreated son mesaj bilgisini, okunmamış sayılarını günceller ve
+// diğer katılımcılara bildirim gönderir
+func (r *ChatRepository) afterMessageCreated(chatObj *chat.Chat, chatPost *post.Post, author *models.User) error {
+	r.db.Model(chatObj).Updates(map[string]interface{}{
 		"last_message_id":        chatPost.ID,
 		"last_message_timestamp": chatPost.CreatedAt,
 	})
 
-	err = r.db.Model(&chat.ChatParticipant{}).
-		Where("chat_id = ? AND user_id <> ?", chatId, author.ID).
+	err := r.db.Model(&chat.ChatParticipant{}).
+		Where("chat_id = ? AND user_id <> ?", chatObj.ID, author.ID).
 		Update("unread_count", gorm.Expr("unread_count + ?", 1)).
 		Error
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	newMessageNotification := fmt.Sprintf("You received a new message from %s. Click to read.", author.UserName)
 	r.NotifyChatParticipants(chatObj.ID, *author, "New Message", newMessageNotification)
-	return chatPost, err
+	return nil
+}
+
+// ForwardMessage mesajı başka bir sohbete kopyalar. Ekler aynı dosyayı
+// paylaşan yeni medya kayıtları olarak eklenir; orijinal yazar bilgisi
+// Extras["forwarded_from"] içinde korunur.
+func (r *ChatRepository) ForwardMessage(original *post.Post, targetChatID uuid.UUID, author *models.User) (*post.Post, error) {
+	chatObj, err := r.GetChatByIDWithoutRelations(targetChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	contentableType := "chat"
+	extras := map[string]any{
+		chat.ExtraForwardedFrom: chat.ForwardAttribution(original),
+	}
+	now := time.Now()
+	forwarded := &post.Post{
+		ID:              uuid.New(),
+		PublicID:        r.snowFlakeNode.Generate().Int64(),
+		AuthorID:        author.ID,
+		Published:       true,
+		PublishedAt:     &now,
+		PostKind:        post.PostTypeChat,
+		ContentCategory: original.ContentCategory,
+		Title:           original.Title,
+		Content:         original.Content,
+		Summary:         original.Summary,
+		ContentableType: &contentableType,
+		ContentableID:   &chatObj.ID,
+		Extras:          &extras,
+		ExpiresAt:       messageExpiry(chatObj),
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(forwarded).Error; err != nil {
+			return err
+		}
+		for _, attachment := range original.Attachments {
+			copied := media.Media{
+				ID:        uuid.New(),
+				PublicID:  r.snowFlakeNode.Generate().Int64(),
+				FileID:    attachment.FileID,
+				OwnerID:   forwarded.ID,
+				OwnerType: attachment.OwnerType,
+				UserID:    author.ID,
+				Role:      attachment.Role,
+				IsPublic:  attachment.IsPublic,
+			}
+			if err := tx.Omit("File").Create(&copied).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chatPost, err := r.postRepo.GetPostByID(forwarded.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.afterMessageCreated(chatObj, chatPost, author); err != nil {
+		return nil, err
+	}
+	return chatPost, nil
 }
 
 func (r *ChatRepository) NotifyChatParticipants(chatId uuid.UUID, author models.User, messageTitle, messageText string) error {
@@ -260,13 +635,26 @@ func (r *ChatRepository) NotifyChatParticipants(chatId uuid.UUID, author models.
 			continue
 		}
 
+		// Sessize alınan ya da arşivlenen sohbetler için bildirim gönderilmez;
+		// mesaj yine de socket üzerinden iletilir
+		if participant.MutedAt(time.Now()) || participant.ArchivedAt != nil {
+			continue
+		}
+
+		title, text := messageTitle, messageText
+		if participant.IsRequest {
+			// Mesaj isteklerinde içerik bildirimde gösterilmez
+			title = "New Message Request"
+			text = fmt.Sprintf("%s wants to send you a message.", author.UserName)
+		}
+
 		payload := notifications.NotificationPayload{
-			Title: messageTitle,
-			Body:  messageText,
+			Title: title,
+			Body:  text,
 			// diğer alanlar eklenecekse ekle
 		}
 
-		err := r.notificationRepo.SendNotificationToUser(author, user, notifications.NotificationTypeChatMessage, messageTitle, messageText, payload)
+		err := r.notificationRepo.SendNotificationToUser(author, user, notifications.NotificationTypeChatMessage, title, text, payload)
 		if err != nil {
 			fmt.Printf("Bildirim gönderilemedi user %s: %v\n", user.ID, err)
 		}
@@ -275,32 +663,116 @@ func (r *ChatRepository) NotifyChatParticipants(chatId uuid.UUID, author models.
 	return nil
 }
 
-func (r *ChatRepository) GetMessagesByChatID(userID uuid.UUID, chatID uuid.UUID) ([]post.Post, error) {
-	var messages []post.Post
-	err := r.db.
+// messagesQuery kullanıcının "benden sil" ile gizlediği mesajları ve sohbeti
+// gizlediği andan önceki mesajları dışarıda bırakır
+func (r *ChatRepository) messagesQuery(chatID, userID uuid.UUID) *gorm.DB {
+	return r.db.Model(&post.Post{}).
 		Where("contentable_type = ? AND contentable_id = ?", "chat", chatID).
-		Order("created_at ASC").
+		Where(messageNotExpired).
+		Where("NOT EXISTS (SELECT 1 FROM messages_hidden mh WHERE mh.message_id = posts.id AND mh.user_id = ?)", userID).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM chat_participants cp
+			WHERE cp.chat_id = posts.contentable_id AND cp.user_id = ?
+				AND cp.hidden_at IS NOT NULL AND posts.created_at <= cp.hidden_at
+		)`, userID).
 		Preload("Author").
 		Preload("Attachments").
 		Preload("Attachments.File").
-		Find(&messages).Error
+		Preload("Location")
+}
 
-	if err != nil {
-		return nil, err
+// GetMessagesByChatID mesajları PublicID (snowflake) cursor'ı ile sayfalar.
+// Before: bu mesajdan eski olanlar, After: bu mesajdan yeni olanlar,
+// Around: bu mesaj ve çevresi (mesaja atlama). Hiçbiri verilmezse en son mesajlar.
+// Mesajlar her durumda eskiden yeniye sıralı döner; NextCursor daha eski,
+// PrevCursor daha yeni mesajlar için kullanılır.
+func (r *ChatRepository) GetMessagesByChatID(userID uuid.UUID, chatID uuid.UUID, page types.MessagePage) (types.MessagesResult, error) {
+	limit := page.Limit
+	var older, newer []post.Post
+	hasOlder, hasNewer := false, false
+
+	switch {
+	case page.Around != nil:
+		olderLimit := limit / 2
+		newerLimit := limit - olderLimit
+
+		if err := r.messagesQuery(chatID, userID).
+			Where("public_id < ?", *page.Around).
+			Order("public_id DESC").
+			Limit(olderLimit + 1).
+			Find(&older).Error; err != nil {
+			return types.MessagesResult{}, err
+		}
+		if err := r.messagesQuery(chatID, userID).
+			Where("public_id >= ?", *page.Around).
+			Order("public_id ASC").
+			Limit(newerLimit + 1).
+			Find(&newer).Error; err != nil {
+			return types.MessagesResult{}, err
+		}
+		if len(older) > olderLimit {
+			older, hasOlder = older[:olderLimit], true
+		}
+		if len(newer) > newerLimit {
+			newer, hasNewer = newer[:newerLimit], true
+		}
+
+	case page.After != nil:
+		if err := r.messagesQuery(chatID, userID).
+			Where("public_id > ?", *page.After).
+			Order("public_id ASC").
+			Limit(limit + 1).
+			Find(&newer).Error; err != nil {
+			return types.MessagesResult{}, err
+		}
+		if len(newer) > limit {
+			newer, hasNewer = newer[:limit], true
+		}
+		hasOlder = true
+
+	default:
+		query := r.messagesQuery(chatID, userID)
+		if page.Before != nil {
+			query = query.Where("public_id < ?", *page.Before)
+			hasNewer = true
+		}
+		if err := query.
+			Order("public_id DESC").
+			Limit(limit + 1).
+			Find(&older).Error; err != nil {
+			return types.MessagesResult{}, err
+		}
+		if len(older) > limit {
+			older, hasOlder = older[:limit], true
+		}
 	}
 
-	now := time.Now()
-	err = r.db.Model(&chat.ChatParticipant{}).
-		Where("chat_id = ? AND user_id = ?", chatID, userID).
-		Updates(map[string]interface{}{
-			"unread_count": 0,
-			"last_read_at": now,
-		}).Error
+	// older DESC geldi, eskiden yeniye çevir
+	messages := make([]post.Post, 0, len(older)+len(newer))
+	for i := len(older) - 1; i >= 0; i-- {
+		messages = append(messages, older[i])
+	}
+	messages = append(messages, newer...)
 
-	if err != nil {
-		return nil, err
+	if err := r.AttachReceipts(messages); err != nil {
+		return types.MessagesResult{}, err
+	}
+	if err := r.AttachReactions(messages); err != nil {
+		return types.MessagesResult{}, err
 	}
-	return messages, nil
+
+	result := types.MessagesResult{Messages: messages}
+	if len(messages) > 0 {
+		if hasOlder {
+			s := strconv.FormatInt(messages[0].PublicID, 10)
+			result.NextCursor = &s
+		}
+		if hasNewer {
+			s := strconv.FormatInt(messages[len(messages)-1].PublicID, 10)
+			result.PrevCursor = &s
+		}
+	}
+	return result, nil
 }
 
 func (r *ChatRepository) GetUserChatIDsByUserPublicID(userPublicId int64) ([]uuid.UUID, error) {
@@ -321,3 +793,822 @@ func (r *ChatRepository) GetUserChatIDsByUserPublicID(userPublicId int64) ([]uui
 
 	return chatIDs, nil
 }
+
+// MarkMessagesDelivered upToPublicID dahil olmak üzere kullanıcının kendi
+// mesajları dışındaki mesajları iletildi olarak işaretler ve yeni
+// işaretlenen mesajların ID'lerini döndürür
+func (r *ChatRepository) MarkMessagesDelivered(chatID, userID uuid.UUID, upToPublicID int64) ([]uuid.UUID, error) {
+	var messageIDs []uuid.UUID
+	err := r.db.Raw(`
+		INSERT INTO messages_reads (id, message_id, chat_id, user_id, delivered_at)
+		SELECT uuid_generate_v4(), p.id, p.contentable_id, ?, NOW()
+		FROM posts p
+		WHERE p.contentable_type = 'chat'
+			AND p.contentable_id = ?
+			AND p.public_id <= ?
+			AND p.author_id <> ?
+			AND p.deleted_at IS NULL
+		ON CONFLICT (message_id, user_id) DO NOTHING
+		RETURNING message_id
+	`, userID, chatID, upToPublicID, userID).Scan(&messageIDs).Error
+	return messageIDs, err
+}
+
+// MarkMessagesRead upToPublicID dahil olmak üzere mesajları okundu olarak
+// işaretler, katılımcının unread_count değerini yeniden hesaplar
+func (r *ChatRepository) MarkMessagesRead(chatID, userID uuid.UUID, upToPublicID int64) ([]uuid.UUID, int, error) {
+	var messageIDs []uuid.UUID
+	var unreadCount int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Raw(`
+			INSERT INTO messages_reads (id, message_id, chat_id, user_id, delivered_at, read_at)
+			SELECT uuid_generate_v4(), p.id, p.contentable_id, ?, NOW(), NOW()
+			FROM posts p
+			WHERE p.contentable_type = 'chat'
+				AND p.contentable_id = ?
+				AND p.public_id <= ?
+				AND p.author_id <> ?
+				AND p.deleted_at IS NULL
+			ON CONFLICT (message_id, user_id) DO UPDATE
+				SET read_at = NOW(),
+					delivered_at = COALESCE(messages_reads.delivered_at, NOW())
+				WHERE messages_reads.read_at IS NULL
+			RETURNING message_id
+		`, userID, chatID, upToPublicID, userID).Scan(&messageIDs).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Raw(`
+			SELECT COUNT(*)
+			FROM posts p
+			WHERE p.contentable_type = 'chat'
+				AND p.contentable_id = ?
+				AND p.author_id <> ?
+				AND p.deleted_at IS NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM messages_reads mr
+					WHERE mr.message_id = p.id AND mr.user_id = ? AND mr.read_at IS NOT NULL
+				)
+				AND NOT EXISTS (
+					SELECT 1 FROM chat_participants cp
+					WHERE cp.chat_id = p.contentable_id AND cp.user_id = ?
+						AND cp.hidden_at IS NOT NULL AND p.created_at <= cp.hidden_at
+				)
+		`, chatID, userID, userID, userID).Scan(&unreadCount).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&chat.ChatParticipant{}).
+			Where("chat_id = ? AND user_id = ?", chatID, userID).
+			Updates(map[string]interface{}{
+				"unread_count": unreadCount,
+				"last_read_at": time.Now(),
+			}).Error
+	})
+
+	return messageIDs, unreadCount, err
+}
+
+// GetMessageReceipts verilen mesajlar için iletim / okunma özetlerini hesaplar
+func (r *ChatRepository) GetMessageReceipts(messageIDs []uuid.UUID) (map[uuid.UUID]*post.MessageReceipt, error) {
+	receipts := make(map[uuid.UUID]*post.MessageReceipt, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return receipts, nil
+	}
+
+	type receiptRow struct {
+		MessageID      uuid.UUID
+		Recipients     int64
+		DeliveredCount int64
+		SeenCount      int64
+	}
+	var rows []receiptRow
+	err := r.db.Raw(`
+		SELECT p.id AS message_id,
+			(
+				SELECT COUNT(*) FROM chat_participants cp
+				WHERE cp.chat_id = p.contentable_id
+					AND cp.user_id <> p.author_id
+			) AS recipients,
+			COUNT(mr.delivered_at) AS delivered_count,
+			COUNT(mr.read_at) AS seen_count
+		FROM posts p
+		LEFT JOIN messages_reads mr ON mr.message_id = p.id AND mr.user_id <> p.author_id
+		WHERE p.id IN ?
+		GROUP BY p.id
+	`, messageIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		receipts[row.MessageID] = &post.MessageReceipt{
+			Status:         string(chat.ReceiptStatus(row.Recipients, row.DeliveredCount, row.SeenCount)),
+			Recipients:     row.Recipients,
+			DeliveredCount: row.DeliveredCount,
+			SeenCount:      row.SeenCount,
+		}
+	}
+
+	var reads []chat.MessageRead
+	err = r.db.
+		Where("message_id IN ? AND read_at IS NOT NULL", messageIDs).
+		Order("read_at ASC").
+		Find(&reads).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, read := range reads {
+		if receipt, ok := receipts[read.MessageID]; ok {
+			receipt.SeenBy = append(receipt.SeenBy, read.UserID)
+		}
+	}
+
+	return receipts, nil
+}
+
+// AttachReceipts mesajların Receipt alanını doldurur
+func (r *ChatRepository) AttachReceipts(messages []post.Post) error {
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+
+	receipts, err := r.GetMessageReceipts(ids)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		messages[i].Receipt = receipts[messages[i].ID]
+	}
+	return nil
+}
+
+// EditMessage mesajın önceki içeriğini revizyon olarak saklar ve yeni içeriği yazar
+func (r *ChatRepository) EditMessage(message *post.Post, editorID uuid.UUID, content *utils.LocalizedString) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		revision := chat.MessageRevision{
+			ID:        uuid.New(),
+			MessageID: message.ID,
+			EditorID:  editorID,
+			Content:   message.Content,
+			CreatedAt: now,
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+
+		message.Content = content
+		message.EditedAt = &now
+		return tx.Model(&post.Post{}).Where("id = ?", message.ID).Updates(map[string]interface{}{
+			"content":   content,
+			"edited_at": now,
+		}).Error
+	})
+}
+
+func (r *ChatRepository) GetMessageRevisions(messageID uuid.UUID) ([]chat.MessageRevision, error) {
+	var revisions []chat.MessageRevision
+	err := r.db.
+		Where("message_id = ?", messageID).
+		Order("created_at ASC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// HideMessageForUser "benden sil": mesaj sadece bu kullanıcı için gizlenir
+func (r *ChatRepository) HideMessageForUser(messageID, userID uuid.UUID) error {
+	hidden := chat.MessageHidden{
+		ID:        uuid.New(),
+		MessageID: messageID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	return r.db.
+		Where("message_id = ? AND user_id = ?", messageID, userID).
+		FirstOrCreate(&hidden).Error
+}
+
+// TombstoneMessage "herkesten sil": içerik ve revizyonlar silinir, mesaj
+// sohbette "silindi" olarak kalır
+func (r *ChatRepository) TombstoneMessage(message *post.Post, actorID uuid.UUID) error {
+	message.SetExtra(chat.ExtraMessageStatus, chat.Deleted)
+	message.SetExtra(chat.ExtraDeletedBy, actorID)
+	delete(*message.Extras, post.ExtraLinkPreview)
+	message.Title = nil
+	message.Content = nil
+	message.Summary = nil
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("message_id = ?", message.ID).Delete(&chat.MessageRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("message_id = ?", message.ID).Delete(&chat.MessageReaction{}).Error; err != nil {
+			return err
+		}
+		if err := redactReplyPreviews(tx, message); err != nil {
+			return err
+		}
+		return tx.Model(&post.Post{}).Where("id = ?", message.ID).Updates(map[string]interface{}{
+			"title":   nil,
+			"content": nil,
+			"summary": nil,
+			"extras":  message.Extras,
+		}).Error
+	})
+}
+
+// redactReplyPreviews silinen mesajı alıntılayan cevaplardaki içerik
+// önizlemesini boşaltır ve alıntıyı silindi olarak işaretler
+func redactReplyPreviews(tx *gorm.DB, message *post.Post) error {
+	return tx.Exec(`
+		UPDATE posts SET extras = jsonb_set(
+			jsonb_set(extras, '{reply_to,content}', '{}'::jsonb),
+			'{reply_to,deleted}', 'true'::jsonb)
+		WHERE contentable_type = 'chat' AND contentable_id = ?
+			AND extras->'reply_to'->>'id' = ?`,
+		message.ContentableID, message.ID.String()).Error
+}
+
+// HideChatForUser "sohbeti benden sil": o ana kadarki mesajlar kullanıcı için gizlenir
+func (r *ChatRepository) HideChatForUser(chatID, userID uuid.UUID) error {
+	return r.db.Model(&chat.ChatParticipant{}).
+		Where("chat_id = ? AND user_id = ?", chatID, userID).
+		Updates(map[string]interface{}{
+			"hidden_at":    time.Now(),
+			"unread_count": 0,
+		}).Error
+}
+
+// ToggleReaction emoji tepkisini ekler, varsa kaldırır. Eklendiyse true döner.
+func (r *ChatRepository) ToggleReaction(message *post.Post, userID uuid.UUID, emoji string) (bool, error) {
+	result := r.db.
+		Where("message_id = ? AND user_id = ? AND emoji = ?", message.ID, userID, emoji).
+		Delete(&chat.MessageReaction{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return false, nil
+	}
+
+	reaction := chat.MessageReaction{
+		ID:        uuid.New(),
+		MessageID: message.ID,
+		ChatID:    *message.ContentableID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	}
+	if err := r.db.Create(&reaction).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetReactionSummaries mesajlardaki emojileri ilk bırakılma sırasına göre gruplar
+func (r *ChatRepository) GetReactionSummaries(messageIDs []uuid.UUID) (map[uuid.UUID][]post.ReactionSummary, error) {
+	summaries := make(map[uuid.UUID][]post.ReactionSummary, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return summaries, nil
+	}
+
+	var reactions []chat.MessageReaction
+	err := r.db.
+		Where("message_id IN ?", messageIDs).
+		Order("created_at ASC").
+		Find(&reactions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reaction := range reactions {
+		list := summaries[reaction.MessageID]
+		found := false
+		for i := range list {
+			if list[i].Emoji == reaction.Emoji {
+				list[i].Count++
+				list[i].Reactors = append(list[i].Reactors, reaction.UserID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, post.ReactionSummary{
+				Emoji:    reaction.Emoji,
+				Count:    1,
+				Reactors: []uuid.UUID{reaction.UserID},
+			})
+		}
+		summaries[reaction.MessageID] = list
+	}
+	return summaries, nil
+}
+
+// AttachReactions mesajların Reactions alanını doldurur
+func (r *ChatRepository) AttachReactions(messages []post.Post) error {
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+
+	summaries, err := r.GetReactionSummaries(ids)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		messages[i].Reactions = summaries[messages[i].ID]
+	}
+	return nil
+}
+
+// chatContentTSVector sohbet mesajlarının çok dilli Content alanının arama vektörü.
+// Dil bağımsız olması için 'simple' konfigürasyonu kullanılır; GIN index'i
+// aynı ifade üzerinde tanımlı olmalı.
+const chatContentTSVector = `jsonb_to_tsvector('simple', posts.content, '["string"]')`
+
+// chatContentEscaped snippet için HTML'e kaçırılmış mesaj metni. Snippet'te
+// yalnızca ts_headline'ın eklediği <mark> etiketleri ham HTML olarak kalır.
+const chatContentEscaped = `replace(replace(replace(replace(
+	COALESCE((SELECT string_agg(value, ' ') FROM jsonb_each_text(posts.content)), ''),
+	'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+
+// SearchMessages kullanıcının üyesi olduğu sohbetlerde tam metin arama yapar.
+// Gizlenen mesajlar ve gizlenen sohbetin eski mesajları sonuçlara girmez.
+func (r *ChatRepository) SearchMessages(userID uuid.UUID, search types.MessageSearch) (types.MessageSearchResult, error) {
+	type searchRow struct {
+		ID       uuid.UUID
+		PublicID int64
+		Snippet  string
+		Rank     float64
+	}
+
+	query := r.db.Table("posts").
+		Select(`posts.id, posts.public_id,
+			ts_headline('simple', `+chatContentEscaped+`,
+				websearch_to_tsquery('simple', ?),
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS snippet,
+			ts_rank(`+chatContentTSVector+`, websearch_to_tsquery('simple', ?)) AS rank`,
+			search.Query, search.Query).
+		Where("posts.deleted_at IS NULL").
+		Where("posts.contentable_type = ?", "chat").
+		Where(messageNotExpired).
+		Where("posts.contentable_id IN (SELECT chat_id FROM chat_participants WHERE user_id = ?)", userID).
+		Where(chatContentTSVector+" @@ websearch_to_tsquery('simple', ?)", search.Query).
+		Where("NOT EXISTS (SELECT 1 FROM messages_hidden mh WHERE mh.message_id = posts.id AND mh.user_id = ?)", userID).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM chat_participants cp
+			WHERE cp.chat_id = posts.contentable_id AND cp.user_id = ?
+				AND cp.hidden_at IS NOT NULL AND posts.created_at <= cp.hidden_at
+		)`, userID)
+
+	if search.ChatID != nil {
+		query = query.Where("posts.contentable_id = ?", *search.ChatID)
+	}
+	if search.SenderID != nil {
+		query = query.Where("posts.author_id = ?", *search.SenderID)
+	}
+	if search.From != nil {
+		query = query.Where("posts.created_at >= ?", *search.From)
+	}
+	if search.To != nil {
+		query = query.Where("posts.created_at <= ?", *search.To)
+	}
+	if search.Before != nil {
+		query = query.Where("posts.public_id < ?", *search.Before)
+	}
+
+	if search.AttachmentType != "" {
+		attachmentQuery := `EXISTS (
+			SELECT 1 FROM medias m JOIN file_metadata f ON f.id = m.file_id
+			WHERE m.owner_id = posts.id AND m.owner_type = 'post' AND %s
+		)`
+		switch search.AttachmentType {
+		case "image", "video", "audio":
+			query = query.Where(fmt.Sprintf(attachmentQuery, "f.mime_type LIKE ?"), search.AttachmentType+"/%")
+		case "file":
+			query = query.Where(fmt.Sprintf(attachmentQuery,
+				"f.mime_type NOT LIKE 'image/%%' AND f.mime_type NOT LIKE 'video/%%' AND f.mime_type NOT LIKE 'audio/%%'"))
+		default:
+			return types.MessageSearchResult{}, fmt.Errorf("unknown attachment type: %s", search.AttachmentType)
+		}
+	}
+
+	var rows []searchRow
+	if err := query.Order("posts.public_id DESC").Limit(search.Limit + 1).Scan(&rows).Error; err != nil {
+		return types.MessageSearchResult{}, err
+	}
+
+	result := types.MessageSearchResult{Hits: []types.MessageSearchHit{}}
+	if len(rows) > search.Limit {
+		rows = rows[:search.Limit]
+		cursor := strconv.FormatInt(rows[len(rows)-1].PublicID, 10)
+		result.NextCursor = &cursor
+	}
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	var messages []post.Post
+	if err := r.db.
+		Preload("Author").
+		Preload("Attachments").
+		Preload("Attachments.File").
+		Where("id IN ?", ids).
+		Find(&messages).Error; err != nil {
+		return types.MessageSearchResult{}, err
+	}
+	if err := r.AttachReceipts(messages); err != nil {
+		return types.MessageSearchResult{}, err
+	}
+	if err := r.AttachReactions(messages); err != nil {
+		return types.MessageSearchResult{}, err
+	}
+
+	byID := make(map[uuid.UUID]post.Post, len(messages))
+	for _, m := range messages {
+		byID[m.ID] = m
+	}
+	for _, row := range rows {
+		message, ok := byID[row.ID]
+		if !ok {
+			continue
+		}
+		result.Hits = append(result.Hits, types.MessageSearchHit{
+			Message: message,
+			Snippet: row.Snippet,
+			Rank:    row.Rank,
+		})
+	}
+	return result, nil
+}
+
+// SetDisappearAfter sohbetin kaybolan mesaj süresini ayarlar; nil kapatır.
+// Süre sadece bundan sonra gönderilen mesajlara uygulanır.
+func (r *ChatRepository) SetDisappearAfter(chatID uuid.UUID, seconds *int64) error {
+	return r.db.Model(&chat.Chat{}).Where("id = ?", chatID).Update("disappear_after", seconds).Error
+}
+
+// MarkViewOnceOpened kullanıcıyı tek seferlik mesajı açanlara ekler.
+// Kullanıcı daha önce açtıysa false döner.
+func (r *ChatRepository) MarkViewOnceOpened(messageID, userID uuid.UUID) (bool, error) {
+	result := r.db.Exec(`
+		UPDATE posts
+		SET extras = jsonb_set(
+			COALESCE(extras, '{}'::jsonb), '{`+chat.ExtraViewOnceOpenedBy+`}',
+			COALESCE(extras->'`+chat.ExtraViewOnceOpenedBy+`', '[]'::jsonb) || to_jsonb(?::text))
+		WHERE id = ?
+			AND NOT jsonb_exists(COALESCE(extras->'`+chat.ExtraViewOnceOpenedBy+`', '[]'::jsonb), ?)`,
+		userID.String(), messageID, userID.String())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ScheduleViewOncePurge tek seferlik eklerin dosyalarının silineceği zamanı yazar
+func (r *ChatRepository) ScheduleViewOncePurge(messageID uuid.UUID, purgeAt time.Time) error {
+	return r.db.Exec(`
+		UPDATE posts
+		SET extras = jsonb_set(COALESCE(extras, '{}'::jsonb), '{`+chat.ExtraViewOncePurgeAt+`}', to_jsonb(?::text))
+		WHERE id = ?`, purgeAt.UTC().Format(time.RFC3339), messageID).Error
+}
+
+// GetViewOnceDue dosyalarının silinme zamanı gelmiş tek seferlik mesajlar
+func (r *ChatRepository) GetViewOnceDue(limit int) ([]post.Post, error) {
+	var messages []post.Post
+	err := r.db.
+		Where("contentable_type = ?", "chat").
+		Where("(extras->>'"+chat.ExtraViewOncePurgeAt+"')::timestamptz <= ?", time.Now()).
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// MarkViewOnceConsumed dosyaları silinmiş tek seferlik mesajı işaretler
+func (r *ChatRepository) MarkViewOnceConsumed(messageID uuid.UUID) error {
+	return r.db.Exec(`
+		UPDATE posts
+		SET extras = (COALESCE(extras, '{}'::jsonb) - '`+chat.ExtraViewOncePurgeAt+`')
+			|| jsonb_build_object('`+chat.ExtraViewOnceConsumed+`', true)
+		WHERE id = ?`, messageID).Error
+}
+
+// GetExpiredMessages süresi dolmuş kaybolan mesajlar
+func (r *ChatRepository) GetExpiredMessages(limit int) ([]post.Post, error) {
+	var messages []post.Post
+	err := r.db.
+		Where("contentable_type = ? AND expires_at IS NOT NULL AND expires_at <= ?", "chat", time.Now()).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// PurgeMessage mesajı ve mesaja bağlı kayıtları kalıcı olarak siler.
+// Sohbetin son mesajı buysa bir önceki mesaj son mesaj olur.
+func (r *ChatRepository) PurgeMessage(message *post.Post) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			UPDATE chats SET last_message_id = (
+				SELECT p.id FROM posts p
+				WHERE p.contentable_type = 'chat' AND p.contentable_id = chats.id
+					AND p.deleted_at IS NULL AND p.id <> ?
+				ORDER BY p.public_id DESC LIMIT 1
+			)
+			WHERE last_message_id = ?`, message.ID, message.ID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&chat.Chat{}).Where("pinned_msg_id = ?", message.ID).Update("pinned_msg_id", nil).Error; err != nil {
+			return err
+		}
+		if err := redactReplyPreviews(tx, message); err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&chat.MessageRevision{}, &chat.MessageReaction{}, &chat.MessageRead{}, &chat.MessageHidden{}} {
+			if err := tx.Where("message_id = ?", message.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&post.Post{}, "id = ?", message.ID).Error
+	})
+}
+
+// Bu süreden eski "aktif" aramalar yok sayılır. Bağlantısı kopan kullanıcının
+// araması disconnect'te kapatılır; bu sınır yalnızca sunucu yeniden
+// başlarken açık kalan aramalar içindir.
+const staleCallAfter = 12 * time.Hour
+
+func (r *ChatRepository) CreateCall(call *chat_payloads.Call) error {
+	return r.db.Create(call).Error
+}
+
+func (r *ChatRepository) GetCall(callID uuid.UUID) (*chat_payloads.Call, error) {
+	var call chat_payloads.Call
+	if err := r.db.First(&call, "id = ?", callID).Error; err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// UpdateCallStatus aramanın durumunu sadece beklenen durumdaysa değiştirir;
+// eşzamanlı accept / hangup isteklerinde tek bir geçiş kazanır
+func (r *ChatRepository) UpdateCallStatus(call *chat_payloads.Call, from chat_payloads.CallStatus) (bool, error) {
+	result := r.db.Model(&chat_payloads.Call{}).
+		Where("id = ? AND status = ?", call.ID, from).
+		Updates(map[string]interface{}{
+			"status":     call.Status,
+			"duration":   call.Duration,
+			"started_at": call.StartedAt,
+			"ended_at":   call.EndedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetActiveCallForUser kullanıcının çalan veya devam eden araması
+func (r *ChatRepository) GetActiveCallForUser(userID uuid.UUID) (*chat_payloads.Call, error) {
+	var call chat_payloads.Call
+	err := r.db.
+		Where("(caller_id = ? OR receiver_id = ?)", userID, userID).
+		Where("status IN ?", []chat_payloads.CallStatus{chat_payloads.CallRinging, chat_payloads.CallOngoing}).
+		Where("created_at > ?", time.Now().Add(-staleCallAfter)).
+		Order("created_at DESC").
+		First(&call).Error
+	if err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// AddCallMessage biten aramayı sohbet geçmişine mesaj olarak ekler.
+// Cevapsız aramalar alıcının okunmamış sayısını artırır.
+func (r *ChatRepository) AddCallMessage(call *chat_payloads.Call, text string) (*post.Post, error) {
+	messageType := chat.CallAudio
+	if call.CallType == "video" {
+		messageType = chat.CallVideo
+	}
+	extras := map[string]any{
+		chat.ExtraMessageType:  messageType,
+		chat.ExtraCallID:       call.ID,
+		chat.ExtraCallStatus:   call.Status,
+		chat.ExtraCallDuration: call.Duration,
+	}
+
+	chatObj, err := r.GetChatByIDWithoutRelations(call.ChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	contentableType := "chat"
+	now := time.Now()
+	message := &post.Post{
+		ID:              uuid.New(),
+		PublicID:        r.snowFlakeNode.Generate().Int64(),
+		AuthorID:        call.CallerID,
+		Published:       true,
+		PublishedAt:     &now,
+		PostKind:        post.PostTypeChat,
+		ContentCategory: post.ContentNormal,
+		Content:         utils.MakeLocalizedString("en", text),
+		ContentableType: &contentableType,
+		ContentableID:   &call.ChatID,
+		Extras:          &extras,
+		ExpiresAt:       messageExpiry(chatObj),
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		if call.Status == chat_payloads.CallMissed {
+			err := tx.Model(&chat.ChatParticipant{}).
+				Where("chat_id = ? AND user_id = ?", call.ChatID, call.ReceiverID).
+				Update("unread_count", gorm.Expr("unread_count + ?", 1)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&chat.Chat{}).Where("id = ?", call.ChatID).Updates(map[string]interface{}{
+			"last_message_id":        message.ID,
+			"last_message_timestamp": message.CreatedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.postRepo.GetPostByID(message.ID)
+}
+
+var ErrInvalidCoordinates = errors.New("invalid coordinates")
+
+// ValidCoordinates enlem / boylam geçerli aralıkta mı. (0,0) istemcilerin
+// konum alınamadığında gönderdiği varsayılan değer olduğu için reddedilir.
+func ValidCoordinates(lat, lng float64) bool {
+	if lat == 0 && lng == 0 {
+		return false
+	}
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// StartLiveLocation canlı konum mesajını ve oturumunu oluşturur. Kullanıcının
+// aynı sohbetteki önceki aktif oturumu sonlandırılır.
+func (r *ChatRepository) StartLiveLocation(chatObj *chat.Chat, author *models.User, session *chat.LiveLocation) (*post.Post, error) {
+	contentableType := "chat"
+	now := time.Now()
+	message := &post.Post{
+		ID:              uuid.New(),
+		PublicID:        r.snowFlakeNode.Generate().Int64(),
+		AuthorID:        author.ID,
+		Published:       true,
+		PublishedAt:     &now,
+		PostKind:        post.PostTypeChat,
+		ContentCategory: post.ContentNormal,
+		ContentableType: &contentableType,
+		ContentableID:   &chatObj.ID,
+		ExpiresAt:       messageExpiry(chatObj),
+	}
+	session.ID = uuid.New()
+	session.MessageID = message.ID
+	message.SetExtra(chat.ExtraMessageType, chat.Location)
+	message.SetExtra(chat.ExtraLiveLocation, map[string]any{
+		"session_id": session.ID,
+		"expires_at": session.ExpiresAt,
+		"live":       true,
+	})
+
+	lat, lng := session.Latitude, session.Longitude
+	location := &utils.Location{
+		ID:              uuid.New(),
+		ContentableType: utils.LocationOwnerPost,
+		ContentableID:   message.ID,
+		Latitude:        &lat,
+		Longitude:       &lng,
+		LocationPoint:   &extensions.PostGISPoint{Lat: lat, Lng: lng},
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.stopLiveLocations(tx, "chat_id = ? AND user_id = ?", chatObj.ID, author.ID); err != nil {
+			return err
+		}
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(location).Error; err != nil {
+			return err
+		}
+		return tx.Create(session).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chatPost, err := r.postRepo.GetPostByID(message.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.afterMessageCreated(chatObj, chatPost, author); err != nil {
+		return nil, err
+	}
+	return chatPost, nil
+}
+
+// stopLiveLocations koşula uyan aktif oturumları kapatır ve son konumu
+// mesajın konum kaydına yazar
+func (r *ChatRepository) stopLiveLocations(tx *gorm.DB, query string, args ...interface{}) error {
+	var sessions []chat.LiveLocation
+	if err := tx.Where("stopped_at IS NULL").Where(query, args...).Find(&sessions).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, session := range sessions {
+		stoppedAt := now
+		if session.ExpiresAt.Before(now) {
+			stoppedAt = session.ExpiresAt
+		}
+		if err := tx.Model(&chat.LiveLocation{}).Where("id = ?", session.ID).Update("stopped_at", stoppedAt).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec(`
+			UPDATE posts SET extras = jsonb_set(extras, '{`+chat.ExtraLiveLocation+`,live}', 'false'::jsonb)
+			WHERE id = ? AND jsonb_exists(extras, '`+chat.ExtraLiveLocation+`')`, session.MessageID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&utils.Location{}).
+			Where("contentable_type = ? AND contentable_id = ?", utils.LocationOwnerPost, session.MessageID).
+			Updates(map[string]interface{}{
+				"latitude":       session.Latitude,
+				"longitude":      session.Longitude,
+				"location_point": extensions.PostGISPoint{Lat: session.Latitude, Lng: session.Longitude},
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StopLiveLocation oturumu sonlandırır
+func (r *ChatRepository) StopLiveLocation(sessionID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return r.stopLiveLocations(tx, "id = ?", sessionID)
+	})
+}
+
+func (r *ChatRepository) GetLiveLocation(sessionID uuid.UUID) (*chat.LiveLocation, error) {
+	var session chat.LiveLocation
+	if err := r.db.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UpdateLiveLocation son konumu yazar. Oturum aktif değilse veya son
+// güncellemeden bu yana minInterval geçmediyse false döner.
+func (r *ChatRepository) UpdateLiveLocation(session *chat.LiveLocation, minInterval time.Duration) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&chat.LiveLocation{}).
+		Where("id = ? AND user_id = ? AND stopped_at IS NULL AND expires_at > ?", session.ID, session.UserID, now).
+		Where("updated_at <= ?", now.Add(-minInterval)).
+		Updates(map[string]interface{}{
+			"latitude":   session.Latitude,
+			"longitude":  session.Longitude,
+			"accuracy":   session.Accuracy,
+			"heading":    session.Heading,
+			"updated_at": now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetActiveLiveLocations sohbetteki aktif canlı konum oturumları
+func (r *ChatRepository) GetActiveLiveLocations(chatID uuid.UUID) ([]chat.LiveLocation, error) {
+	var sessions []chat.LiveLocation
+	err := r.db.
+		Where("chat_id = ? AND stopped_at IS NULL AND expires_at > ?", chatID, time.Now()).
+		Order("started_at ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// GetExpiredLiveLocations süresi dolduğu halde kapatılmamış oturumlar
+func (r *ChatRepository) GetExpiredLiveLocations(limit int) ([]chat.LiveLocation, error) {
+	var sessions []chat.LiveLocation
+	err := r.db.
+		Where("stopped_at IS NULL AND expires_at <= ?", time.Now()).
+		Limit(limit).
+		Find(&sessions).Error
+	return sessions, err
+}
@@ -373,7 +373,9 @@ func (r *EngagementRepository) GetEngagements(ctx context.Context, contentableTy
 	return r.GetEngagementDetailsWithCursor(ctx, engagement.ID, &engagementKind, cursor, limit)
 }
 
-func (r *EngagementRepository) AddEngagement(ctx context.Context, engagerID uuid.UUID, engageeID uuid.UUID, kind models.EngagementKind, contentableID uuid.UUID, contentableType models.EngagementContentableType) error {
+// AddEngagement adds a new engagement detail and returns its id so the caller
+// can remove exactly this detail later
+func (r *EngagementRepository) AddEngagement(ctx context.Context, engagerID uuid.UUID, engageeID uuid.UUID, kind models.EngagementKind, contentableID uuid.UUID, contentableType models.EngagementContentableType) (uuid.UUID, error) {
 	// Engagement kaydını al veya oluştur
 	var engagement models.Engagement
 
@@ -394,10 +396,10 @@ func (r *EngagementRepository) AddEngagement(ctx context.Context, engagerID uuid
 			UpdatedAt:       time.Now(),
 		}
 		if err := r.db.WithContext(ctx).Create(&engagement).Error; err != nil {
-			return err
+			return uuid.Nil, err
 		}
 	} else if err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
 	// Yeni EngagementDetail oluştur (toggle yok, hep ekle)
@@ -410,5 +412,8 @@ func (r *EngagementRepository) AddEngagement(ctx context.Context, engagerID uuid
 		CreatedAt:    time.Now(),
 	}
 
-	return r.CreateEngagementDetail(ctx, &newDetail)
+	if err := r.CreateEngagementDetail(ctx, &newDetail); err != nil {
+		return uuid.Nil, err
+	}
+	return newDetail.ID, nil
 }
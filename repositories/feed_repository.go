@@ -0,0 +1,435 @@
+package repositories
+
+import (
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/types"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FeedRepository takip akışı. Varsayılan olarak akış okuma anında takip
+// grafiğinden hesaplanır (fan-out-on-read); çok sayıda hesabı takip eden
+// kullanıcılar için feed_items tablosunda önceden hesaplanmış akış tutulur.
+type FeedRepository struct {
+	db       *gorm.DB
+	postRepo *PostRepository
+}
+
+func NewFeedRepository(db *gorm.DB, postRepo *PostRepository) *FeedRepository {
+	return &FeedRepository{db: db, postRepo: postRepo}
+}
+
+// Kullanıcının kendisi, takip ettikleri ve takip ettiği etiketleri taşıyan postlar
+const feedFollowingCondition = `(posts.author_id = @user OR EXISTS (
+	SELECT 1 FROM engagement_details ed
+	WHERE ed.kind = @following AND ed.engager_id = @user AND ed.engagee_id = posts.author_id
+) OR EXISTS (
+	SELECT 1 FROM hashtags fh
+	JOIN hashtag_follows hf ON hf.tag = LOWER(fh.tag)
+	WHERE fh.taggable_id = posts.id AND fh.taggable_type = @hashtag_type AND hf.user_id = @user
+))`
+
+// Engellenen, engelleyen ve sessize alınan yazarlar akışta gösterilmez
+const feedVisibleCondition = `NOT EXISTS (
+	SELECT 1 FROM engagement_details ed
+	WHERE (ed.engager_id = @user AND ed.engagee_id = posts.author_id AND ed.kind IN @hidden)
+		OR (ed.engager_id = posts.author_id AND ed.engagee_id = @user AND ed.kind = @blocking)
+)`
+
+func feedArgs(userID uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"user":         userID,
+		"following":    models.EngagementKindFollowing,
+		"hashtag_type": "post",
+		"blocking":     models.EngagementKindBlocking,
+		"hidden":       []models.EngagementKind{models.EngagementKindBlocking, models.EngagementKindMuting},
+	}
+}
+
+// feedPostsQuery takip akışına girebilecek ana postlar
+func (r *FeedRepository) feedPostsQuery(userID uuid.UUID) *gorm.DB {
+	return r.db.Model(&post.Post{}).
+		Where("posts.contentable_type = ?", post.PostTypePost).
+		Where("posts.parent_id IS NULL").
+		Where(feedFollowingCondition, feedArgs(userID))
+}
+
+// GetFollowingTimeline takip akışını okuma anında hesaplar
+func (r *FeedRepository) GetFollowingTimeline(userID uuid.UUID, limit int, cursor *int64) (types.TimelineResult, error) {
+	query := r.feedPostsQuery(userID).
+		Where(feedVisibleCondition, feedArgs(userID)).
+		Where(audienceCondition(&userID)).
+		Order("posts.public_id DESC").
+		Limit(limit)
+	if cursor != nil {
+		query = query.Where("posts.public_id < ?", *cursor)
+	}
+
+	var ids []uuid.UUID
+	if err := query.Pluck("posts.id", &ids).Error; err != nil {
+		return types.TimelineResult{}, err
+	}
+	return r.timelineResult(ids, userID, limit)
+}
+
+// GetPrecomputedTimeline önceden hesaplanan akışı okur. Takip ve engel durumu
+// okuma anında tekrar kontrol edilir; takipten çıkılan yazarlar gösterilmez.
+func (r *FeedRepository) GetPrecomputedTimeline(userID uuid.UUID, limit int, cursor *int64) (types.TimelineResult, error) {
+	query := r.db.Table("feed_items").
+		Joins("JOIN posts ON posts.id = feed_items.post_id AND posts.deleted_at IS NULL").
+		Where("feed_items.user_id = ?", userID).
+		Where(feedFollowingCondition, feedArgs(userID)).
+		Where(feedVisibleCondition, feedArgs(userID)).
+		Where(audienceCondition(&userID)).
+		Order("feed_items.public_id DESC").
+		Limit(limit)
+	if cursor != nil {
+		query = query.Where("feed_items.public_id < ?", *cursor)
+	}
+
+	var ids []uuid.UUID
+	if err := query.Pluck("feed_items.post_id", &ids).Error; err != nil {
+		return types.TimelineResult{}, err
+	}
+	return r.timelineResult(ids, userID, limit)
+}
+
+// timelineResult postları yükler; sayfa dolmadıysa son sayfadır ve imleç dönmez
+func (r *FeedRepository) timelineResult(ids []uuid.UUID, userID uuid.UUID, limit int) (types.TimelineResult, error) {
+	posts, err := r.postRepo.GetTimelinePostsByIDs(ids, &userID)
+	if err != nil {
+		return types.TimelineResult{}, err
+	}
+
+	var nextCursor *string
+	if len(ids) == limit && len(posts) > 0 {
+		s := strconv.FormatInt(posts[len(posts)-1].PublicID, 10)
+		nextCursor = &s
+	}
+	return types.TimelineResult{
+		Posts:      posts,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// CountFollowing kullanıcının takip ettiği hesap sayısı
+func (r *FeedRepository) CountFollowing(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.EngagementDetail{}).
+		Where("engager_id = ? AND kind = ?", userID, models.EngagementKindFollowing).
+		Count(&count).Error
+	return count, err
+}
+
+// GetFeedState önceden hesaplanan akış durumu; yoksa nil döner
+func (r *FeedRepository) GetFeedState(userID uuid.UUID) (*post.FeedState, error) {
+	var state post.FeedState
+	err := r.db.Where("user_id = ?", userID).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// LastFollowAt kullanıcının en son bir hesabı ya da etiketi takip etmeye
+// başladığı zaman
+func (r *FeedRepository) LastFollowAt(userID uuid.UUID) (*time.Time, error) {
+	var last sql.NullTime
+	err := r.db.Raw(`
+		SELECT GREATEST(
+			(SELECT MAX(created_at) FROM engagement_details WHERE engager_id = @user AND kind = @following),
+			(SELECT MAX(created_at) FROM hashtag_follows WHERE user_id = @user)
+		)`,
+		map[string]interface{}{"user": userID, "following": models.EngagementKindFollowing}).
+		Row().Scan(&last)
+	if err != nil || !last.Valid {
+		return nil, err
+	}
+	return &last.Time, nil
+}
+
+// BuildFeed kullanıcının akışını takip grafiğinden en yeni size post ile
+// yeniden oluşturur
+func (r *FeedRepository) BuildFeed(userID uuid.UUID, size int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&post.FeedItem{}).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec(`
+			INSERT INTO feed_items (user_id, post_id, author_id, public_id, created_at)
+			SELECT @user, posts.id, posts.author_id, posts.public_id, posts.created_at
+			FROM posts
+			WHERE posts.deleted_at IS NULL
+				AND posts.published = TRUE
+				AND posts.contentable_type = @type
+				AND posts.parent_id IS NULL
+				AND `+feedFollowingCondition+`
+			ORDER BY posts.public_id DESC
+			LIMIT @size`,
+			map[string]interface{}{
+				"user":         userID,
+				"following":    models.EngagementKindFollowing,
+				"hashtag_type": "post",
+				"type":         post.PostTypePost,
+				"size":         size,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		state := post.FeedState{UserID: userID, BuiltAt: time.Now()}
+		return tx.Save(&state).Error
+	})
+}
+
+// DropFeed önceden hesaplanan akışı kaldırır; kullanıcı okuma anında
+// hesaplanan akışa döner
+func (r *FeedRepository) DropFeed(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&post.FeedItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&post.FeedState{}).Error
+	})
+}
+
+// FanOutPost yeni postu, akışı önceden hesaplanan takipçilerin, postun
+// etiketlerini takip edenlerin ve yazarın akışına yazar
+func (r *FeedRepository) FanOutPost(p *post.Post) error {
+	return r.db.Exec(`
+		INSERT INTO feed_items (user_id, post_id, author_id, public_id, created_at)
+		SELECT fs.user_id, @post, @author, @public_id, @created_at
+		FROM feed_states fs
+		WHERE fs.user_id = @author OR EXISTS (
+			SELECT 1 FROM engagement_details ed
+			WHERE ed.kind = @following AND ed.engager_id = fs.user_id AND ed.engagee_id = @author
+		) OR EXISTS (
+			SELECT 1 FROM hashtags fh
+			JOIN hashtag_follows hf ON hf.tag = LOWER(fh.tag)
+			WHERE fh.taggable_id = @post AND fh.taggable_type = @hashtag_type AND hf.user_id = fs.user_id
+		)
+		ON CONFLICT DO NOTHING`,
+		map[string]interface{}{
+			"post":         p.ID,
+			"author":       p.AuthorID,
+			"public_id":    p.PublicID,
+			"created_at":   p.CreatedAt,
+			"following":    models.EngagementKindFollowing,
+			"hashtag_type": "post",
+		}).Error
+}
+
+// TrimFeed akışta en yeni keep kaydı bırakır
+func (r *FeedRepository) TrimFeed(userID uuid.UUID, keep int) error {
+	return r.db.Exec(`
+		DELETE FROM feed_items
+		WHERE user_id = @user AND public_id <= (
+			SELECT public_id FROM feed_items
+			WHERE user_id = @user
+			ORDER BY public_id DESC
+			OFFSET @keep LIMIT 1
+		)`,
+		map[string]interface{}{"user": userID, "keep": keep}).Error
+}
+
+// Aday skorunda Engagement.Counts alanlarının ağırlıkları
+var candidateCountWeights = map[models.EngagementKind]float64{
+	models.EngagementKindLikeReceived:    1,
+	models.EngagementKindDisLikeReceived: -1,
+	models.EngagementKindComment:         2,
+	models.EngagementKindRepost:          3,
+	models.EngagementKindQuote:           3,
+	models.EngagementKindBookmark:        2,
+	models.EngagementKindBanana:          1,
+	models.EngagementKindCarrot:          1,
+	models.EngagementKindCoffee:          1,
+	models.EngagementKindKiss:            1,
+	models.EngagementKindTouch:           1,
+	models.EngagementKindView:            0.05,
+}
+
+// Hız ve yakınlık sinyallerinde sayılan etkileşimler
+var candidateSignalKinds = []models.EngagementKind{
+	models.EngagementKindLikeReceived,
+	models.EngagementKindComment,
+	models.EngagementKindRepost,
+	models.EngagementKindQuote,
+	models.EngagementKindBookmark,
+	models.EngagementKindBanana,
+	models.EngagementKindCarrot,
+	models.EngagementKindCoffee,
+	models.EngagementKindKiss,
+	models.EngagementKindTouch,
+}
+
+// engagementScoreSQL engagements e satırındaki sayaçların ağırlıklı toplamı
+func engagementScoreSQL() string {
+	kinds := make([]string, 0, len(candidateCountWeights))
+	for kind := range candidateCountWeights {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+
+	terms := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		key := models.EngagementCountKeys[models.EngagementKind(kind)].CountKey
+		weight := candidateCountWeights[models.EngagementKind(kind)]
+		terms = append(terms, fmt.Sprintf("COALESCE((e.counts->>'%s')::numeric, 0) * %g", key, weight))
+	}
+	return "(" + strings.Join(terms, " + ") + ")"
+}
+
+// GetForYouCandidates "Senin İçin" akışı adaylarını ve sinyallerini döndürür.
+// Adaylar iki kaynaktan gelir: Engagement.Counts'a göre en çok etkileşim alan
+// postlar ve izleyicinin kullandığı / etkileşimde bulunduğu hashtag'lerdeki
+// postlar. Kendi postları, içeriksiz repostlar, engellenen ve sessize alınan
+// yazarlar ve izleyiciye kapalı postlar dahil edilmez.
+func (r *FeedRepository) GetForYouCandidates(userID uuid.UUID, query types.FeedCandidateQuery) ([]types.FeedCandidate, error) {
+	score := engagementScoreSQL()
+	audience, audienceArgs := audienceCondition(&userID)
+	base := `posts.deleted_at IS NULL
+		AND posts.contentable_type = @type
+		AND posts.parent_id IS NULL
+		AND posts.post_kind <> @repost
+		AND posts.created_at > @since
+		AND posts.author_id <> @user
+		AND ` + feedVisibleCondition + `
+		AND ` + audience
+
+	raw := `
+		WITH viewer_location AS (
+			SELECT location_point FROM locations
+			WHERE contentable_type = 'user' AND contentable_id = @user
+				AND deleted_at IS NULL AND location_point IS NOT NULL
+			LIMIT 1
+		),
+		viewer_tags AS (
+			SELECT DISTINCT h.tag FROM hashtags h
+			WHERE h.taggable_type = 'post' AND h.created_at > @affinity_since AND h.taggable_id IN (
+				SELECT p.id FROM posts p WHERE p.author_id = @user AND p.deleted_at IS NULL
+				UNION
+				SELECT e.contentable_id FROM engagement_details d
+				JOIN engagements e ON e.id = d.engagement_id AND e.contentable_type = 'post'
+				WHERE d.engager_id = @user AND d.kind IN @signal_kinds AND d.created_at > @affinity_since
+			)
+		),
+		by_engagement AS (
+			SELECT posts.id, 'engagement' AS source FROM posts
+			JOIN engagements e ON e.contentable_id = posts.id AND e.contentable_type = 'post'
+			WHERE ` + base + `
+			ORDER BY ` + score + ` DESC, posts.public_id DESC
+			LIMIT @pool
+		),
+		by_hashtag AS (
+			SELECT posts.id, 'hashtag' AS source FROM posts
+			WHERE ` + base + ` AND EXISTS (
+				SELECT 1 FROM hashtags h JOIN viewer_tags vt ON vt.tag = h.tag
+				WHERE h.taggable_type = 'post' AND h.taggable_id = posts.id
+			)
+			ORDER BY posts.public_id DESC
+			LIMIT @pool
+		),
+		candidates AS (
+			SELECT id, MIN(source) AS source
+			FROM (SELECT * FROM by_engagement UNION ALL SELECT * FROM by_hashtag) c
+			GROUP BY id
+		)
+		SELECT
+			posts.id AS post_id,
+			posts.public_id,
+			posts.author_id,
+			posts.created_at,
+			candidates.source,
+			COALESCE(` + score + `, 0) AS engagement,
+			(SELECT COUNT(*) FROM engagement_details d
+				WHERE d.engagement_id = e.id AND d.kind IN @signal_kinds AND d.created_at > @velocity_since) AS recent_engagements,
+			(SELECT COUNT(*) FROM engagement_details d
+				WHERE d.engager_id = @user AND d.engagee_id = posts.author_id
+					AND d.kind IN @signal_kinds AND d.created_at > @affinity_since) AS affinity,
+			EXISTS (SELECT 1 FROM engagement_details d
+				WHERE d.engager_id = @user AND d.engagee_id = posts.author_id AND d.kind = @following) AS following,
+			(SELECT ST_Distance(l.location_point, vl.location_point)
+				FROM viewer_location vl, locations l
+				WHERE l.deleted_at IS NULL AND l.location_point IS NOT NULL AND (
+					(l.contentable_type = 'post' AND l.contentable_id = posts.id)
+					OR (l.contentable_type = 'user' AND l.contentable_id = posts.author_id)
+				)
+				ORDER BY l.contentable_type = 'post' DESC
+				LIMIT 1) AS distance_meters,
+			COALESCE((SELECT string_agg(lang, ',')
+				FROM jsonb_object_keys(CASE WHEN jsonb_typeof(posts.content) = 'object' THEN posts.content ELSE '{}'::jsonb END) AS lang
+			), '') AS languages
+		FROM candidates
+		JOIN posts ON posts.id = candidates.id
+		LEFT JOIN engagements e ON e.contentable_id = posts.id AND e.contentable_type = 'post'`
+
+	args := feedArgs(userID)
+	for key, value := range audienceArgs {
+		args[key] = value
+	}
+	args["type"] = post.PostTypePost
+	args["repost"] = post.PostTypeRepost
+	args["since"] = query.Since
+	args["pool"] = query.PoolSize
+	args["velocity_since"] = query.VelocitySince
+	args["affinity_since"] = query.AffinitySince
+	args["signal_kinds"] = candidateSignalKinds
+
+	var candidates []types.FeedCandidate
+	if err := r.db.Raw(raw, args).Scan(&candidates).Error; err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// SaveForYouSnapshot sıralanmış post listesini saklar. Kullanıcının
+// maxAge'den eski listeleri aynı anda silinir.
+func (r *FeedRepository) SaveForYouSnapshot(userID uuid.UUID, postIDs []uuid.UUID, maxAge time.Duration) (*post.ForYouSnapshot, error) {
+	ids := make([]string, len(postIDs))
+	for i, id := range postIDs {
+		ids[i] = id.String()
+	}
+	snapshot := &post.ForYouSnapshot{
+		ID:        uuid.New(),
+		UserID:    userID,
+		PostIDs:   ids,
+		CreatedAt: time.Now(),
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND created_at < ?", userID, snapshot.CreatedAt.Add(-maxAge)).
+			Delete(&post.ForYouSnapshot{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(snapshot).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetForYouSnapshot kullanıcının sıralanmış listesi; bulunamazsa nil döner
+func (r *FeedRepository) GetForYouSnapshot(id, userID uuid.UUID) (*post.ForYouSnapshot, error) {
+	var snapshot post.ForYouSnapshot
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&snapshot).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
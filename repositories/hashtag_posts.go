@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/types"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// İlgili etiketler etiketin en yeni bu kadar postundan hesaplanır
+	relatedHashtagSample = 1000
+	relatedHashtagLimit  = 10
+)
+
+// Etiketi taşıyan postlar; tag küçük harfle verilir
+const hashtagPostCondition = `EXISTS (
+	SELECT 1 FROM hashtags h
+	WHERE h.taggable_id = posts.id AND h.taggable_type = @hashtag_type AND LOWER(h.tag) = @tag
+)`
+
+// GetHashtagPosts etiketi taşıyan ve izleyicinin görebildiği postlar, yeniden eskiye
+func (r *PostRepository) GetHashtagPosts(tag string, limit int, cursor *int64, viewerID *uuid.UUID) (types.TimelineResult, error) {
+	query := r.db.Model(&post.Post{}).
+		Where("posts.contentable_type = ?", post.PostTypePost).
+		Where(hashtagPostCondition, map[string]interface{}{"tag": tag, "hashtag_type": "post"}).
+		Where(audienceCondition(viewerID)).
+		Order("posts.public_id DESC").
+		Limit(limit)
+	if cursor != nil {
+		query = query.Where("posts.public_id < ?", *cursor)
+	}
+
+	var ids []uuid.UUID
+	if err := query.Pluck("posts.id", &ids).Error; err != nil {
+		return types.TimelineResult{}, err
+	}
+	posts, err := r.GetTimelinePostsByIDs(ids, viewerID)
+	if err != nil {
+		return types.TimelineResult{}, err
+	}
+
+	var nextCursor *string
+	if len(posts) > 0 {
+		s := strconv.FormatInt(posts[len(posts)-1].PublicID, 10)
+		nextCursor = &s
+	}
+	return types.TimelineResult{Posts: posts, NextCursor: nextCursor}, nil
+}
+
+// GetHashtagInfo etiketin post sayısı, ilk kullanımı ve aynı postlarda en çok
+// birlikte kullanıldığı etiketler. Yalnızca herkese açık postlar sayılır;
+// sohbet mesajlarının etiketleri kitle koşuluyla dışarıda kalır.
+func (r *PostRepository) GetHashtagInfo(tag string) (types.HashtagInfo, error) {
+	info := types.HashtagInfo{Tag: tag, RelatedTags: []types.RelatedHashtag{}}
+	audience, args := audienceCondition(nil)
+	args["tag"] = tag
+	args["hashtag_type"] = "post"
+	args["sample"] = relatedHashtagSample
+	args["limit"] = relatedHashtagLimit
+
+	var stats struct {
+		PostCount int64
+		FirstSeen *time.Time
+	}
+	err := r.db.Raw(`
+		SELECT COUNT(DISTINCT posts.id) AS post_count, MIN(hashtags.created_at) AS first_seen
+		FROM hashtags
+		JOIN posts ON posts.id = hashtags.taggable_id AND posts.deleted_at IS NULL
+		WHERE LOWER(hashtags.tag) = @tag AND hashtags.taggable_type = @hashtag_type AND `+audience,
+		args).Scan(&stats).Error
+	if err != nil {
+		return info, err
+	}
+	info.PostCount, info.FirstSeen = stats.PostCount, stats.FirstSeen
+
+	err = r.db.Raw(`
+		SELECT LOWER(other.tag) AS tag, COUNT(DISTINCT other.taggable_id) AS count
+		FROM (
+			SELECT hashtags.taggable_id
+			FROM hashtags
+			JOIN posts ON posts.id = hashtags.taggable_id AND posts.deleted_at IS NULL
+			WHERE LOWER(hashtags.tag) = @tag AND hashtags.taggable_type = @hashtag_type AND `+audience+`
+			ORDER BY hashtags.created_at DESC
+			LIMIT @sample
+		) tagged
+		JOIN hashtags other ON other.taggable_id = tagged.taggable_id
+			AND other.taggable_type = @hashtag_type AND LOWER(other.tag) <> @tag
+		GROUP BY 1
+		ORDER BY count DESC, tag
+		LIMIT @limit`,
+		args).Scan(&info.RelatedTags).Error
+	return info, err
+}
+
+// FollowHashtag etiketi takip eder; zaten takip ediliyorsa bir şey yapmaz
+func (r *PostRepository) FollowHashtag(userID uuid.UUID, tag string) error {
+	return r.db.Exec(`
+		INSERT INTO hashtag_follows (user_id, tag, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		userID, tag, time.Now()).Error
+}
+
+func (r *PostRepository) UnfollowHashtag(userID uuid.UUID, tag string) error {
+	return r.db.Where("user_id = ? AND tag = ?", userID, tag).Delete(&models.HashtagFollow{}).Error
+}
+
+func (r *PostRepository) IsFollowingHashtag(userID uuid.UUID, tag string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.HashtagFollow{}).
+		Where("user_id = ? AND tag = ?", userID, tag).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetFollowedHashtags kullanıcının takip ettiği etiketler, en son takip edilen başta
+func (r *PostRepository) GetFollowedHashtags(userID uuid.UUID) ([]models.HashtagFollow, error) {
+	follows := []models.HashtagFollow{}
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&follows).Error
+	return follows, err
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"coolvibes/models"
+	"coolvibes/types"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Şimdiki pencere bu süre içindeki önceki pencerelerle karşılaştırılır
+	TrendBaseline = 7 * 24 * time.Hour
+
+	trendBucketSize = time.Hour
+
+	// Trend sayılmak için pencerede en az bu kadar kullanım gerekir
+	trendMinCount = 3
+)
+
+var (
+	ErrInvalidTrendScope  = errors.New("invalid trend scope")
+	ErrInvalidTrendWindow = errors.New("invalid trend window")
+)
+
+// TrendScopeKey kapsam tipine göre özetlerde kullanılan anahtarı üretir. Şehir
+// adları ülkeler arasında tekrar edebildiğinden şehir kapsamı ülkeyle birlikte
+// verilir.
+func TrendScopeKey(scopeType string, country string, city string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	city = strings.ToLower(strings.TrimSpace(city))
+	switch scopeType {
+	case "", types.TrendScopeGlobal:
+		return "", nil
+	case types.TrendScopeCountry:
+		if country != "" {
+			return country, nil
+		}
+	case types.TrendScopeCity:
+		if country != "" && city != "" {
+			return country + "/" + city, nil
+		}
+	}
+	return "", ErrInvalidTrendScope
+}
+
+// LastTrendBucket en son hesaplanan özetin saati; özet yoksa nil
+func (r *PostRepository) LastTrendBucket() (*time.Time, error) {
+	var last *time.Time
+	err := r.db.Model(&models.HashtagTrendBucket{}).Select("MAX(bucket)").Scan(&last).Error
+	return last, err
+}
+
+// RollupTrends since saatinden itibaren hashtag kullanımlarını kapsam ve saat
+// bazında yeniden sayar, retention süresinden eski özetleri siler. Yalnızca
+// yayınlanmış herkese açık postlar sayılır; konumu olmayan post yalnızca global
+// kapsama girer.
+func (r *PostRepository) RollupTrends(since time.Time, retention time.Duration) error {
+	since = since.Truncate(trendBucketSize)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bucket >= ? OR bucket < ?", since, time.Now().Add(-retention)).
+			Delete(&models.HashtagTrendBucket{}).Error; err != nil {
+			return err
+		}
+		return insertTrendBuckets(tx, "hashtags.created_at >= @since", map[string]interface{}{"since": since})
+	})
+}
+
+// RollupRecentTrends son hesaplanan özetten itibaren sayar; özet yoksa
+// taban çizgisi için retention süresi kadar geçmiş de hesaplanır. Daha eski
+// özetler silme ve düzenlemede recountTrendBuckets ile güncel tutulur.
+func (r *PostRepository) RollupRecentTrends(retention time.Duration) error {
+	since := time.Now().Add(-retention)
+	last, err := r.LastTrendBucket()
+	if err != nil {
+		return err
+	}
+	// Son saat henüz dolmamış olabilir; bir önceki saatten itibaren yeniden sayılır
+	if last != nil && last.After(since) {
+		since = last.Add(-trendBucketSize)
+	}
+	return r.RollupTrends(since, retention)
+}
+
+// insertTrendBuckets filtreye uyan hashtag kullanımlarını kapsam ve saat
+// bazında sayıp özetlere yazar
+func insertTrendBuckets(tx *gorm.DB, filter string, args map[string]interface{}) error {
+	audience, audienceArgs := audienceCondition(nil)
+	for key, value := range audienceArgs {
+		args[key] = value
+	}
+	args["post_type"] = "post"
+	args["global"] = types.TrendScopeGlobal
+	args["country"] = types.TrendScopeCountry
+	args["city"] = types.TrendScopeCity
+
+	return tx.Exec(`
+		INSERT INTO hashtag_trend_buckets (tag, scope_type, scope, bucket, count)
+		SELECT LOWER(hashtags.tag), s.scope_type, s.scope, date_trunc('hour', hashtags.created_at), COUNT(*)
+		FROM hashtags
+		JOIN posts ON posts.id = hashtags.taggable_id AND hashtags.taggable_type = @post_type AND posts.deleted_at IS NULL
+		LEFT JOIN locations l ON l.contentable_id = posts.id AND l.contentable_type = @post_type AND l.deleted_at IS NULL
+		CROSS JOIN LATERAL (VALUES
+			(CAST(@global AS text), ''),
+			(CAST(@country AS text), NULLIF(UPPER(TRIM(l.country_code)), '')),
+			(CAST(@city AS text), NULLIF(UPPER(TRIM(l.country_code)), '') || '/' || NULLIF(LOWER(TRIM(l.city)), ''))
+		) AS s(scope_type, scope)
+		WHERE s.scope IS NOT NULL AND `+audience+` AND `+filter+`
+		GROUP BY 1, 2, 3, 4
+		ON CONFLICT (tag, scope_type, scope, bucket) DO UPDATE SET count = EXCLUDED.count`,
+		args).Error
+}
+
+// trendBucketKeys koşula uyan hashtag'lerin düştüğü (etiket, saat) özetleri
+func trendBucketKeys(tx *gorm.DB, query string, args ...interface{}) ([][]interface{}, error) {
+	var rows []struct {
+		Tag    string
+		Bucket time.Time
+	}
+	err := tx.Model(&models.Hashtag{}).
+		Select("DISTINCT LOWER(tag) AS tag, date_trunc('hour', created_at) AS bucket").
+		Where(query, args...).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	keys := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, []interface{}{row.Tag, row.Bucket})
+	}
+	return keys, nil
+}
+
+// recountTrendBuckets verilen (etiket, saat) özetlerini yeniden sayar. Artımlı
+// hesap yalnızca son saatleri saydığından silinen postlar ve düzenlemede
+// çıkarılan hashtag'ler eski özetlerden bu şekilde düşer.
+func recountTrendBuckets(tx *gorm.DB, keys [][]interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := tx.Where("(tag, bucket) IN ?", keys).Delete(&models.HashtagTrendBucket{}).Error; err != nil {
+		return err
+	}
+	return insertTrendBuckets(tx, "(LOWER(hashtags.tag), date_trunc('hour', hashtags.created_at)) IN @keys",
+		map[string]interface{}{"keys": keys})
+}
+
+// GetTrendingHashtags kapsamdaki hashtag'leri şimdiki penceredeki kullanımın
+// önceki pencerelerin ortalamasından sapmasına göre sıralar. Skor, ortalama ve
+// varyansa göre normalize edilir; ortalamaya eklenen sabitler yeni ve az
+// kullanılan etiketlerin skorunu yumuşatır, sürekli kullanılan etiketler öne
+// çıkmaz. Şimdiki pencere içinde bulunulan saati de kapsar; kısa pencerelerde
+// sayım geçen süreye göre ölçeklenir.
+func (r *PostRepository) GetTrendingHashtags(query types.TrendQuery, now time.Time) ([]types.HashtagTrend, error) {
+	trends := []types.HashtagTrend{}
+	if query.Window < trendBucketSize || query.Window >= TrendBaseline {
+		return nil, ErrInvalidTrendWindow
+	}
+
+	end := now.Truncate(trendBucketSize).Add(trendBucketSize)
+	elapsed := now.Sub(end.Add(-query.Window))
+	if minimum := query.Window / 4; elapsed < minimum {
+		elapsed = minimum
+	}
+
+	err := r.db.Raw(`
+		WITH windows AS (
+			SELECT tag,
+				FLOOR((EXTRACT(EPOCH FROM (CAST(@end AS timestamptz) - bucket)) - CAST(@bucket_seconds AS double precision)) / CAST(@window_seconds AS double precision)) AS slot,
+				SUM(count) AS count
+			FROM hashtag_trend_buckets
+			WHERE scope_type = @scope_type AND scope = @scope AND bucket >= @since AND bucket < @end
+			GROUP BY tag, slot
+		), stats AS (
+			SELECT tag,
+				CAST(COALESCE(SUM(count) FILTER (WHERE slot = 0), 0) AS bigint) AS count,
+				COALESCE(SUM(count) FILTER (WHERE slot > 0), 0) / CAST(@baseline_slots AS double precision) AS mean,
+				COALESCE(SUM(count * count) FILTER (WHERE slot > 0), 0) / CAST(@baseline_slots AS double precision) AS mean_square
+			FROM windows
+			GROUP BY tag
+		)
+		SELECT tag, count, mean AS baseline,
+			(count * CAST(@scale AS double precision) - mean) / SQRT(GREATEST(mean_square - mean * mean, 0) + mean + 1) AS score
+		FROM stats
+		WHERE count >= @min_count
+		ORDER BY score DESC, count DESC, tag
+		LIMIT @limit`,
+		map[string]interface{}{
+			"scope_type":     query.ScopeType,
+			"scope":          query.Scope,
+			"end":            end,
+			"since":          end.Add(-TrendBaseline),
+			"bucket_seconds": trendBucketSize.Seconds(),
+			"window_seconds": query.Window.Seconds(),
+			"baseline_slots": float64(TrendBaseline/query.Window - 1),
+			"scale":          query.Window.Seconds() / elapsed.Seconds(),
+			"min_count":      trendMinCount,
+			"limit":          query.Limit,
+		}).Scan(&trends).Error
+	if err != nil {
+		return nil, err
+	}
+	return trends, nil
+}
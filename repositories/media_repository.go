@@ -5,8 +5,10 @@ import (
 	"coolvibes/helpers"
 	"coolvibes/models/media"
 	"coolvibes/models/utils"
+	"errors"
 	"fmt"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -19,6 +21,20 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	// Ses dosyaları (sesli mesajlar) için izin verilen maksimum süre
+	maxAudioDuration = 15 * time.Minute
+	// Oynatıcıda gösterilen dalga formundaki çubuk sayısı
+	waveformPeaks = 64
+	// Dalga formu hesaplanırken kullanılan örnekleme hızı (mono)
+	waveformSampleRate = 4000
+)
+
+var (
+	ErrAudioTooLong    = fmt.Errorf("audio is longer than %s", maxAudioDuration)
+	ErrAudioUnreadable = errors.New("audio duration could not be read")
+)
+
 type MediaRepository struct {
 	db            *gorm.DB
 	snowFlakeNode *helpers.Node
@@ -173,6 +189,32 @@ func (r *MediaRepository) MakeSureDirectoryPathExists(path string) error {
 	return os.MkdirAll(dir, os.ModePerm)
 }
 
+// DetectMimeType dosyanın tipini istemcinin Content-Type başlığına değil
+// içeriğine göre belirler. Sesli mesajlar çoğunlukla mp4, webm ya da ogg
+// kabında gelir; içerik bu kaplardan biriyse ve istemci ses bildirdiyse ses
+// kabul edilir. Ses dosyası başka bir tip bildirilerek süre sınırı aşılamaz.
+func DetectMimeType(file *multipart.FileHeader) string {
+	src, err := file.Open()
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, _ := src.Read(head)
+	detected := http.DetectContentType(head[:n])
+	switch detected {
+	case "video/mp4", "video/webm", "application/ogg":
+		if declared := file.Header.Get("Content-Type"); strings.HasPrefix(declared, "audio/") {
+			return declared
+		}
+		if detected == "application/ogg" {
+			return "audio/ogg"
+		}
+	}
+	return detected
+}
+
 func (r *MediaRepository) SaveUploadedFile(file *multipart.FileHeader, path string) error {
 	if err := r.MakeSureDirectoryPathExists(path); err != nil {
 		return err
@@ -372,8 +414,122 @@ func (r *MediaRepository) generateVideoVariants(originalPath string, ext string,
 	return videoVars, wptr, hptr, nil
 }
 
+// generateAudioVariants sesi Opus (ogg) ve AAC (m4a) olarak dönüştürür ve
+// dalga formu tepe değerlerini hesaplar. Süre okunamazsa ErrAudioUnreadable,
+// maxAudioDuration'ı aşarsa ErrAudioTooLong döner.
+// döndürür: *utils.AudioVariants, *duration (saniye), error
+func (r *MediaRepository) generateAudioVariants(originalPath string, ext string) (*utils.AudioVariants, *float64, error) {
+	duration, err := probeDuration(originalPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrAudioUnreadable, err)
+	}
+	if duration > maxAudioDuration.Seconds() {
+		return nil, nil, ErrAudioTooLong
+	}
+
+	baseDir := filepath.Dir(originalPath)
+	baseName := strings.TrimSuffix(filepath.Base(originalPath), ext)
+	opusPath := filepath.Join(baseDir, baseName+"_opus.ogg")
+	aacPath := filepath.Join(baseDir, baseName+"_aac.m4a")
+
+	// Konuşma için düşük bitrate yeterli; -vn kapak resmi gibi video akışlarını atar
+	if err := runCmd("ffmpeg", "-y", "-i", originalPath, "-vn", "-ac", "1", "-c:a", "libopus", "-b:a", "32k", "-application", "voip", opusPath); err != nil {
+		fmt.Println("WARN: failed to encode opus:", err)
+	}
+	if err := runCmd("ffmpeg", "-y", "-i", originalPath, "-vn", "-ac", "1", "-c:a", "aac", "-b:a", "64k", "-movflags", "+faststart", aacPath); err != nil {
+		fmt.Println("WARN: failed to encode aac:", err)
+	}
+
+	waveform, err := computeWaveform(originalPath, waveformPeaks)
+	if err != nil {
+		fmt.Println("WARN: failed to compute waveform:", err)
+	}
+
+	makeAudioVariant := func(p string) *utils.VariantInfo {
+		if _, er := os.Stat(p); er != nil {
+			return nil
+		}
+		d, er := probeDuration(p)
+		var dptr *float64
+		if er == nil {
+			dptr = &d
+		}
+		return &utils.VariantInfo{
+			URL:      strings.TrimPrefix(p, "."),
+			Duration: dptr,
+			Format:   strings.TrimPrefix(strings.ToLower(filepath.Ext(p)), "."),
+			Size:     getFileSizeSafe(p),
+		}
+	}
+
+	return &utils.AudioVariants{
+		Opus:     makeAudioVariant(opusPath),
+		AAC:      makeAudioVariant(aacPath),
+		Waveform: waveform,
+	}, &duration, nil
+}
+
 // ---------- yardımcı fonksiyonlar ----------
 
+// probeDuration ffprobe ile medyanın süresini saniye cinsinden döndürür
+func probeDuration(path string) (float64, error) {
+	out, err := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", path).Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+}
+
+// computeWaveform sesi mono 16-bit PCM'e çözüp dalga formunu hesaplar
+func computeWaveform(path string, peaks int) ([]int, error) {
+	out, err := exec.Command("ffmpeg", "-v", "error", "-i", path, "-vn", "-ac", "1", "-ar", strconv.Itoa(waveformSampleRate), "-f", "s16le", "-").Output()
+	if err != nil {
+		return nil, err
+	}
+	return WaveformPeaks(out, peaks)
+}
+
+// WaveformPeaks mono 16-bit little-endian PCM'i peaks adet kovaya böler ve
+// her kovanın tepe genliğini 0-100 arasına ölçekler
+func WaveformPeaks(out []byte, peaks int) ([]int, error) {
+	samples := len(out) / 2
+	if samples == 0 {
+		return nil, fmt.Errorf("no audio samples")
+	}
+	if samples < peaks {
+		peaks = samples
+	}
+
+	values := make([]int, peaks)
+	loudest := 0
+	for i := 0; i < peaks; i++ {
+		start := i * samples / peaks
+		end := (i + 1) * samples / peaks
+		peak := 0
+		for j := start; j < end; j++ {
+			v := int(int16(uint16(out[2*j]) | uint16(out[2*j+1])<<8))
+			if v < 0 {
+				v = -v
+			}
+			if v > peak {
+				peak = v
+			}
+		}
+		values[i] = peak
+		if peak > loudest {
+			loudest = peak
+		}
+	}
+
+	// Sessiz kayıtlarda düz çizgi, diğerlerinde en yüksek tepeye göre normalize
+	for i := range values {
+		if loudest > 0 {
+			values[i] = values[i] * 100 / loudest
+		}
+	}
+	return values, nil
+}
+
 // runCmd çalıştırıp stderr/stdout yakalar, hata döner
 func runCmd(name string, args ...string) error {
 	cmd := exec.Command(name, args...)
@@ -483,12 +639,13 @@ func (r *MediaRepository) AddMedia(ownerID uuid.UUID, ownerType media.OwnerType,
 		return nil, err
 	}
 
-	mimeType := file.Header.Get("Content-Type")
+	mimeType := DetectMimeType(file)
 
 	var (
 		variants *utils.FileVariants
 		width    *int
 		height   *int
+		duration *float64
 		err      error
 	)
 
@@ -514,6 +671,19 @@ func (r *MediaRepository) AddMedia(ownerID uuid.UUID, ownerType media.OwnerType,
 			variants = &utils.FileVariants{Video: videoVariants}
 			width, height = w, h
 		}
+	} else if strings.HasPrefix(mimeType, "audio/") {
+		audioVariants, d, audioErr := r.generateAudioVariants(storagePath, ext)
+		// Süresi bilinmeyen ses de reddedilir; yoksa süre sınırı aşılabilir
+		if errors.Is(audioErr, ErrAudioTooLong) || errors.Is(audioErr, ErrAudioUnreadable) {
+			os.Remove(storagePath)
+			return nil, audioErr
+		}
+		if audioErr != nil {
+			fmt.Println("WARN: audio variant generation failed:", audioErr)
+		} else {
+			variants = &utils.FileVariants{Audio: audioVariants}
+			duration = d
+		}
 	}
 
 	media := media.Media{
@@ -533,6 +703,7 @@ func (r *MediaRepository) AddMedia(ownerID uuid.UUID, ownerType media.OwnerType,
 			Name:        file.Filename,
 			Width:       width,
 			Height:      height,
+			Duration:    duration,
 			Variants:    variants,
 			CreatedAt:   time.Now(),
 		},
@@ -549,3 +720,82 @@ func (r *MediaRepository) AddMedia(ownerID uuid.UUID, ownerType media.OwnerType,
 
 	return &media, nil
 }
+
+// filePaths dosyanın kendisi ve üretilmiş tüm varyantlarının disk yollarını döndürür
+func filePaths(file utils.FileMetadata) []string {
+	paths := []string{file.StoragePath}
+	if file.Variants == nil {
+		return paths
+	}
+
+	var variants []*utils.VariantInfo
+	if img := file.Variants.Image; img != nil {
+		variants = append(variants, img.Icon, img.Thumbnail, img.Small, img.Medium, img.Large, img.Original)
+	}
+	if vid := file.Variants.Video; vid != nil {
+		variants = append(variants, vid.Poster, vid.Low, vid.Medium, vid.High, vid.Preview)
+	}
+	if audio := file.Variants.Audio; audio != nil {
+		variants = append(variants, audio.Opus, audio.AAC)
+	}
+	for _, v := range variants {
+		if v != nil && v.URL != "" {
+			// Varyant URL'i storage path'in başındaki "." olmadan tutuluyor
+			paths = append(paths, "."+v.URL)
+		}
+	}
+	return paths
+}
+
+// DeleteMedia medya kaydını siler. Dosya başka bir medya kaydı tarafından
+// kullanılmıyorsa (ör. iletilen mesajlar) file_metadata ve diskteki dosyalar da silinir.
+func (r *MediaRepository) DeleteMedia(m *media.Media) error {
+	if err := r.db.Delete(&media.Media{}, "id = ?", m.ID).Error; err != nil {
+		return err
+	}
+
+	var refs int64
+	if err := r.db.Model(&media.Media{}).Where("file_id = ?", m.FileID).Count(&refs).Error; err != nil {
+		return err
+	}
+	if refs > 0 {
+		return nil
+	}
+
+	var file utils.FileMetadata
+	if err := r.db.First(&file, "id = ?", m.FileID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+	if err := r.db.Delete(&file).Error; err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, path := range filePaths(file) {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			fmt.Println("WARN: failed to remove file:", path, err)
+		}
+	}
+	return nil
+}
+
+// DeleteMediaByOwner bir içeriğe (post, mesaj) ait tüm medyaları siler
+func (r *MediaRepository) DeleteMediaByOwner(ownerID uuid.UUID) error {
+	var medias []media.Media
+	if err := r.db.Where("owner_id = ?", ownerID).Find(&medias).Error; err != nil {
+		return err
+	}
+	for i := range medias {
+		if err := r.DeleteMedia(&medias[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
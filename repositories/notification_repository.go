@@ -15,9 +15,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// EventPublisher kullanıcıya gerçek zamanlı event gönderebilen servis (socket).
+// repositories -> socket import döngüsüne girmemek için arayüz olarak tutulur.
+type EventPublisher interface {
+	EmitToUser(userID uuid.UUID, event string, message map[string]interface{}) error
+}
+
 type NotificationRepository struct {
 	db            *gorm.DB
 	snowFlakeNode *helpers.Node
+	publisher     EventPublisher
 }
 
 func (r *NotificationRepository) DB() *gorm.DB {
@@ -32,6 +39,11 @@ func NewNotificationRepository(db *gorm.DB, snowFlakeNode *helpers.Node) *Notifi
 	return &NotificationRepository{db: db, snowFlakeNode: snowFlakeNode}
 }
 
+// SetEventPublisher bildirimlerin socket üzerinden de iletilmesini sağlar
+func (r *NotificationRepository) SetEventPublisher(publisher EventPublisher) {
+	r.publisher = publisher
+}
+
 func (r *NotificationRepository) GetAllSubscriptions() ([]models.Subscription, error) {
 	var users []models.User
 	err := r.db.Find(&users).Error
@@ -83,6 +95,15 @@ func (r *NotificationRepository) SendNotificationToUser(sender models.User, rece
 
 	fmt.Println(notification.ID)
 
+	// Socket bağlıysa anında, değilse yeniden bağlanınca replay ile iletilir
+	if r.publisher != nil {
+		if err := r.publisher.EmitToUser(receiver.ID, "notification", map[string]interface{}{
+			"notification": notification,
+		}); err != nil {
+			fmt.Println("failed to emit notification:", err)
+		}
+	}
+
 	var subscriptions []models.Subscription
 	if len(receiver.Subscriptions) == 0 {
 		return fmt.Errorf("user has no subscriptions")
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"coolvibes/constants"
+	"coolvibes/models"
+	"coolvibes/models/media"
+	"coolvibes/models/post"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAudience = errors.New("invalid audience")
+	ErrPostNotFound    = errors.New("post not found")
+)
+
+// Anonim izleyici yalnızca yayınlanmış herkese açık postları görür. Sohbet
+// mesajları da posts tablosunda tutulur; post yüzeylerinde hiç gösterilmez.
+const audiencePublicCondition = `posts.contentable_type = @contentable_post AND posts.published = TRUE AND posts.audience = @public`
+
+// Giriş yapmış izleyici herkese açık postları, kendi postlarını, takip ettiği
+// yazarların takipçilere açık postlarını ve karşılıklı takipleştiği yazarların
+// arkadaşlara/karşılıklılara açık postlarını görür. Gizli postları yalnızca
+// yazarı görür. Taslak ve zamanlanmış postlar yazarına da akışlarda
+// gösterilmez; kendi listelerinden okunur.
+const audienceViewerCondition = `posts.contentable_type = @contentable_post AND posts.published = TRUE AND (posts.audience = @public OR posts.author_id = @viewer
+	OR (posts.audience IN @followers AND EXISTS (
+		SELECT 1 FROM engagement_details af
+		WHERE af.kind = @following AND af.engager_id = @viewer AND af.engagee_id = posts.author_id
+	) AND (posts.audience = @followers_only OR EXISTS (
+		SELECT 1 FROM engagement_details ab
+		WHERE ab.kind = @following AND ab.engager_id = posts.author_id AND ab.engagee_id = @viewer
+	))))`
+
+// audienceCondition izleyicinin görebileceği postlar için koşul; viewerID nil
+// ise izleyici anonimdir
+func audienceCondition(viewerID *uuid.UUID) (string, map[string]interface{}) {
+	if viewerID == nil {
+		return audiencePublicCondition, map[string]interface{}{
+			"public":           constants.PrivacyPublic,
+			"contentable_post": post.PostTypePost,
+		}
+	}
+	return audienceViewerCondition, map[string]interface{}{
+		"contentable_post": post.PostTypePost,
+		"viewer":           *viewerID,
+		"public":           constants.PrivacyPublic,
+		"followers_only":   constants.PrivacyFollowersOnly,
+		"followers": []constants.PrivacyLevel{
+			constants.PrivacyFollowersOnly,
+			constants.PrivacyMutualsOnly,
+			constants.PrivacyFriendsOnly,
+		},
+		"following": models.EngagementKindFollowing,
+	}
+}
+
+// ParseAudience formdaki kitleyi doğrular; boşsa yazarın gizlilik seviyesi,
+// o da geçersizse herkese açık kullanılır
+func ParseAudience(raw string, author *models.User) (constants.PrivacyLevel, error) {
+	if raw == "" {
+		if author != nil && IsValidAudience(author.PrivacyLevel) {
+			return author.PrivacyLevel, nil
+		}
+		return constants.PrivacyPublic, nil
+	}
+	audience := constants.PrivacyLevel(raw)
+	if !IsValidAudience(audience) {
+		return "", ErrInvalidAudience
+	}
+	return audience, nil
+}
+
+func IsValidAudience(audience constants.PrivacyLevel) bool {
+	switch audience {
+	case constants.PrivacyPublic,
+		constants.PrivacyFriendsOnly,
+		constants.PrivacyFollowersOnly,
+		constants.PrivacyMutualsOnly,
+		constants.PrivacyPrivate:
+		return true
+	}
+	return false
+}
+
+// visibleParentCondition yanıtlanan postun izleyiciye açık olması; alt
+// sorgudaki posts dıştaki posts tablosunu gölgeler
+func visibleParentCondition(viewerID *uuid.UUID) (string, map[string]interface{}) {
+	condition, args := audienceCondition(viewerID)
+	return "posts.parent_id IN (SELECT posts.id FROM posts WHERE " + condition + ")", args
+}
+
+// visibleMediaCondition posta ait medyalar yalnızca post izleyiciye açıksa
+// gösterilir; sohbet mesajlarının ekleri ve düzenlemede posttan çıkarılan
+// ekler profilde hiç gösterilmez
+func visibleMediaCondition(viewerID *uuid.UUID) (string, map[string]interface{}) {
+	condition, args := audienceCondition(viewerID)
+	args["owner_post"] = media.OwnerPost
+	args["owner_hidden"] = []media.OwnerType{media.OwnerChat, media.OwnerPostRevision}
+	args["contentable_post"] = post.PostTypePost
+	return `medias.owner_type NOT IN @owner_hidden AND (medias.owner_type <> @owner_post OR medias.owner_id IN (
+		SELECT posts.id FROM posts
+		WHERE posts.contentable_type = @contentable_post AND posts.deleted_at IS NULL AND ` + condition + `
+	))`, args
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/models/post/payloads"
+	"coolvibes/models/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeletePost postu yanıt ağacıyla birlikte soft delete eder. Satırlar
+// silinmez; deleted_at ve Extras'taki tombstone bilgileriyle kalır. Postun ve
+// içeriksiz repostlarının anket, etkinlik, hashtag, mention, etkileşim,
+// revizyon kayıtları ile ekleri ve dosyaları temizlenir. Yanıtlar başka
+// kullanıcılara ait olabileceğinden yalnızca tombstone olur; içerikleri ve
+// dosyaları kalır. Silinen postların ID'leri döner.
+func (r *PostRepository) DeletePost(p *post.Post, actorID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	tree := `
+		WITH RECURSIVE post_tree AS (
+			SELECT posts.id FROM posts WHERE posts.id = ? AND posts.deleted_at IS NULL
+			UNION ALL
+			SELECT posts.id FROM posts
+			INNER JOIN post_tree pt ON pt.id = posts.parent_id
+			WHERE posts.deleted_at IS NULL
+		)
+		SELECT id FROM post_tree`
+	if err := r.db.Raw(tree, p.ID).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("post with id %s: %w", p.ID, ErrPostNotFound)
+	}
+
+	// Silinen postların içeriksiz repostları da kaldırılır; alıntılar kalır ve
+	// orijinal "kullanılamıyor" olarak gösterilir
+	var reposts []uuid.UUID
+	if err := r.db.Model(&post.Post{}).
+		Where("repost_of_id IN ? AND post_kind = ?", ids, post.PostTypeRepost).
+		Pluck("id", &reposts).Error; err != nil {
+		return nil, err
+	}
+	ids = append(ids, reposts...)
+	cleaned := append([]uuid.UUID{p.ID}, reposts...)
+
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Silinen postların düştüğü trend özetleri sonda yeniden sayılır;
+		// tombstone olan yanıtların hashtag'leri kalsa da sayılmaz
+		trendKeys, err := trendBucketKeys(tx, "taggable_id IN ? AND taggable_type = ?", ids, "post")
+		if err != nil {
+			return err
+		}
+
+		// Anketler, seçenekler ve oylar
+		const pollIDs = `SELECT polls.id FROM polls WHERE polls.contentable_id IN ? AND polls.contentable_type = ?`
+		if err := tx.Where("choice_id IN (SELECT poll_choices.id FROM poll_choices WHERE poll_choices.poll_id IN ("+pollIDs+"))", cleaned, payloads.ContentablePollPost).Delete(&payloads.PollVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("poll_id IN ("+pollIDs+")", cleaned, payloads.ContentablePollPost).Delete(&payloads.PollChoice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("contentable_id IN ? AND contentable_type = ?", cleaned, payloads.ContentablePollPost).Delete(&payloads.Poll{}).Error; err != nil {
+			return err
+		}
+
+		// Etkinlikler, katılımcılar ve konumları
+		const eventIDs = `SELECT events.id FROM events WHERE events.post_id IN ?`
+		if err := tx.Where("event_id IN ("+eventIDs+")", cleaned).Delete(&payloads.EventAttendee{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("contentable_id IN ("+eventIDs+") AND contentable_type = ?", cleaned, utils.LocationOwnerEvent).Delete(&utils.Location{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", cleaned).Delete(&payloads.Event{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("contentable_id IN ? AND contentable_type = ?", cleaned, utils.LocationOwnerPost).Delete(&utils.Location{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("taggable_id IN ? AND taggable_type = ?", cleaned, "post").Delete(&models.Hashtag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("mentionable_id IN ? AND mentionable_type = ?", cleaned, "post").Delete(&models.Mention{}).Error; err != nil {
+			return err
+		}
+
+		// Beğeni, yorum vb. sayaçlar postun engagement kaydında tutulur
+		if err := tx.Where("engagement_id IN (SELECT engagements.id FROM engagements WHERE engagements.contentable_id IN ? AND engagements.contentable_type = ?)",
+			cleaned, models.EngagementContentableTypePost).Delete(&models.EngagementDetail{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("contentable_id IN ? AND contentable_type = ?", cleaned, models.EngagementContentableTypePost).Delete(&models.Engagement{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("post_id IN ?", cleaned).Delete(&post.PostRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id IN ?", ids).Delete(&post.FeedItem{}).Error; err != nil {
+			return err
+		}
+
+		err = tx.Exec(`UPDATE posts SET deleted_at = @now,
+			extras = COALESCE(extras, '{}'::jsonb) || jsonb_build_object(CAST(@deleted_by AS text), CAST(@actor AS text), CAST(@deleted_with AS text), CAST(@root AS text))
+			WHERE id IN @ids`, map[string]interface{}{
+			"now":          now,
+			"deleted_by":   post.ExtraDeletedBy,
+			"deleted_with": post.ExtraDeletedWith,
+			"actor":        actorID.String(),
+			"root":         p.ID.String(),
+			"ids":          ids,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recountTrendBuckets(tx, trendKeys)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Dosyalar diskten de silindiği için transaction dışında; düzenlemede
+	// posttan ayrılan ekler de aynı owner_id ile tutulur
+	if err := r.mediaRepo.DeleteMediaByOwner(p.ID); err != nil {
+		return ids, err
+	}
+
+	// Silinen yanıtın üst postundaki yorum sayacı düşürülür
+	if err := r.removeEngagementDetail(p, post.ExtraParentEngagementID); err != nil {
+		return ids, err
+	}
+	if err := r.removeRepostEngagements(ids); err != nil {
+		return ids, err
+	}
+	return ids, nil
+}
+
+// storeEngagementDetail yanıt, repost ya da alıntı oluşturulurken hedef
+// posta eklenen etkileşim kaydının ID'sini kaynak postta saklar
+func (r *PostRepository) storeEngagementDetail(source *post.Post, key string, detailID uuid.UUID) error {
+	if err := r.SetPostExtra(source.ID, key, detailID.String()); err != nil {
+		return err
+	}
+	source.SetExtra(key, detailID.String())
+	return nil
+}
+
+// removeEngagementDetail kaynak postun hedef postta oluşturduğu etkileşim
+// kaydını kaldırır. Kayıt ID'si saklanmamış eski postlarda ya da kayıt hedefle
+// birlikte silinmişse bir şey yapılmaz.
+func (r *PostRepository) removeEngagementDetail(source *post.Post, key string) error {
+	value, _ := source.GetExtra(key)
+	detailID, err := uuid.Parse(fmt.Sprint(value))
+	if err != nil {
+		return nil
+	}
+	err = r.userRepo.engagementRepo.RemoveEngagementDetail(context.Background(), detailID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
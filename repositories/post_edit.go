@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/models/media"
+	"coolvibes/models/post"
+	"coolvibes/models/utils"
+	"mime/multipart"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UpdateContentablePost postun başlık, içerik, özet, hashtag, mention ve
+// eklerini düzenler. Düzenlemeden önceki hali post_revisions tablosuna
+// yazılır. Formda gönderilmeyen alanlar olduğu gibi kalır; hashtag ve
+// mention'lar yeni içerikten tekrar çıkarılır. Yeni mention edilen
+// kullanıcıların ID'leri döner.
+func (r *PostRepository) UpdateContentablePost(p *post.Post, request map[string][]string, files []*multipart.FileHeader, editor *models.User) ([]uuid.UUID, error) {
+	formValue := func(key string) (string, bool) {
+		values, ok := request[key]
+		if !ok {
+			return "", false
+		}
+		if len(values) == 0 {
+			return "", true
+		}
+		return values[0], true
+	}
+
+	var currentTags []models.Hashtag
+	if err := r.db.Where("taggable_id = ? AND taggable_type = ?", p.ID, "post").Find(&currentTags).Error; err != nil {
+		return nil, err
+	}
+	var currentMentions []models.Mention
+	if err := r.db.Where("mentionable_id = ? AND mentionable_type = ?", p.ID, "post").Find(&currentMentions).Error; err != nil {
+		return nil, err
+	}
+	var currentAttachments []media.Media
+	if err := r.db.Where("owner_id = ? AND owner_type = ?", p.ID, media.OwnerPost).Find(&currentAttachments).Error; err != nil {
+		return nil, err
+	}
+
+	language := editor.DefaultLanguage
+	title, content, summary := p.Title, p.Content, p.Summary
+	if v, ok := formValue("title"); ok {
+		title = utils.MakeLocalizedString(language, v)
+	}
+	contentChanged := false
+	if v, ok := formValue("content"); ok {
+		content = utils.MakeLocalizedString(language, v)
+		contentChanged = true
+	}
+	if v, ok := formValue("summary"); ok {
+		summary = utils.MakeLocalizedString(language, v)
+	}
+
+	// Hashtag ve mention'lar içerik ya da listeler değiştiyse yeniden çıkarılır
+	tags := make([]string, 0, len(currentTags))
+	for _, h := range currentTags {
+		tags = append(tags, h.Tag)
+	}
+	explicitTags, tagsSent := request["hashtags[]"]
+	if contentChanged || tagsSent {
+		tags = helpers.ExtractHashtags(localizedText(content), explicitTags)
+	}
+
+	mentionIDs := make([]uuid.UUID, 0, len(currentMentions))
+	for _, m := range currentMentions {
+		mentionIDs = append(mentionIDs, m.UserID)
+	}
+	explicitMentions, mentionsSent := request["mentions[]"]
+	if contentChanged || mentionsSent {
+		mentionIDs = mentionIDs[:0]
+		seen := map[uuid.UUID]bool{}
+		for _, name := range helpers.ExtractMentions(localizedText(content), explicitMentions) {
+			mentionUser, err := r.userRepo.GetUserByNameOrEmailOrNickname(name)
+			if err != nil || seen[mentionUser.ID] {
+				continue
+			}
+			seen[mentionUser.ID] = true
+			mentionIDs = append(mentionIDs, mentionUser.ID)
+		}
+	}
+
+	// Çıkarılacak ekler medya public_id'leri ile gönderilir
+	removed := map[int64]bool{}
+	for _, raw := range request["remove_attachments[]"] {
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			removed[id] = true
+		}
+	}
+
+	now := time.Now()
+	var added []uuid.UUID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Eşzamanlı düzenlemeler aynı revizyon numarasını almasın diye post
+		// satırı kilitlenir
+		if err := tx.Exec("SELECT id FROM posts WHERE id = ? FOR UPDATE", p.ID).Error; err != nil {
+			return err
+		}
+		var revisionCount int64
+		if err := tx.Model(&post.PostRevision{}).Where("post_id = ?", p.ID).Count(&revisionCount).Error; err != nil {
+			return err
+		}
+
+		revision := post.PostRevision{
+			ID:        uuid.New(),
+			PostID:    p.ID,
+			Revision:  int(revisionCount) + 1,
+			EditorID:  editor.ID,
+			Title:     p.Title,
+			Content:   p.Content,
+			Summary:   p.Summary,
+			CreatedAt: now,
+		}
+		for _, h := range currentTags {
+			revision.Hashtags = append(revision.Hashtags, h.Tag)
+		}
+		for _, m := range currentMentions {
+			revision.MentionIDs = append(revision.MentionIDs, m.UserID.String())
+		}
+		for _, a := range currentAttachments {
+			revision.AttachmentIDs = append(revision.AttachmentIDs, a.ID.String())
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+
+		// Kalan hashtag'ler silinip yeniden oluşturulmaz; oluşturulma zamanları
+		// trend hesabında kullanılır
+		keepTags := map[string]bool{}
+		for _, tag := range tags {
+			keepTags[strings.ToLower(tag)] = true
+		}
+		existingTags := map[string]bool{}
+		var removedTags []uuid.UUID
+		for _, h := range currentTags {
+			key := strings.ToLower(h.Tag)
+			if !keepTags[key] || existingTags[key] {
+				removedTags = append(removedTags, h.ID)
+				continue
+			}
+			existingTags[key] = true
+		}
+		if len(removedTags) > 0 {
+			// Çıkarılan hashtag'ler eski trend özetlerinden de düşer
+			trendKeys, err := trendBucketKeys(tx, "id IN ?", removedTags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.Hashtag{}, "id IN ?", removedTags).Error; err != nil {
+				return err
+			}
+			if err := recountTrendBuckets(tx, trendKeys); err != nil {
+				return err
+			}
+		}
+		for _, tag := range tags {
+			if existingTags[strings.ToLower(tag)] {
+				continue
+			}
+			hashtag := models.Hashtag{ID: uuid.New(), TaggableID: p.ID, TaggableType: "post", Tag: tag, CreatedAt: now}
+			if err := tx.Create(&hashtag).Error; err != nil {
+				return err
+			}
+		}
+
+		keepMentions := map[uuid.UUID]bool{}
+		for _, id := range mentionIDs {
+			keepMentions[id] = true
+		}
+		existingMentions := map[uuid.UUID]bool{}
+		for _, m := range currentMentions {
+			if !keepMentions[m.UserID] || existingMentions[m.UserID] {
+				if err := tx.Delete(&models.Mention{}, "id = ?", m.ID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			existingMentions[m.UserID] = true
+		}
+		for _, id := range mentionIDs {
+			if existingMentions[id] {
+				continue
+			}
+			mention := models.Mention{ID: uuid.New(), MentionableID: p.ID, MentionableType: "post", UserID: id, CreatedAt: now}
+			if err := tx.Create(&mention).Error; err != nil {
+				return err
+			}
+			added = append(added, id)
+		}
+
+		// Çıkarılan ekler silinmez; revizyon geçmişinde gösterilebilmeleri için
+		// posttan ayrılır
+		var detach []uuid.UUID
+		for _, a := range currentAttachments {
+			if removed[a.PublicID] {
+				detach = append(detach, a.ID)
+			}
+		}
+		if len(detach) > 0 {
+			if err := tx.Model(&media.Media{}).Where("id IN ?", detach).Update("owner_type", media.OwnerPostRevision).Error; err != nil {
+				return err
+			}
+		}
+
+		p.Title, p.Content, p.Summary, p.EditedAt = title, content, summary, &now
+		return tx.Model(&post.Post{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+			"title":     title,
+			"content":   content,
+			"summary":   summary,
+			"edited_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Yeni ekler dosya olarak da yazıldığından düzenleme kaydedildikten sonra
+	// yüklenir; geri alınan düzenlemede posta ek kalmaz
+	for _, f := range files {
+		if _, err := r.mediaRepo.AddMedia(p.ID, media.OwnerPost, editor.ID, media.RolePost, f); err != nil {
+			return added, err
+		}
+	}
+	return added, nil
+}
+
+// GetPostRevisions postun önceki hallerini eskiden yeniye, o anki ekleriyle döndürür
+func (r *PostRepository) GetPostRevisions(postID uuid.UUID) ([]post.PostRevision, error) {
+	var revisions []post.PostRevision
+	if err := r.db.Where("post_id = ?", postID).Order("revision ASC").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, revision := range revisions {
+		ids = append(ids, revision.AttachmentIDs...)
+	}
+	if len(ids) == 0 {
+		return revisions, nil
+	}
+
+	var medias []*media.Media
+	if err := r.db.Preload("File").Where("id IN ?", ids).Find(&medias).Error; err != nil {
+		return nil, err
+	}
+	mediaMap := make(map[string]*media.Media, len(medias))
+	for _, m := range medias {
+		mediaMap[m.ID.String()] = m
+	}
+	for i := range revisions {
+		for _, id := range revisions[i].AttachmentIDs {
+			if m, ok := mediaMap[id]; ok {
+				revisions[i].Attachments = append(revisions[i].Attachments, m)
+			}
+		}
+	}
+	return revisions, nil
+}
+
+// localizedText tüm dillerdeki metni birleştirir; hashtag ve mention çıkarmak için
+func localizedText(ls *utils.LocalizedString) string {
+	if ls == nil {
+		return ""
+	}
+	langs := make([]string, 0, len(*ls))
+	for lang := range *ls {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	parts := make([]string, 0, len(langs))
+	for _, lang := range langs {
+		parts = append(parts, (*ls)[lang])
+	}
+	return strings.Join(parts, "\n")
+}
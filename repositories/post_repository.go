@@ -10,6 +10,7 @@ import (
 	"coolvibes/models/post"
 	"coolvibes/models/post/payloads"
 	"coolvibes/models/utils"
+	"encoding/json"
 	"errors"
 	"strconv"
 
@@ -17,6 +18,7 @@ import (
 	"coolvibes/types"
 	"mime/multipart"
 	"sort"
+	"strings"
 
 	"fmt"
 	"time"
@@ -130,24 +132,47 @@ func (r *PostRepository) GetPostByIDEx(id uuid.UUID) (*post.Post, error) {
 }
 
 func (r *PostRepository) GetPostByID(id uuid.UUID) (*post.Post, error) {
+	return r.getPostTree(id, "", nil)
+}
+
+// GetVisiblePostByID postu izleyicinin görebildiği yanıtlarıyla yükler; post
+// izleyiciye kapalıysa ErrPostNotFound döner. viewerID nil ise izleyici anonimdir.
+func (r *PostRepository) GetVisiblePostByID(id uuid.UUID, viewerID *uuid.UUID) (*post.Post, error) {
+	condition, args := audienceCondition(viewerID)
+	return r.getPostTree(id, condition, args)
+}
+
+// getPostTree postu yanıt ağacıyla yükler; condition boş değilse ağaçta
+// yalnızca koşula uyan postlar (ve altları) yer alır
+func (r *PostRepository) getPostTree(id uuid.UUID, condition string, args map[string]interface{}) (*post.Post, error) {
+	filter := ""
+	if condition != "" {
+		filter = " AND " + condition
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	args["id"] = id
+
 	var ids []uuid.UUID
 	cte := `
 		WITH RECURSIVE post_tree AS (
-			SELECT id
+			SELECT posts.id
 			FROM posts
-			WHERE id = ?
+			WHERE posts.id = @id AND posts.deleted_at IS NULL` + filter + `
 			UNION ALL
-			SELECT p.id
-			FROM posts p
-			INNER JOIN post_tree pt ON pt.id = p.parent_id
+			SELECT posts.id
+			FROM posts
+			INNER JOIN post_tree pt ON pt.id = posts.parent_id
+			WHERE posts.deleted_at IS NULL` + filter + `
 		)
 		SELECT id FROM post_tree;
 	`
-	if err := r.db.Raw(cte, id).Scan(&ids).Error; err != nil {
+	if err := r.db.Raw(cte, args).Scan(&ids).Error; err != nil {
 		return nil, err
 	}
 	if len(ids) == 0 {
-		return nil, fmt.Errorf("post with id %s not found", id)
+		return nil, fmt.Errorf("post with id %s: %w", id, ErrPostNotFound)
 	}
 
 	var posts []post.Post
@@ -183,7 +208,10 @@ func (r *PostRepository) GetPostByID(id uuid.UUID) (*post.Post, error) {
 	}
 
 	if len(posts) == 0 {
-		return nil, fmt.Errorf("no posts found for %s", id)
+		return nil, fmt.Errorf("no posts found for %s: %w", id, ErrPostNotFound)
+	}
+	if err := r.attachReposts(posts, condition, args); err != nil {
+		return nil, err
 	}
 
 	postMap := make(map[uuid.UUID]*post.Post, len(posts))
@@ -205,7 +233,7 @@ func (r *PostRepository) GetPostByID(id uuid.UUID) (*post.Post, error) {
 
 	root, ok := postMap[id]
 	if !ok {
-		return nil, fmt.Errorf("post with id %s not found in postMap", id)
+		return nil, fmt.Errorf("post with id %s not found in postMap: %w", id, ErrPostNotFound)
 	}
 
 	buildTree(root)
@@ -227,7 +255,7 @@ func (r *PostRepository) GetPostByID(id uuid.UUID) (*post.Post, error) {
 	return root, nil
 }
 
-func (r *PostRepository) GetPostByPublicID(id int64) (*post.Post, error) {
+func (r *PostRepository) GetPostByPublicID(id int64, viewerID *uuid.UUID) (*post.Post, error) {
 	var p post.Post
 
 	err := r.db.
@@ -235,23 +263,81 @@ func (r *PostRepository) GetPostByPublicID(id int64) (*post.Post, error) {
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("post with id %d not found", id)
+			return nil, fmt.Errorf("post with id %d: %w", id, ErrPostNotFound)
 		}
 		return nil, err
 	}
 
-	return r.GetPostByID(p.ID)
+	return r.GetVisiblePostByID(p.ID, viewerID)
 }
 
-func (r *PostRepository) GetTimeline(limit int, cursor *int64) (types.TimelineResult, error) {
+// CanViewPost izleyicinin postu görüp göremeyeceğini döner
+func (r *PostRepository) CanViewPost(postID uuid.UUID, viewerID *uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&post.Post{}).
+		Where("posts.id = ?", postID).
+		Where(audienceCondition(viewerID)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *PostRepository) GetTimeline(limit int, cursor *int64, viewerID *uuid.UUID) (types.TimelineResult, error) {
 	var posts []post.Post
 
-	query := r.db.Model(&post.Post{}).
+	query := r.timelinePreloads(r.db.Model(&post.Post{}).
 		//Where("published = ?", true).
 		Where("contentable_type = ?", post.PostTypePost).
 		Where("parent_id IS NULL").
+		Where(audienceCondition(viewerID)).
 		Order("public_id DESC").
-		Limit(limit).
+		Limit(limit))
+
+	if cursor != nil {
+		query = query.Where("public_id < ?", *cursor)
+	}
+
+	if err := query.Find(&posts).Error; err != nil {
+		return types.TimelineResult{}, err
+	}
+	if err := r.attachVisibleReposts(posts, viewerID); err != nil {
+		return types.TimelineResult{}, err
+	}
+
+	var nextCursor *string
+	if len(posts) > 0 {
+		s := strconv.FormatInt(int64(posts[len(posts)-1].PublicID), 10)
+		nextCursor = &s
+	}
+
+	return types.TimelineResult{
+		Posts:      posts,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// GetTimelinePostsByIDs verilen postları timeline ilişkileriyle, en yeniden
+// eskiye sıralı yükler; alıntılanan orijinaller izleyicinin kitlesine göre eklenir
+func (r *PostRepository) GetTimelinePostsByIDs(ids []uuid.UUID, viewerID *uuid.UUID) ([]post.Post, error) {
+	var posts []post.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	err := r.timelinePreloads(r.db.Model(&post.Post{})).
+		Where("id IN ?", ids).
+		Order("public_id DESC").
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := r.attachVisibleReposts(posts, viewerID); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// timelinePreloads timeline kartında gösterilen ilişkileri yükler
+func (r *PostRepository) timelinePreloads(query *gorm.DB) *gorm.DB {
+	return query.
 		Preload("Location").
 		Preload("Poll").
 		Preload("Poll.Choices", func(db *gorm.DB) *gorm.DB {
@@ -276,28 +362,9 @@ func (r *PostRepository) GetTimeline(limit int, cursor *int64) (types.TimelineRe
 		Preload("Mentions").
 		Preload("Attachments").
 		Preload("Attachments.File")
-
-	if cursor != nil {
-		query = query.Where("public_id < ?", *cursor)
-	}
-
-	if err := query.Find(&posts).Error; err != nil {
-		return types.TimelineResult{}, err
-	}
-
-	var nextCursor *string
-	if len(posts) > 0 {
-		s := strconv.FormatInt(int64(posts[len(posts)-1].PublicID), 10)
-		nextCursor = &s
-	}
-
-	return types.TimelineResult{
-		Posts:      posts,
-		NextCursor: nextCursor,
-	}, nil
 }
 
-func (r *PostRepository) GetTimelineVibes(limit int, cursor *int64) (types.TimelineResult, error) {
+func (r *PostRepository) GetTimelineVibes(limit int, cursor *int64, viewerID *uuid.UUID) (types.TimelineResult, error) {
 	var posts []post.Post
 
 	query := r.db.Model(&post.Post{}).
@@ -314,6 +381,7 @@ func (r *PostRepository) GetTimelineVibes(limit int, cursor *int64) (types.Timel
 		Preload("Attachments").
 		Preload("Attachments.File").
 		Where("published = ?", true).
+		Where(audienceCondition(viewerID)).
 		Order("posts.public_id DESC").
 		Limit(limit).
 		Group("posts.id")
@@ -325,6 +393,9 @@ func (r *PostRepository) GetTimelineVibes(limit int, cursor *int64) (types.Timel
 	if err := query.Find(&posts).Error; err != nil {
 		return types.TimelineResult{}, err
 	}
+	if err := r.attachVisibleReposts(posts, viewerID); err != nil {
+		return types.TimelineResult{}, err
+	}
 
 	var nextCursor *string
 	if len(posts) > 0 {
@@ -338,7 +409,7 @@ func (r *PostRepository) GetTimelineVibes(limit int, cursor *int64) (types.Timel
 	}, nil
 }
 
-func (r *PostRepository) GetUserPosts(userId uuid.UUID, cursor *int64, limit int) ([]post.Post, error) {
+func (r *PostRepository) GetUserPosts(userId uuid.UUID, cursor *int64, limit int, viewerID *uuid.UUID) ([]post.Post, error) {
 	var posts []post.Post
 
 	query := r.db.
@@ -355,6 +426,7 @@ func (r *PostRepository) GetUserPosts(userId uuid.UUID, cursor *int64, limit int
 		Preload("Attachments").
 		Preload("Attachments.File").
 		Where("author_id = ? AND parent_id IS NULL and contentable_type = ?", userId, "post").
+		Where(audienceCondition(viewerID)).
 		Order("public_id DESC").
 		Limit(limit)
 
@@ -366,11 +438,14 @@ func (r *PostRepository) GetUserPosts(userId uuid.UUID, cursor *int64, limit int
 	if err := query.Find(&posts).Error; err != nil {
 		return nil, err
 	}
+	if err := r.attachVisibleReposts(posts, viewerID); err != nil {
+		return nil, err
+	}
 
 	return posts, nil
 }
 
-func (r *PostRepository) GetUserPostReplies(userID uuid.UUID, cursor *int64, limit int) ([]post.Post, error) {
+func (r *PostRepository) GetUserPostReplies(userID uuid.UUID, cursor *int64, limit int, viewerID *uuid.UUID) ([]post.Post, error) {
 	var posts []post.Post
 
 	query := r.db.
@@ -387,6 +462,9 @@ func (r *PostRepository) GetUserPostReplies(userID uuid.UUID, cursor *int64, lim
 		Preload("Attachments").
 		Preload("Attachments.File").
 		Where("author_id = ? AND parent_id IS NOT NULL and contentable_type = ? ", userID, "post").
+		Where(audienceCondition(viewerID)).
+		// Yanıtlanan post izleyiciye kapalıysa yanıt da gösterilmez
+		Where(visibleParentCondition(viewerID)).
 		Order("public_id DESC").
 		Limit(limit)
 
@@ -398,16 +476,20 @@ func (r *PostRepository) GetUserPostReplies(userID uuid.UUID, cursor *int64, lim
 	if err := query.Find(&posts).Error; err != nil {
 		return nil, err
 	}
+	if err := r.attachVisibleReposts(posts, viewerID); err != nil {
+		return nil, err
+	}
 
 	return posts, nil
 }
 
-func (r *PostRepository) GetUserMedias(userID uuid.UUID, cursor *int64, limit int) ([]types.MediaWithUser, *int64, error) {
+func (r *PostRepository) GetUserMedias(userID uuid.UUID, cursor *int64, limit int, viewerID *uuid.UUID) ([]types.MediaWithUser, *int64, error) {
 	var medias []media.Media
 
 	query := r.db.Unscoped().
 		Preload("File").
 		Where("user_id = ?", userID).
+		Where(visibleMediaCondition(viewerID)).
 		Order("public_id DESC").
 		Limit(limit)
 
@@ -454,21 +536,6 @@ func (r *PostRepository) GetUserMedias(userID uuid.UUID, cursor *int64, limit in
 	return results, lastCursor, nil
 }
 
-func (r *PostRepository) GetRecentHashtags(limit int) ([]types.HashtagStats, error) {
-	var results []types.HashtagStats
-	cutoff := time.Now().Add(-48 * time.Hour)
-
-	err := r.db.Model(&models.Hashtag{}). // gerçek tablo struct'ı buraya
-						Select("tag, COUNT(*) as count").
-						Where("created_at >= ?", cutoff).
-						Group("tag").
-						Order("count DESC").
-						Limit(limit).
-						Scan(&results).Error
-
-	return results, err
-}
-
 func (r *PostRepository) CreateContentablePost(request map[string][]string, files []*multipart.FileHeader, author *models.User, contentableType string, contentableID *uuid.UUID) (*post.Post, error) {
 	type PollForm struct {
 		ID            string   `form:"id"`
@@ -483,10 +550,13 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 
 	type PostForm struct {
 		ParentId string     `form:"parentPostId"`
+		QuoteId  string     `form:"quotePostId"`
 		Title    string     `form:"title"`
 		Summary  string     `form:"summary"`
 		Content  string     `form:"content"`
 		Audience string     `form:"audience"`
+		Status   string     `form:"status"`       // published, draft, scheduled
+		Schedule string     `form:"scheduled_at"` // RFC3339
 		Hashtags []string   `form:"hashtags[]"`
 		Mentions []string   `form:"mentions[]"`
 		Polls    []PollForm `form:"polls"`
@@ -503,9 +573,12 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 		EventIsOnline    string `form:"event[is_online]"`
 		EventIsOnlineURL string `form:"event[online_url]"`
 
-		LocationAddress string  `form:"location[address]"`
-		LocationLat     float64 `form:"location[lat]"`
-		LocationLng     float64 `form:"location[lng]"`
+		LocationAddress     string  `form:"location[address]"`
+		LocationLat         float64 `form:"location[lat]"`
+		LocationLng         float64 `form:"location[lng]"`
+		LocationCountryCode string  `form:"location[country_code]"`
+		LocationCountry     string  `form:"location[country]"`
+		LocationCity        string  `form:"location[city]"`
 	}
 
 	decoder := form.NewDecoder()
@@ -515,6 +588,25 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 		return nil, err
 	}
 
+	// Sohbet mesajlarının görünürlüğü sohbet üyeliğiyle belirlenir
+	audience := constants.PrivacyPublic
+	if contentableType == string(post.PostTypePost) {
+		parsed, err := ParseAudience(postForm.Audience, author)
+		if err != nil {
+			return nil, err
+		}
+		audience = parsed
+	}
+
+	// Taslak ve zamanlanmış gönderim yalnızca postlarda kullanılır
+	published, scheduledAt := true, (*time.Time)(nil)
+	if contentableType == string(post.PostTypePost) {
+		var err error
+		if published, scheduledAt, err = ParsePublishStatus(postForm.Status, postForm.Schedule, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
 	tx := r.DB().Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -536,17 +628,57 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 			return nil, fmt.Errorf("invalid parentId %s: %w", postForm.ParentId, err)
 		}
 		parentPost, err = r.FindPostByPublicID(parentIDInt)
+		if err == nil && parentPost.PostKind == post.PostTypeRepost {
+			// İçeriksiz repostlara verilen yanıtlar orijinal posta yazılır
+			parentPost, err = r.ResolveRepostTarget(parentIDInt)
+		}
 		if err == nil {
+			// Yazarın göremediği posta yanıt verilemez
+			visible, err := r.CanViewPost(parentPost.ID, &author.ID)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			if !visible {
+				tx.Rollback()
+				return nil, fmt.Errorf("parent post %d: %w", parentIDInt, ErrPostNotFound)
+			}
 			parentUUID = &parentPost.ID
 		}
 	}
 
+	// Alıntılanan post yazarın görebildiği bir post olmalıdır
+	var quotedPost *post.Post
+	if len(postForm.QuoteId) > 0 && contentableType == string(post.PostTypePost) {
+		quoteIDInt, err := strconv.ParseInt(postForm.QuoteId, 10, 64)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("invalid quotePostId %s: %w", postForm.QuoteId, err)
+		}
+		quotedPost, err = r.ResolveRepostTarget(quoteIDInt)
+		if err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("quoted post %d: %w", quoteIDInt, ErrPostNotFound)
+		}
+		visible, err := r.CanViewPost(quotedPost.ID, &author.ID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if !visible {
+			tx.Rollback()
+			return nil, fmt.Errorf("quoted post %d: %w", quoteIDInt, ErrPostNotFound)
+		}
+	}
+
 	defaultLanguage := author.DefaultLanguage
 
 	var postKindType post.PostType
-	switch contentableType {
-	case "chat":
+	switch {
+	case contentableType == "chat":
 		postKindType = post.PostTypeChat
+	case quotedPost != nil:
+		postKindType = post.PostTypeQuote
 	default:
 		postKindType = post.PostTypeStatus
 	}
@@ -556,9 +688,11 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 		ParentID:        parentUUID,
 		PublicID:        node.Generate().Int64(),
 		AuthorID:        author.ID,
-		Published:       true,
+		Published:       published,
+		ScheduledAt:     scheduledAt,
 		PostKind:        postKindType,
 		ContentCategory: post.ContentNormal,
+		Audience:        audience,
 		Title:           utils.MakeLocalizedString(defaultLanguage, postForm.Title),
 		Content:         utils.MakeLocalizedString(defaultLanguage, postForm.Content),
 		Summary:         utils.MakeLocalizedString(defaultLanguage, postForm.Summary),
@@ -567,6 +701,13 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 		ContentableType: &contentableType,
 		ContentableID:   contentableID,
 	}
+	if quotedPost != nil {
+		newPost.RepostOfID = &quotedPost.ID
+	}
+	if published {
+		now := time.Now()
+		newPost.PublishedAt = &now
+	}
 
 	if err := tx.Create(newPost).Error; err != nil {
 		tx.Rollback()
@@ -581,8 +722,9 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 
 		switch contentableType {
 		case "chat":
-			ownerType = media.OwnerChat
-			role = media.RoleChatMedia // burada istersen MIME type’a göre video da yapabiliriz
+			// Mesaj ekleri de Post.Attachments ile yüklenebilmesi için post'a aittir
+			ownerType = media.OwnerPost
+			role = chatMediaRole(DetectMimeType(f))
 		default:
 			ownerType = media.OwnerPost
 			role = media.RolePost
@@ -660,6 +802,9 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 			ContentableType: utils.LocationOwnerPost,
 			ContentableID:   newPost.ID,
 			Address:         &postForm.LocationAddress,
+			CountryCode:     &postForm.LocationCountryCode,
+			Country:         &postForm.LocationCountry,
+			City:            &postForm.LocationCity,
 			Latitude:        &postForm.LocationLat,
 			Longitude:       &postForm.LocationLng,
 			LocationPoint:   locationPoint,
@@ -729,10 +874,25 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 		newPost.Event = evt
 	}
 
+	// Postlarda hashtag ve mention'lar içerikten de çıkarılır; sohbet
+	// mesajlarındaki etiketler trendlere karışmasın diye yalnızca formdakiler alınır
+	mentionNames, hashtagNames := postForm.Mentions, postForm.Hashtags
+	if contentableType == string(post.PostTypePost) {
+		mentionNames = helpers.ExtractMentions(postForm.Content, postForm.Mentions)
+		hashtagNames = helpers.ExtractHashtags(postForm.Content, postForm.Hashtags)
+	}
+	// Yayınlanmayan postlar yayın anında indekslenir; formdaki listeler saklanır
+	if !published {
+		setPendingTags(newPost, postForm.Hashtags, postForm.Mentions)
+		mentionNames, hashtagNames = nil, nil
+	}
+
 	// Mentions
-	for _, mentionText := range postForm.Mentions {
+	mentioned := map[uuid.UUID]bool{}
+	for _, mentionText := range mentionNames {
 		mentionUser, err := r.userRepo.GetUserByNameOrEmailOrNickname(mentionText)
-		if err == nil {
+		if err == nil && !mentioned[mentionUser.ID] {
+			mentioned[mentionUser.ID] = true
 			mentionItem := models.Mention{
 				ID:     uuid.New(),
 				UserID: mentionUser.ID,
@@ -742,7 +902,7 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 	}
 
 	// Hashtags
-	for _, hashtagStr := range postForm.Hashtags {
+	for _, hashtagStr := range hashtagNames {
 		hashtagItem := models.Hashtag{
 			ID:  uuid.New(),
 			Tag: hashtagStr,
@@ -760,9 +920,8 @@ func (r *PostRepository) CreateContentablePost(request map[string][]string, file
 		return nil, err
 	}
 
-	if parentPost != nil {
-		err = r.userRepo.engagementRepo.AddEngagement(context.Background(), author.ID, parentPost.AuthorID, models.EngagementKindComment, parentPost.ID, models.EngagementContentableTypePost)
-		if err != nil {
+	if published {
+		if err := r.addPublishEngagements(newPost); err != nil {
 			return nil, err
 		}
 	}
@@ -857,6 +1016,18 @@ func (r *PostRepository) FindPostByPublicID(id int64) (*post.Post, error) {
 	return &p, nil
 }
 
+// FindPostByID postu ilişkileri olmadan bulur
+func (r *PostRepository) FindPostByID(id uuid.UUID) (*post.Post, error) {
+	var p post.Post
+	if err := r.db.First(&p, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("post with id %s: %w", id, ErrPostNotFound)
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
 func (r *PostRepository) Like(ctx context.Context, postId int64, authUser *models.User) error {
 	post, err := r.FindPostByPublicID(postId)
 	if err != nil {
@@ -920,3 +1091,51 @@ func (r *PostRepository) Bookmark(ctx context.Context, postId int64, authUser *m
 func (r *PostRepository) View(ctx context.Context, postId int64, authUser *models.User) error {
 	return nil
 }
+
+// chatMediaRole sohbet ekinin rolünü içerikten tespit edilen MIME tipine göre belirler
+func chatMediaRole(mimeType string) media.MediaRole {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return media.RoleChatImage
+	case strings.HasPrefix(mimeType, "video/"):
+		return media.RoleChatVideo
+	case strings.HasPrefix(mimeType, "audio/"):
+		return media.RoleChatAudio
+	default:
+		return media.RoleChatMedia
+	}
+}
+
+// SetPostExtra Extras içindeki tek bir anahtarı diğer anahtarlara dokunmadan yazar
+func (r *PostRepository) SetPostExtra(postID uuid.UUID, key string, value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return r.db.Exec(`UPDATE posts SET extras = jsonb_set(COALESCE(extras, '{}'::jsonb), ?::text[], ?::jsonb) WHERE id = ?`,
+		"{"+key+"}", string(b), postID).Error
+}
+
+// SetLinkPreview önizlemeyi sadece post silinmemişse ve içeriği önizlemenin
+// hesaplandığı içerikle aynıysa yazar. Arada silinen (tombstone) veya
+// düzenlenen postlara eski önizleme yazılmaz; yazıldıysa true döner.
+func (r *PostRepository) SetLinkPreview(p *post.Post, preview any) (bool, error) {
+	b, err := json.Marshal(preview)
+	if err != nil {
+		return false, err
+	}
+	content, err := json.Marshal(p.Content)
+	if err != nil {
+		return false, err
+	}
+	result := r.db.Exec(`
+		UPDATE posts SET extras = jsonb_set(COALESCE(extras, '{}'::jsonb), ?::text[], ?::jsonb)
+		WHERE id = ? AND deleted_at IS NULL AND content = ?::jsonb`,
+		"{"+post.ExtraLinkPreview+"}", string(b), p.ID, string(content))
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeletePostExtra Extras içindeki tek bir anahtarı siler
+func (r *PostRepository) DeletePostExtra(postID uuid.UUID, key string) error {
+	return r.db.Exec(`UPDATE posts SET extras = extras - ? WHERE id = ? AND extras IS NOT NULL`, key, postID).Error
+}
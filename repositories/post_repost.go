@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"coolvibes/constants"
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrRepostNotAllowed = errors.New("only public posts can be reposted")
+
+// ResolveRepostTarget paylaşılacak orijinal postu bulur; içeriksiz bir
+// repost paylaşılmak istenirse onun orijinali döner
+func (r *PostRepository) ResolveRepostTarget(publicID int64) (*post.Post, error) {
+	target, err := r.FindPostByPublicID(publicID)
+	if err != nil {
+		return nil, ErrPostNotFound
+	}
+	if target.PostKind == post.PostTypeRepost && target.RepostOfID != nil {
+		if target, err = r.FindPostByID(*target.RepostOfID); err != nil {
+			return nil, ErrPostNotFound
+		}
+	}
+	if target.ContentableType == nil || *target.ContentableType != string(post.PostTypePost) {
+		return nil, ErrPostNotFound
+	}
+	return target, nil
+}
+
+// FindRepost kullanıcının orijinal post için yaptığı içeriksiz repostu bulur
+func (r *PostRepository) FindRepost(originalID uuid.UUID, authorID uuid.UUID) (*post.Post, error) {
+	var p post.Post
+	err := r.db.
+		Where("repost_of_id = ? AND author_id = ? AND post_kind = ?", originalID, authorID, post.PostTypeRepost).
+		First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreateRepost orijinal postu kullanıcının akışında içeriksiz paylaşır ve
+// orijinalin repost sayacını artırır. Kitlesi dışına taşmaması için yalnızca
+// herkese açık postlar paylaşılabilir.
+func (r *PostRepository) CreateRepost(original *post.Post, author *models.User) (*post.Post, error) {
+	if original.Audience != constants.PrivacyPublic {
+		return nil, ErrRepostNotAllowed
+	}
+
+	contentableType := string(post.PostTypePost)
+	now := time.Now()
+	repost := &post.Post{
+		ID:              uuid.New(),
+		PublicID:        r.snowFlakeNode.Generate().Int64(),
+		AuthorID:        author.ID,
+		Published:       true,
+		PublishedAt:     &now,
+		PostKind:        post.PostTypeRepost,
+		ContentCategory: post.ContentNormal,
+		Audience:        constants.PrivacyPublic,
+		RepostOfID:      &original.ID,
+		ContentableType: &contentableType,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := r.db.Create(repost).Error; err != nil {
+		return nil, err
+	}
+
+	detailID, err := r.userRepo.engagementRepo.AddEngagement(context.Background(), author.ID, original.AuthorID, models.EngagementKindRepost, original.ID, models.EngagementContentableTypePost)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.storeEngagementDetail(repost, post.ExtraRepostEngagementID, detailID); err != nil {
+		return nil, err
+	}
+	return repost, nil
+}
+
+// attachReposts repost ve alıntıların orijinal postlarını timeline
+// ilişkileriyle yükler. condition boş değilse orijinal de koşula uymalıdır;
+// silinmiş ya da koşula uymayan orijinaller RepostUnavailable olarak işaretlenir.
+func (r *PostRepository) attachReposts(posts []post.Post, condition string, args map[string]interface{}) error {
+	var ids []uuid.UUID
+	for _, p := range posts {
+		if p.RepostOfID != nil {
+			ids = append(ids, *p.RepostOfID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := r.timelinePreloads(r.db.Model(&post.Post{})).Where("posts.id IN ?", ids)
+	if condition != "" {
+		query = query.Where(condition, args)
+	}
+	var originals []post.Post
+	if err := query.Find(&originals).Error; err != nil {
+		return err
+	}
+
+	byID := make(map[uuid.UUID]*post.Post, len(originals))
+	for i := range originals {
+		byID[originals[i].ID] = &originals[i]
+	}
+	for i := range posts {
+		if posts[i].RepostOfID == nil {
+			continue
+		}
+		if original, ok := byID[*posts[i].RepostOfID]; ok {
+			posts[i].RepostOf = original
+		} else {
+			posts[i].RepostUnavailable = true
+		}
+	}
+	return nil
+}
+
+// attachVisibleReposts orijinalleri izleyicinin kitlesine göre ekler
+func (r *PostRepository) attachVisibleReposts(posts []post.Post, viewerID *uuid.UUID) error {
+	condition, args := audienceCondition(viewerID)
+	return r.attachReposts(posts, condition, args)
+}
+
+// removeRepostEngagements silinen repost ve alıntıların, silinmeyen
+// orijinallerindeki sayaçları düşürür
+func (r *PostRepository) removeRepostEngagements(deleted []uuid.UUID) error {
+	var posts []post.Post
+	err := r.db.Unscoped().
+		Select("id", "post_kind", "repost_of_id", "extras").
+		Where("id IN ? AND repost_of_id IS NOT NULL AND repost_of_id NOT IN ?", deleted, deleted).
+		Find(&posts).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		if err := r.removeEngagementDetail(&posts[i], post.ExtraRepostEngagementID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
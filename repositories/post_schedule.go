@@ -0,0 +1,317 @@
+package repositories
+
+import (
+	"context"
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/models/media"
+	"coolvibes/models/post"
+	"coolvibes/models/utils"
+	"coolvibes/types"
+	"errors"
+	"mime/multipart"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Postun yayın durumu formdaki status alanıyla seçilir
+const (
+	PostStatusPublished = "published"
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+)
+
+var (
+	ErrInvalidSchedule      = errors.New("invalid post status or schedule")
+	ErrPostAlreadyPublished = errors.New("post is already published")
+)
+
+// ParsePublishStatus formdaki status ve scheduled_at alanlarını doğrular. Boş
+// status hemen yayınlamadır; zamanlanmış postun zamanı gelecekte olmalıdır.
+func ParsePublishStatus(status string, scheduledAt string, now time.Time) (bool, *time.Time, error) {
+	switch status {
+	case "", PostStatusPublished:
+		return true, nil, nil
+	case PostStatusDraft:
+		return false, nil, nil
+	case PostStatusScheduled:
+		at, err := time.Parse(time.RFC3339, scheduledAt)
+		if err != nil || !at.After(now) {
+			return false, nil, ErrInvalidSchedule
+		}
+		return false, &at, nil
+	}
+	return false, nil, ErrInvalidSchedule
+}
+
+// setPendingTags taslağın formdan gelen hashtag ve mention listelerini saklar
+func setPendingTags(p *post.Post, hashtags []string, mentions []string) {
+	if len(hashtags) > 0 {
+		p.SetExtra(post.ExtraPendingHashtags, hashtags)
+	}
+	if len(mentions) > 0 {
+		p.SetExtra(post.ExtraPendingMentions, mentions)
+	}
+}
+
+// addPublishEngagements yayınlanan yanıtın üst postuna yorum, alıntının
+// orijinaline alıntı etkileşimi ekler
+func (r *PostRepository) addPublishEngagements(p *post.Post) error {
+	targets := []struct {
+		id   *uuid.UUID
+		kind models.EngagementKind
+		key  string
+	}{
+		{p.ParentID, models.EngagementKindComment, post.ExtraParentEngagementID},
+		{p.RepostOfID, models.EngagementKindQuote, post.ExtraRepostEngagementID},
+	}
+	for _, target := range targets {
+		if target.id == nil {
+			continue
+		}
+		// Hedef post bu arada silinmiş olabilir
+		original, err := r.FindPostByID(*target.id)
+		if errors.Is(err, ErrPostNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		detailID, err := r.userRepo.engagementRepo.AddEngagement(context.Background(), p.AuthorID, original.AuthorID, target.kind, original.ID, models.EngagementContentableTypePost)
+		if err != nil {
+			return err
+		}
+		if err := r.storeEngagementDetail(p, target.key, detailID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishPost taslak ya da zamanlanmış postu yayınlar. Akışlar PublicID ile
+// sıralandığından posta yayın anında yeni bir PublicID verilir. Hashtag ve
+// mention'lar bu anda kaydedilir; mention edilen kullanıcıların ID'leri döner.
+// Post başka bir işlem tarafından yayınlandıysa ErrPostAlreadyPublished döner.
+func (r *PostRepository) PublishPost(p *post.Post) ([]uuid.UUID, error) {
+	text := localizedText(p.Content)
+	tags := helpers.ExtractHashtags(text, p.GetExtraStrings(post.ExtraPendingHashtags))
+
+	var mentionIDs []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for _, name := range helpers.ExtractMentions(text, p.GetExtraStrings(post.ExtraPendingMentions)) {
+		mentionUser, err := r.userRepo.GetUserByNameOrEmailOrNickname(name)
+		if err != nil || seen[mentionUser.ID] {
+			continue
+		}
+		seen[mentionUser.ID] = true
+		mentionIDs = append(mentionIDs, mentionUser.ID)
+	}
+
+	now := time.Now()
+	publicID := r.snowFlakeNode.Generate().Int64()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`UPDATE posts SET published = TRUE, published_at = @now, scheduled_at = NULL,
+			public_id = @public_id, created_at = @now, updated_at = @now,
+			extras = CASE WHEN extras IS NULL THEN NULL ELSE extras - CAST(@pending AS text[]) END
+			WHERE id = @id AND published = FALSE AND deleted_at IS NULL`, map[string]interface{}{
+			"now":       now,
+			"public_id": publicID,
+			"pending":   "{" + post.ExtraPendingHashtags + "," + post.ExtraPendingMentions + "}",
+			"id":        p.ID,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPostAlreadyPublished
+		}
+
+		for _, tag := range tags {
+			hashtag := models.Hashtag{ID: uuid.New(), TaggableID: p.ID, TaggableType: "post", Tag: tag, CreatedAt: now}
+			if err := tx.Create(&hashtag).Error; err != nil {
+				return err
+			}
+		}
+		for _, id := range mentionIDs {
+			mention := models.Mention{ID: uuid.New(), MentionableID: p.ID, MentionableType: "post", UserID: id, CreatedAt: now}
+			if err := tx.Create(&mention).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.Published, p.PublishedAt, p.ScheduledAt = true, &now, nil
+	p.PublicID, p.CreatedAt, p.UpdatedAt = publicID, now, now
+	if p.Extras != nil {
+		delete(*p.Extras, post.ExtraPendingHashtags)
+		delete(*p.Extras, post.ExtraPendingMentions)
+	}
+
+	if err := r.addPublishEngagements(p); err != nil {
+		return mentionIDs, err
+	}
+	return mentionIDs, nil
+}
+
+// GetDueScheduledPosts yayın zamanı gelen zamanlanmış postları yazarlarıyla döndürür
+func (r *PostRepository) GetDueScheduledPosts(limit int) ([]post.Post, error) {
+	var posts []post.Post
+	err := r.db.
+		Preload("Author").
+		Where("published = ? AND scheduled_at IS NOT NULL AND scheduled_at <= ?", false, time.Now()).
+		Order("scheduled_at ASC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+// GetDrafts kullanıcının zamanlanmamış taslakları, yeniden eskiye
+func (r *PostRepository) GetDrafts(authorID uuid.UUID, limit int, cursor *int64) (types.TimelineResult, error) {
+	var posts []post.Post
+	query := r.timelinePreloads(r.db.Model(&post.Post{})).
+		Where("author_id = ? AND published = ? AND scheduled_at IS NULL", authorID, false).
+		Order("public_id DESC").
+		Limit(limit)
+	if cursor != nil {
+		query = query.Where("public_id < ?", *cursor)
+	}
+	if err := query.Find(&posts).Error; err != nil {
+		return types.TimelineResult{}, err
+	}
+	if err := r.attachVisibleReposts(posts, &authorID); err != nil {
+		return types.TimelineResult{}, err
+	}
+
+	var nextCursor *string
+	if len(posts) > 0 {
+		s := strconv.FormatInt(posts[len(posts)-1].PublicID, 10)
+		nextCursor = &s
+	}
+	return types.TimelineResult{Posts: posts, NextCursor: nextCursor}, nil
+}
+
+// GetScheduledPosts kullanıcının zamanlanmış postları, yayın sırasına göre
+func (r *PostRepository) GetScheduledPosts(authorID uuid.UUID) ([]post.Post, error) {
+	var posts []post.Post
+	err := r.timelinePreloads(r.db.Model(&post.Post{})).
+		Where("author_id = ? AND published = ? AND scheduled_at IS NOT NULL", authorID, false).
+		Order("scheduled_at ASC").
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := r.attachVisibleReposts(posts, &authorID); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// CountScheduledPosts kullanıcının bekleyen zamanlanmış post sayısı
+func (r *PostRepository) CountScheduledPosts(authorID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&post.Post{}).
+		Where("author_id = ? AND published = ? AND scheduled_at IS NOT NULL", authorID, false).
+		Count(&count).Error
+	return count, err
+}
+
+// SetSchedule yayınlanmamış postun zamanını değiştirir; nil taslağa çevirir
+func (r *PostRepository) SetSchedule(p *post.Post, scheduledAt *time.Time) error {
+	result := r.db.Model(&post.Post{}).
+		Where("id = ? AND published = ?", p.ID, false).
+		Update("scheduled_at", scheduledAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPostAlreadyPublished
+	}
+	p.ScheduledAt = scheduledAt
+	return nil
+}
+
+// UpdateDraft yayınlanmamış postun başlık, içerik, özet, kitle ve eklerini
+// düzenler. Revizyon tutulmaz; çıkarılan ekler silinir. Formda gönderilen
+// hashtag ve mention listeleri yayın anında kullanılmak üzere saklanır.
+func (r *PostRepository) UpdateDraft(p *post.Post, request map[string][]string, files []*multipart.FileHeader, editor *models.User) error {
+	updates := map[string]interface{}{}
+	language := editor.DefaultLanguage
+	if values, ok := request["title"]; ok {
+		p.Title = utils.MakeLocalizedString(language, firstValue(values))
+		updates["title"] = p.Title
+	}
+	if values, ok := request["content"]; ok {
+		p.Content = utils.MakeLocalizedString(language, firstValue(values))
+		updates["content"] = p.Content
+	}
+	if values, ok := request["summary"]; ok {
+		p.Summary = utils.MakeLocalizedString(language, firstValue(values))
+		updates["summary"] = p.Summary
+	}
+	if values, ok := request["audience"]; ok {
+		audience, err := ParseAudience(firstValue(values), editor)
+		if err != nil {
+			return err
+		}
+		p.Audience = audience
+		updates["audience"] = audience
+	}
+	hashtags, tagsSent := request["hashtags[]"]
+	mentions, mentionsSent := request["mentions[]"]
+	if tagsSent || mentionsSent {
+		if !tagsSent {
+			hashtags = p.GetExtraStrings(post.ExtraPendingHashtags)
+		}
+		if !mentionsSent {
+			mentions = p.GetExtraStrings(post.ExtraPendingMentions)
+		}
+		if p.Extras != nil {
+			delete(*p.Extras, post.ExtraPendingHashtags)
+			delete(*p.Extras, post.ExtraPendingMentions)
+		}
+		setPendingTags(p, hashtags, mentions)
+		updates["extras"] = p.Extras
+	}
+
+	removed := map[int64]bool{}
+	for _, raw := range request["remove_attachments[]"] {
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			removed[id] = true
+		}
+	}
+	var attachments []*media.Media
+	if err := r.db.Where("owner_id = ? AND owner_type = ?", p.ID, media.OwnerPost).Find(&attachments).Error; err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		if removed[a.PublicID] {
+			if err := r.mediaRepo.DeleteMedia(a); err != nil {
+				return err
+			}
+		}
+	}
+	for _, f := range files {
+		if _, err := r.mediaRepo.AddMedia(p.ID, media.OwnerPost, editor.ID, media.RolePost, f); err != nil {
+			return err
+		}
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+	return r.db.Model(&post.Post{}).Where("id = ? AND published = ?", p.ID, false).Updates(updates).Error
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/types"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// GetPostAncestors postun üst postlarını kökten başlayarak döndürür. Zincirdeki
+// postlardan biri izleyiciye kapalıysa post da gösterilmez ve ErrPostNotFound döner.
+func (r *PostRepository) GetPostAncestors(p *post.Post, viewerID *uuid.UUID) ([]post.Post, error) {
+	ancestors := []post.Post{}
+	if p.ParentID == nil {
+		return ancestors, nil
+	}
+
+	var ids []uuid.UUID
+	cte := `
+		WITH RECURSIVE ancestors AS (
+			SELECT posts.id, posts.parent_id FROM posts
+			WHERE posts.id = ? AND posts.deleted_at IS NULL
+			UNION ALL
+			SELECT posts.id, posts.parent_id FROM posts
+			INNER JOIN ancestors a ON a.parent_id = posts.id
+			WHERE posts.deleted_at IS NULL
+		)
+		SELECT id FROM ancestors`
+	if err := r.db.Raw(cte, *p.ParentID).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+
+	posts, err := r.GetTimelinePostsByIDs(ids, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := r.filterVisible(posts, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 || len(visible) < len(ids) {
+		return nil, fmt.Errorf("ancestors of %s: %w", p.ID, ErrPostNotFound)
+	}
+
+	// Kök post en eskisidir; zincir PublicID sırasıyla kökten aşağı iner
+	for i := len(visible) - 1; i >= 0; i-- {
+		ancestors = append(ancestors, visible[i])
+	}
+	return ancestors, nil
+}
+
+// filterVisible postlardan izleyicinin görebildiklerini ayırır
+func (r *PostRepository) filterVisible(posts []post.Post, viewerID *uuid.UUID) ([]post.Post, error) {
+	if len(posts) == 0 {
+		return posts, nil
+	}
+	ids := make([]uuid.UUID, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+
+	var visibleIDs []uuid.UUID
+	if err := r.db.Model(&post.Post{}).
+		Where("posts.id IN ?", ids).
+		Where(audienceCondition(viewerID)).
+		Pluck("posts.id", &visibleIDs).Error; err != nil {
+		return nil, err
+	}
+	allowed := make(map[uuid.UUID]bool, len(visibleIDs))
+	for _, id := range visibleIDs {
+		allowed[id] = true
+	}
+
+	visible := make([]post.Post, 0, len(posts))
+	for _, p := range posts {
+		if allowed[p.ID] {
+			visible = append(visible, p)
+		}
+	}
+	return visible, nil
+}
+
+// GetReplies verilen üst postların izleyiciye açık yanıtlarından her dal için
+// en fazla PerParent tanesini sıralı döndürür. Etkileşim skoru "Senin İçin"
+// adaylarıyla aynı Engagement.Counts ağırlıklarıyla hesaplanır.
+func (r *PostRepository) GetReplies(query types.ReplyQuery, viewerID *uuid.UUID) ([]types.ThreadReply, error) {
+	replies := []types.ThreadReply{}
+	if len(query.ParentIDs) == 0 {
+		return replies, nil
+	}
+
+	score := "COALESCE(" + engagementScoreSQL() + ", 0)"
+	audience, args := audienceCondition(viewerID)
+	args["parents"] = query.ParentIDs
+	args["per_parent"] = query.PerParent
+	args["post_type"] = models.EngagementContentableTypePost
+
+	order, after := "public_id DESC", "public_id < @public_id"
+	switch query.Sort {
+	case types.ReplySortTop:
+		order = "score DESC, public_id DESC"
+		after = "(score < @score OR (score = @score AND public_id < @public_id))"
+	case types.ReplySortOldest:
+		order = "public_id ASC"
+		after = "public_id > @public_id"
+	}
+	if query.After != nil {
+		args["score"] = query.After.Score
+		args["public_id"] = query.After.PublicID
+	} else {
+		after = "TRUE"
+	}
+
+	raw := `
+		SELECT id, parent_id, public_id, score FROM (
+			SELECT scored.*, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY ` + order + `) AS position
+			FROM (
+				SELECT posts.id, posts.parent_id, posts.public_id, ` + score + ` AS score
+				FROM posts
+				LEFT JOIN engagements e ON e.contentable_id = posts.id AND e.contentable_type = @post_type
+				WHERE posts.parent_id IN @parents AND posts.deleted_at IS NULL AND ` + audience + `
+			) scored
+			WHERE ` + after + `
+		) ranked
+		WHERE position <= @per_parent
+		ORDER BY parent_id, position`
+
+	if err := r.db.Raw(raw, args).Scan(&replies).Error; err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
@@ -2,10 +2,16 @@ package handlers
 
 import (
 	"coolvibes/middleware"
+	"coolvibes/models"
 	services "coolvibes/services/user"
+	"coolvibes/types"
 	"coolvibes/utils"
+	"errors"
+	"log"
 	"mime/multipart"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -46,7 +52,17 @@ func HandleSendTypingEvent(s *services.ChatService) http.HandlerFunc {
 			return
 		}
 
-		err = s.SendTypingEvent(chatId, auth_user.ID, true)
+		// typing=false yazmayı durdurur; gönderilmezse başlatma kabul edilir
+		typing := true
+		if typingStr := r.FormValue("typing"); typingStr != "" {
+			typing, err = strconv.ParseBool(typingStr)
+			if err != nil {
+				http.Error(w, "Invalid typing value", http.StatusBadRequest)
+				return
+			}
+		}
+
+		err = s.SendTypingEvent(chatId, auth_user.ID, typing)
 		if err != nil {
 			http.Error(w, "Failed to send typng event users", http.StatusInternalServerError)
 			return
@@ -80,7 +96,7 @@ func HandleSendMessage(s *services.ChatService) http.HandlerFunc {
 
 		_post, err := s.AddMessageToChat(formParams, files, user)
 		if err != nil {
-			http.Error(w, "Send message failed", http.StatusInternalServerError)
+			writeChatError(w, err, "Send message failed")
 			return
 		}
 		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
@@ -142,7 +158,8 @@ func HandleGetChatsByUserID(s *services.ChatService) http.HandlerFunc {
 			return
 		}
 
-		chats, err := s.GetChatsByUserID(auth_user.ID)
+		archived := r.FormValue("archived") == "true"
+		chats, err := s.GetChatsByUserID(auth_user.ID, archived)
 		if err != nil {
 			http.Error(w, "Failed to fetch GetChatsByUserID", http.StatusInternalServerError)
 			return
@@ -154,6 +171,19 @@ func HandleGetChatsByUserID(s *services.ChatService) http.HandlerFunc {
 	}
 }
 
+// parsePublicIDParam opsiyonel bir PublicID form değerini okur; boşsa nil döner
+func parsePublicIDParam(r *http.Request, key string) (*int64, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
 func HandleGetMessagesByChatID(s *services.ChatService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		auth_user, ok := middleware.GetAuthenticatedUser(r)
@@ -180,14 +210,976 @@ func HandleGetMessagesByChatID(s *services.ChatService) http.HandlerFunc {
 			return
 		}
 
-		messages, err := s.GetMessagesByChatID(auth_user.ID, chatId)
+		page := types.MessagePage{}
+		if limitStr := r.FormValue("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+				page.Limit = l
+			}
+		}
+
+		// before / after / around: mesajın PublicID'si
+		if page.Before, err = parsePublicIDParam(r, "before"); err != nil {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		if page.After, err = parsePublicIDParam(r, "after"); err != nil {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		if page.Around, err = parsePublicIDParam(r, "around"); err != nil {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+
+		result, err := s.GetMessagesByChatID(auth_user.ID, chatId, page)
+		if errors.Is(err, services.ErrNotChatParticipant) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		if err != nil {
 			http.Error(w, "Failed to load messages", http.StatusInternalServerError)
 			return
 		}
 		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
-			"success":  true,
-			"messages": messages,
+			"success":     true,
+			"messages":    result.Messages,
+			"next_cursor": result.NextCursor,
+			"prev_cursor": result.PrevCursor,
+		})
+	}
+}
+
+func HandleMarkRead(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, err := uuid.Parse(r.FormValue("chat_id"))
+		if err != nil {
+			http.Error(w, "Invalid chat", http.StatusBadRequest)
+			return
+		}
+
+		// message_id (public_id) verilmezse sohbetteki tüm mesajlar okunur
+		upTo, err := services.ParseUpToPublicID(r.FormValue("message_id"))
+		if err != nil {
+			http.Error(w, "Invalid message id", http.StatusBadRequest)
+			return
+		}
+
+		unreadCount, err := s.MarkRead(auth_user.ID, chatId, upTo)
+		if err != nil {
+			http.Error(w, "Failed to mark messages as read", http.StatusInternalServerError)
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":      true,
+			"unread_count": unreadCount,
+		})
+	}
+}
+
+// writeChatError servis hatalarını uygun HTTP durum koduna çevirir
+func writeChatError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrNotChatParticipant), errors.Is(err, services.ErrNotPermitted),
+		errors.Is(err, services.ErrUserBlocked):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrNotGroupChat), errors.Is(err, services.ErrParticipantMissing),
+		errors.Is(err, services.ErrMessageNotEditable), errors.Is(err, services.ErrDeleteWindowExpired),
+		errors.Is(err, services.ErrInvalidDisappearAfter), errors.Is(err, services.ErrNotViewOnce),
+		errors.Is(err, services.ErrVoiceMessageTooLong), errors.Is(err, services.ErrVoiceMessageUnreadable),
+		errors.Is(err, services.ErrInvalidCoordinates),
+		errors.Is(err, services.ErrInvalidLiveLocationLimit), errors.Is(err, services.ErrLiveLocationEnded),
+		errors.Is(err, services.ErrInvalidMuteUntil), errors.Is(err, services.ErrTooManyPinnedChats):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrViewOnceOpened):
+		http.Error(w, err.Error(), http.StatusGone)
+	case errors.Is(err, services.ErrMessageNotFound), errors.Is(err, services.ErrLiveLocationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		// İç hata ayrıntısı istemciye gönderilmez, yalnızca loglanır
+		log.Printf("%s: %v", fallback, err)
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
+
+// parseChatAndUser grup işlemleri için chat_id ve user_id form değerlerini okur
+func parseChatAndUser(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	chatId, err := uuid.Parse(r.FormValue("chat_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid chat id")
+	}
+	userId, err := uuid.Parse(r.FormValue("user_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid user id")
+	}
+	return chatId, userId, nil
+}
+
+func HandleCreateGroup(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		err := r.ParseMultipartForm(5 * 1024 * 1024 * 1024)
+		if err != nil {
+			http.Error(w, "Could not parse multipart form: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		participantIds := []uuid.UUID{}
+		for _, idStr := range r.MultipartForm.Value["participant_ids[]"] {
+			id, err := uuid.Parse(idStr)
+			if err != nil {
+				http.Error(w, "Invalid participant id", http.StatusBadRequest)
+				return
+			}
+			participantIds = append(participantIds, id)
+		}
+
+		var avatar *multipart.FileHeader
+		if avatars := r.MultipartForm.File["avatar"]; len(avatars) > 0 {
+			avatar = avatars[0]
+		}
+
+		group, err := s.CreateGroup(auth_user, participantIds, r.FormValue("title"), r.FormValue("description"), avatar)
+		if err != nil {
+			writeChatError(w, err, "Failed to create group")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"chat":    group,
+		})
+	}
+}
+
+func HandleGroupInvite(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, err := uuid.Parse(r.FormValue("chat_id"))
+		if err != nil {
+			http.Error(w, "Invalid chat id", http.StatusBadRequest)
+			return
+		}
+
+		userIds := []uuid.UUID{}
+		for _, idStr := range r.Form["user_ids[]"] {
+			id, err := uuid.Parse(idStr)
+			if err != nil {
+				http.Error(w, "Invalid user id", http.StatusBadRequest)
+				return
+			}
+			userIds = append(userIds, id)
+		}
+		if len(userIds) == 0 {
+			http.Error(w, "Invalid participants length", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.InviteToGroup(auth_user, chatId, userIds); err != nil {
+			writeChatError(w, err, "Failed to invite users")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandleGroupRemove(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, userId, err := parseChatAndUser(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := s.RemoveFromGroup(auth_user, chatId, userId); err != nil {
+			writeChatError(w, err, "Failed to remove user")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandleGroupPromote(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, userId, err := parseChatAndUser(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := s.PromoteAdmin(auth_user, chatId, userId); err != nil {
+			writeChatError(w, err, "Failed to promote user")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandleGroupLeave(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, err := uuid.Parse(r.FormValue("chat_id"))
+		if err != nil {
+			http.Error(w, "Invalid chat id", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.LeaveGroup(auth_user, chatId); err != nil {
+			writeChatError(w, err, "Failed to leave group")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandleGroupTransferOwnership(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, userId, err := parseChatAndUser(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := s.TransferOwnership(auth_user, chatId, userId); err != nil {
+			writeChatError(w, err, "Failed to transfer ownership")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandlePinMessage(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, err := uuid.Parse(r.FormValue("chat_id"))
+		if err != nil {
+			http.Error(w, "Invalid chat id", http.StatusBadRequest)
+			return
+		}
+
+		// message_id (public_id) boş gönderilirse sabit mesaj kaldırılır
+		messageId, err := parsePublicIDParam(r, "message_id")
+		if err != nil {
+			http.Error(w, "Invalid message id", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.PinMessage(auth_user, chatId, messageId); err != nil {
+			writeChatError(w, err, "Failed to pin message")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+// parseChatAndMessage chat_id ve message_id (public_id) form değerlerini okur
+func parseChatAndMessage(r *http.Request) (uuid.UUID, int64, error) {
+	chatId, err := uuid.Parse(r.FormValue("chat_id"))
+	if err != nil {
+		return uuid.Nil, 0, errors.New("invalid chat id")
+	}
+	messageId, err := strconv.ParseInt(r.FormValue("message_id"), 10, 64)
+	if err != nil {
+		return uuid.Nil, 0, errors.New("invalid message id")
+	}
+	return chatId, messageId, nil
+}
+
+func HandleEditMessage(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, messageId, err := parseChatAndMessage(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		message, err := s.EditMessage(auth_user, chatId, messageId, r.FormValue("content"))
+		if err != nil {
+			writeChatError(w, err, "Failed to edit message")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": message,
 		})
 	}
 }
+
+func HandleMessageEdits(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, messageId, err := parseChatAndMessage(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		revisions, err := s.GetMessageRevisions(auth_user.ID, chatId, messageId)
+		if err != nil {
+			writeChatError(w, err, "Failed to fetch message edits")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"revisions": revisions,
+		})
+	}
+}
+
+func HandleDeleteMessage(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, messageId, err := parseChatAndMessage(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// scope: "me" (varsayılan) veya "everyone"
+		scope := r.FormValue("scope")
+		if scope == "" {
+			scope = "me"
+		}
+		if scope != "me" && scope != "everyone" {
+			http.Error(w, "Invalid scope", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.DeleteMessage(auth_user, chatId, messageId, scope == "everyone"); err != nil {
+			writeChatError(w, err, "Failed to delete message")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandleDeleteChat(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, err := uuid.Parse(r.FormValue("chat_id"))
+		if err != nil {
+			http.Error(w, "Invalid chat id", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.HideChat(auth_user, chatId); err != nil {
+			writeChatError(w, err, "Failed to delete chat")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandleReactMessage(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, messageId, err := parseChatAndMessage(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		reactions, added, err := s.ReactToMessage(auth_user, chatId, messageId, r.FormValue("emoji"))
+		if err != nil {
+			writeChatError(w, err, "Failed to react to message")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"added":     added,
+			"reactions": reactions,
+		})
+	}
+}
+
+func HandleForwardMessage(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, messageId, err := parseChatAndMessage(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		targetIds := []uuid.UUID{}
+		for _, idStr := range r.Form["target_chat_ids[]"] {
+			id, err := uuid.Parse(idStr)
+			if err != nil {
+				http.Error(w, "Invalid target chat id", http.StatusBadRequest)
+				return
+			}
+			targetIds = append(targetIds, id)
+		}
+		if len(targetIds) == 0 {
+			http.Error(w, "Invalid target chats length", http.StatusBadRequest)
+			return
+		}
+
+		messages, err := s.ForwardMessage(auth_user, chatId, messageId, targetIds)
+		if err != nil {
+			writeChatError(w, err, "Failed to forward message")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":  true,
+			"messages": messages,
+		})
+	}
+}
+
+func HandleGetChatRequests(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		chats, err := s.GetChatRequests(auth_user.ID)
+		if err != nil {
+			http.Error(w, "Failed to fetch message requests", http.StatusInternalServerError)
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"chats":   chats,
+		})
+	}
+}
+
+// handleChatRequestAction istek kabul / silme / engelleme handler'larının ortak gövdesi
+func handleChatRequestAction(action func(r *http.Request, authUser *models.User, chatId uuid.UUID) error, fallback string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, err := uuid.Parse(r.FormValue("chat_id"))
+		if err != nil {
+			http.Error(w, "Invalid chat id", http.StatusBadRequest)
+			return
+		}
+
+		if err := action(r, auth_user, chatId); err != nil {
+			writeChatError(w, err, fallback)
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandleAcceptChatRequest(s *services.ChatService) http.HandlerFunc {
+	return handleChatRequestAction(func(r *http.Request, authUser *models.User, chatId uuid.UUID) error {
+		return s.AcceptChatRequest(authUser, chatId)
+	}, "Failed to accept message request")
+}
+
+func HandleDeleteChatRequest(s *services.ChatService) http.HandlerFunc {
+	return handleChatRequestAction(func(r *http.Request, authUser *models.User, chatId uuid.UUID) error {
+		return s.DeleteChatRequest(authUser, chatId)
+	}, "Failed to delete message request")
+}
+
+func HandleBlockChatRequest(s *services.ChatService) http.HandlerFunc {
+	return handleChatRequestAction(func(r *http.Request, authUser *models.User, chatId uuid.UUID) error {
+		return s.BlockChatRequest(r.Context(), authUser, chatId)
+	}, "Failed to block message request sender")
+}
+
+// parseTimeParam RFC3339 tarih parametresini okur
+func parseTimeParam(r *http.Request, key string) (*time.Time, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func HandleSearchMessages(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		search := types.MessageSearch{
+			Query:          r.FormValue("query"),
+			AttachmentType: r.FormValue("attachment_type"),
+		}
+
+		if chatIdStr := r.FormValue("chat_id"); chatIdStr != "" {
+			chatId, err := uuid.Parse(chatIdStr)
+			if err != nil {
+				http.Error(w, "Invalid chat id", http.StatusBadRequest)
+				return
+			}
+			search.ChatID = &chatId
+		}
+		if senderIdStr := r.FormValue("sender_id"); senderIdStr != "" {
+			senderId, err := uuid.Parse(senderIdStr)
+			if err != nil {
+				http.Error(w, "Invalid sender id", http.StatusBadRequest)
+				return
+			}
+			search.SenderID = &senderId
+		}
+
+		var err error
+		if search.From, err = parseTimeParam(r, "from"); err != nil {
+			http.Error(w, "Invalid from date", http.StatusBadRequest)
+			return
+		}
+		if search.To, err = parseTimeParam(r, "to"); err != nil {
+			http.Error(w, "Invalid to date", http.StatusBadRequest)
+			return
+		}
+		if search.Before, err = parsePublicIDParam(r, "before"); err != nil {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		if limitStr := r.FormValue("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+				search.Limit = l
+			}
+		}
+
+		switch search.AttachmentType {
+		case "", "image", "video", "audio", "file":
+		default:
+			http.Error(w, "Invalid attachment type", http.StatusBadRequest)
+			return
+		}
+
+		result, err := s.SearchMessages(auth_user.ID, search)
+		if errors.Is(err, services.ErrEmptySearchQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			writeChatError(w, err, "Failed to search messages")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":     true,
+			"hits":        result.Hits,
+			"next_cursor": result.NextCursor,
+		})
+	}
+}
+
+func HandleSetDisappearing(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, err := uuid.Parse(r.FormValue("chat_id"))
+		if err != nil {
+			http.Error(w, "Invalid chat id", http.StatusBadRequest)
+			return
+		}
+
+		// seconds=0 kaybolan mesajları kapatır
+		seconds, err := strconv.ParseInt(r.FormValue("seconds"), 10, 64)
+		if err != nil || seconds < 0 {
+			http.Error(w, "Invalid duration", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.SetDisappearingMessages(auth_user, chatId, time.Duration(seconds)*time.Second); err != nil {
+			writeChatError(w, err, "Failed to update disappearing messages")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandleOpenViewOnce(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, messageId, err := parseChatAndMessage(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		message, err := s.OpenViewOnce(auth_user, chatId, messageId)
+		if err != nil {
+			writeChatError(w, err, "Failed to open view-once media")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": message,
+		})
+	}
+}
+
+// parseFloatParam boş bırakılabilen sayısal form değerini okur
+func parseFloatParam(r *http.Request, key string) (*float64, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func HandleStartLiveLocation(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, err := uuid.Parse(r.FormValue("chat_id"))
+		if err != nil {
+			http.Error(w, "Invalid chat id", http.StatusBadRequest)
+			return
+		}
+
+		// duration saniye cinsinden
+		duration, err := strconv.Atoi(r.FormValue("duration"))
+		if err != nil {
+			http.Error(w, "Invalid duration", http.StatusBadRequest)
+			return
+		}
+
+		lat, latErr := strconv.ParseFloat(r.FormValue("lat"), 64)
+		lng, lngErr := strconv.ParseFloat(r.FormValue("lng"), 64)
+		if latErr != nil || lngErr != nil {
+			http.Error(w, "Invalid coordinates", http.StatusBadRequest)
+			return
+		}
+		position := services.LiveLocationPosition{Latitude: lat, Longitude: lng}
+		if position.Accuracy, err = parseFloatParam(r, "accuracy"); err != nil {
+			http.Error(w, "Invalid accuracy", http.StatusBadRequest)
+			return
+		}
+		if position.Heading, err = parseFloatParam(r, "heading"); err != nil {
+			http.Error(w, "Invalid heading", http.StatusBadRequest)
+			return
+		}
+
+		message, err := s.StartLiveLocation(auth_user, chatId, time.Duration(duration)*time.Second, position)
+		if err != nil {
+			writeChatError(w, err, "Failed to start live location")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": message,
+		})
+	}
+}
+
+func HandleStopLiveLocation(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		sessionId, err := uuid.Parse(r.FormValue("session_id"))
+		if err != nil {
+			http.Error(w, "Invalid session id", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.StopLiveLocation(auth_user, sessionId); err != nil {
+			writeChatError(w, err, "Failed to stop live location")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+func HandleFetchLiveLocations(s *services.ChatService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		chatId, err := uuid.Parse(r.FormValue("chat_id"))
+		if err != nil {
+			http.Error(w, "Invalid chat id", http.StatusBadRequest)
+			return
+		}
+
+		sessions, err := s.GetLiveLocations(auth_user.ID, chatId)
+		if err != nil {
+			writeChatError(w, err, "Failed to fetch live locations")
+			return
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":        true,
+			"live_locations": sessions,
+		})
+	}
+}
+
+func HandleMuteChat(s *services.ChatService) http.HandlerFunc {
+	return handleChatRequestAction(func(r *http.Request, authUser *models.User, chatId uuid.UUID) error {
+		// until (RFC3339) ya da duration (saniye); ikisi de yoksa süresiz
+		until, err := parseTimeParam(r, "until")
+		if err != nil {
+			return services.ErrInvalidMuteUntil
+		}
+		if until == nil && r.FormValue("duration") != "" {
+			seconds, err := strconv.Atoi(r.FormValue("duration"))
+			if err != nil || seconds <= 0 {
+				return services.ErrInvalidMuteUntil
+			}
+			t := time.Now().Add(time.Duration(seconds) * time.Second)
+			until = &t
+		}
+		return s.MuteChat(authUser, chatId, until)
+	}, "Failed to mute chat")
+}
+
+func HandleUnmuteChat(s *services.ChatService) http.HandlerFunc {
+	return handleChatRequestAction(func(r *http.Request, authUser *models.User, chatId uuid.UUID) error {
+		return s.UnmuteChat(authUser, chatId)
+	}, "Failed to unmute chat")
+}
+
+func HandleArchiveChat(s *services.ChatService) http.HandlerFunc {
+	return handleChatRequestAction(func(r *http.Request, authUser *models.User, chatId uuid.UUID) error {
+		return s.ArchiveChat(authUser, chatId)
+	}, "Failed to archive chat")
+}
+
+func HandleUnarchiveChat(s *services.ChatService) http.HandlerFunc {
+	return handleChatRequestAction(func(r *http.Request, authUser *models.User, chatId uuid.UUID) error {
+		return s.UnarchiveChat(authUser, chatId)
+	}, "Failed to unarchive chat")
+}
+
+func HandlePinChat(s *services.ChatService) http.HandlerFunc {
+	return handleChatRequestAction(func(r *http.Request, authUser *models.User, chatId uuid.UUID) error {
+		return s.PinChat(authUser, chatId)
+	}, "Failed to pin chat")
+}
+
+func HandleUnpinChat(s *services.ChatService) http.HandlerFunc {
+	return handleChatRequestAction(func(r *http.Request, authUser *models.User, chatId uuid.UUID) error {
+		return s.UnpinChat(authUser, chatId)
+	}, "Failed to unpin chat")
+}
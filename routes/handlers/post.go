@@ -6,6 +6,7 @@ import (
 	services "coolvibes/services/user"
 	"coolvibes/utils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"mime/multipart"
@@ -45,6 +46,22 @@ func HandleCreate(s *services.PostService) http.HandlerFunc {
 			return
 		}
 		post, err := s.CreatePost(formParams, files, user)
+		if errors.Is(err, services.ErrInvalidAudience) {
+			http.Error(w, "invalid audience", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, services.ErrInvalidSchedule) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, services.ErrTooManyScheduledPosts) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, services.ErrPostNotFound) {
+			http.Error(w, "parent or quoted post not found", http.StatusNotFound)
+			return
+		}
 		if err != nil {
 			http.Error(w, "Failed to create post: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -302,6 +319,283 @@ func HandlePostView(s *services.PostService) http.HandlerFunc {
 	}
 }
 
+// optionalViewerID giriş yapmış izleyicinin ID'si; anonim izleyici için nil
+func optionalViewerID(r *http.Request) *uuid.UUID {
+	if user, ok := middleware.GetAuthenticatedUser(r); ok && user != nil {
+		return &user.ID
+	}
+	return nil
+}
+
+func HandleUpdate(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseMultipartForm(5 * 1024 * 1024 * 1024)
+		if err != nil {
+			http.Error(w, "Could not parse multipart form: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		postID, err := strconv.ParseInt(r.FormValue("post_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid post", http.StatusBadRequest)
+			return
+		}
+
+		formParams := r.MultipartForm.Value        // text fields
+		images := r.MultipartForm.File["images[]"] // images array
+		videos := r.MultipartForm.File["videos[]"] // videos array
+
+		files := append([]*multipart.FileHeader{}, images...)
+		files = append(files, videos...)
+
+		post, err := s.UpdatePost(user, postID, formParams, files)
+		switch {
+		case errors.Is(err, services.ErrPostNotFound):
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		case errors.Is(err, services.ErrNotPostAuthor), errors.Is(err, services.ErrEditWindowClosed):
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		case errors.Is(err, services.ErrInvalidAudience), errors.Is(err, services.ErrInvalidSchedule):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, services.ErrTooManyScheduledPosts), errors.Is(err, services.ErrPostAlreadyPublished):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			http.Error(w, "Failed to update post: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(post)
+	}
+}
+
+func HandleDelete(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		postID, err := strconv.ParseInt(r.FormValue("post_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid post", http.StatusBadRequest)
+			return
+		}
+
+		err = s.DeletePost(user, postID)
+		switch {
+		case errors.Is(err, services.ErrPostNotFound):
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		case errors.Is(err, services.ErrDeleteForbidden):
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		case err != nil:
+			http.Error(w, "Failed to delete post: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"post_id": strconv.FormatInt(postID, 10),
+		})
+	}
+}
+
+func HandleRepost(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		postID, err := strconv.ParseInt(r.FormValue("post_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid post", http.StatusBadRequest)
+			return
+		}
+
+		repost, err := s.Repost(user, postID)
+		switch {
+		case errors.Is(err, services.ErrPostNotFound):
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		case errors.Is(err, services.ErrRepostNotAllowed):
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		case err != nil:
+			http.Error(w, "Failed to repost: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Repost geri alındıysa post dönmez
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":  true,
+			"reposted": repost != nil,
+			"post":     repost,
+		})
+	}
+}
+
+func HandlePostThread(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		postID, err := strconv.ParseInt(r.FormValue("post_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid post", http.StatusBadRequest)
+			return
+		}
+
+		sort, ok := services.ParseReplySort(r.FormValue("sort"))
+		if !ok {
+			http.Error(w, "invalid sort", http.StatusBadRequest)
+			return
+		}
+		depth, _ := strconv.Atoi(r.FormValue("depth"))
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+
+		thread, err := s.GetThread(postID, optionalViewerID(r), sort, depth, limit, r.FormValue("cursor"))
+		switch {
+		case errors.Is(err, services.ErrPostNotFound):
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		case errors.Is(err, services.ErrInvalidThreadCursor):
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, "failed to get thread: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(thread)
+	}
+}
+
+func HandleDrafts(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		limit := 10 // default
+		if l, err := strconv.Atoi(r.FormValue("limit")); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+
+		var cursor *int64
+		if cursorStr := r.FormValue("cursor"); cursorStr != "" {
+			c, err := strconv.ParseInt(cursorStr, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid cursor", http.StatusBadRequest)
+				return
+			}
+			cursor = &c
+		}
+
+		result, err := s.GetDrafts(user, limit, cursor)
+		if err != nil {
+			http.Error(w, "failed to get drafts: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func HandleScheduledPosts(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		posts, err := s.GetScheduledPosts(user)
+		if err != nil {
+			http.Error(w, "failed to get scheduled posts: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"posts":   posts,
+		})
+	}
+}
+
+func HandleCancelScheduledPost(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		postID, err := strconv.ParseInt(r.FormValue("post_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid post", http.StatusBadRequest)
+			return
+		}
+
+		draft, err := s.CancelScheduledPost(user, postID)
+		switch {
+		case errors.Is(err, services.ErrPostNotFound):
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		case errors.Is(err, services.ErrPostNotScheduled), errors.Is(err, services.ErrPostAlreadyPublished):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			http.Error(w, "failed to cancel scheduled post: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"post":    draft,
+		})
+	}
+}
+
+func HandlePostRevisions(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		postID, err := strconv.ParseInt(r.FormValue("post_id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid post", http.StatusBadRequest)
+			return
+		}
+
+		revisions, err := s.GetPostRevisions(postID, optionalViewerID(r))
+		if errors.Is(err, services.ErrPostNotFound) {
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to get revisions: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"revisions": revisions,
+		})
+	}
+}
+
 func HandleGetByID(s *services.PostService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		idStr := r.URL.Query().Get("id")
@@ -321,7 +615,11 @@ func HandleGetByID(s *services.PostService) http.HandlerFunc {
 			postId = val
 		}
 
-		post, err := s.GetPostByPublicID(postId)
+		post, err := s.GetPostByPublicID(postId, optionalViewerID(r))
+		if errors.Is(err, services.ErrPostNotFound) {
+			http.Error(w, "post not found", http.StatusNotFound)
+			return
+		}
 		if err != nil {
 			http.Error(w, "failed to get post: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -340,15 +638,12 @@ func HandleGetByPublicID(s *services.PostService) http.HandlerFunc {
 			return
 		}
 
-		id, err := uuid.Parse(idStr)
-		if err != nil {
+		if _, err := uuid.Parse(idStr); err != nil {
 			http.Error(w, "invalid uuid", http.StatusBadRequest)
 			return
 		}
 
-		fmt.Println("%d", id)
-
-		post, err := s.GetPostByPublicID(12)
+		post, err := s.GetPostByPublicID(12, optionalViewerID(r))
 		if err != nil {
 			http.Error(w, "failed to get post: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -383,7 +678,7 @@ func HandleTimeline(s *services.PostService) http.HandlerFunc {
 		}
 
 		// Timeline verisini çek
-		result, err := s.GetTimeline(limit, cursor)
+		result, err := s.GetTimeline(limit, cursor, optionalViewerID(r))
 		if err != nil {
 			http.Error(w, "failed to get timeline: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -395,6 +690,69 @@ func HandleTimeline(s *services.PostService) http.HandlerFunc {
 	}
 }
 
+func HandleFollowingTimeline(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		limit := 10 // default
+		if l, err := strconv.Atoi(r.FormValue("limit")); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+
+		// Cursor parametresi (PublicID)
+		var cursor *int64
+		if cursorStr := r.FormValue("cursor"); cursorStr != "" {
+			c, err := strconv.ParseInt(cursorStr, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid cursor", http.StatusBadRequest)
+				return
+			}
+			cursor = &c
+		}
+
+		result, err := s.GetFollowingTimeline(auth_user.ID, limit, cursor)
+		if err != nil {
+			http.Error(w, "failed to get timeline: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func HandleForYouTimeline(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		limit := 10 // default
+		if l, err := strconv.Atoi(r.FormValue("limit")); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+
+		result, err := s.GetForYouTimeline(auth_user, limit, r.FormValue("cursor"))
+		if errors.Is(err, services.ErrInvalidFeedCursor) {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to get timeline: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
 func HandleTimelineVibes(s *services.PostService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Form verilerini parse et
@@ -429,7 +787,7 @@ func HandleTimelineVibes(s *services.PostService) http.HandlerFunc {
 		limit = 10
 
 		// Timeline verisini çek
-		result, err := s.GetTimelineVibes(limit, cursor)
+		result, err := s.GetTimelineVibes(limit, cursor, optionalViewerID(r))
 		if err != nil {
 			http.Error(w, "failed to get timeline: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -473,7 +831,7 @@ func HandleGetPostsByUser(s *services.PostService) http.HandlerFunc {
 			cursor = val
 		}
 
-		post, err := s.GetPostsByUserID(userId, limit, &cursor)
+		post, err := s.GetPostsByUserID(userId, limit, &cursor, optionalViewerID(r))
 		if err != nil {
 			http.Error(w, "failed to get post: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -517,7 +875,7 @@ func HandleGetRepliesByUser(s *services.PostService) http.HandlerFunc {
 			cursor = val
 		}
 
-		post, err := s.GetUserPostReplies(userId, limit, &cursor)
+		post, err := s.GetUserPostReplies(userId, limit, &cursor, optionalViewerID(r))
 		if err != nil {
 			http.Error(w, "failed to get post: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -562,7 +920,7 @@ func HandleGetAllMediasByUser(s *services.PostService) http.HandlerFunc {
 			cursor = val
 		}
 
-		medias, nextCursor, err := s.GetUserMedias(userId, limit, &cursor)
+		medias, nextCursor, err := s.GetUserMedias(userId, limit, &cursor, optionalViewerID(r))
 		if err != nil {
 			http.Error(w, "failed to get post: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -599,7 +957,7 @@ func HandleGetAllLikesByUser(s *services.PostService) http.HandlerFunc {
 			return
 		}
 
-		post, err := s.GetPostByID(id)
+		post, err := s.GetPostByID(id, optionalViewerID(r))
 		if err != nil {
 			http.Error(w, "failed to get post: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -626,7 +984,12 @@ func HandleGetTrends(s *services.PostService) http.HandlerFunc {
 			}
 		}
 
-		hashtags, err := s.GetRecentHashtags(limit)
+		// scope: global, country (country=TR) ya da city (country=TR&city=istanbul)
+		hashtags, err := s.GetTrends(r.FormValue("scope"), r.FormValue("country"), r.FormValue("city"), r.FormValue("window"), limit)
+		if errors.Is(err, services.ErrInvalidTrendScope) || errors.Is(err, services.ErrInvalidTrendWindow) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			http.Error(w, "failed to get trends: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -639,3 +1002,109 @@ func HandleGetTrends(s *services.PostService) http.HandlerFunc {
 		})
 	}
 }
+
+func HandleHashtagPosts(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 10 // default
+		if l, err := strconv.Atoi(r.FormValue("limit")); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+
+		// Cursor parametresi (PublicID)
+		var cursor *int64
+		if cursorStr := r.FormValue("cursor"); cursorStr != "" {
+			c, err := strconv.ParseInt(cursorStr, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid cursor", http.StatusBadRequest)
+				return
+			}
+			cursor = &c
+		}
+
+		page, err := s.GetHashtagPage(r.FormValue("tag"), limit, cursor, optionalViewerID(r))
+		switch {
+		case errors.Is(err, services.ErrInvalidHashtag):
+			http.Error(w, "invalid hashtag", http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, "failed to get hashtag posts: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+func HandleHashtagFollow(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		tag, err := s.FollowHashtag(user, r.FormValue("tag"))
+		switch {
+		case errors.Is(err, services.ErrInvalidHashtag):
+			http.Error(w, "invalid hashtag", http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, "Failed to follow hashtag: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"tag":       tag,
+			"following": true,
+		})
+	}
+}
+
+func HandleHashtagUnfollow(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		tag, err := s.UnfollowHashtag(user, r.FormValue("tag"))
+		switch {
+		case errors.Is(err, services.ErrInvalidHashtag):
+			http.Error(w, "invalid hashtag", http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, "Failed to unfollow hashtag: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"tag":       tag,
+			"following": false,
+		})
+	}
+}
+
+func HandleFollowedHashtags(s *services.PostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			http.Error(w, "User not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		hashtags, err := s.GetFollowedHashtags(user)
+		if err != nil {
+			http.Error(w, "Failed to get followed hashtags: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"success":  true,
+			"hashtags": hashtags,
+		})
+	}
+}
@@ -943,3 +943,40 @@ func HandleUserNotifications(s *services.UserService) http.HandlerFunc {
 		})
 	}
 }
+
+func HandleUserToggleMute(s *services.UserService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		auth_user, ok := middleware.GetAuthenticatedUser(r)
+		if !ok {
+			utils.SendError(w, http.StatusUnauthorized, constants.ErrUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form data", http.StatusBadRequest)
+			return
+		}
+
+		mutedId, err := strconv.ParseInt(r.FormValue("muted_id"), 10, 64)
+		if err != nil || mutedId == 0 || mutedId == auth_user.PublicID {
+			utils.SendError(w, http.StatusBadRequest, constants.ErrInvalidInput)
+			return
+		}
+
+		muted, err := s.ToggleMute(r.Context(), auth_user.PublicID, mutedId)
+		if err != nil {
+			utils.SendError(w, http.StatusBadRequest, constants.ErrDatabaseError)
+			return
+		}
+
+		message := "User unmuted successfully"
+		if muted {
+			message = "User muted successfully"
+		}
+		utils.SendJSON(w, http.StatusOK, map[string]interface{}{
+			"message": message,
+			"muted":   muted,
+		})
+	}
+}
@@ -8,10 +8,13 @@ import (
 	"coolvibes/repositories"
 	"coolvibes/router"
 	"coolvibes/routes/handlers"
+	"coolvibes/services/ranking"
 	"coolvibes/services/socket"
+	"coolvibes/services/unfurl"
 	services "coolvibes/services/user"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 
@@ -49,14 +52,22 @@ func NewRouter(db *gorm.DB, snowFlakeNode *helpers.Node) *Router {
 	postRepo := repositories.NewPostRepository(r.db, snowFlakeNode, mediaRepo, userRepo)
 	matchesRepo := repositories.NewMatchesRepository(r.db, engagementRepo)
 	notificationRepo := repositories.NewNotificationRepository(r.db, snowFlakeNode)
+	notificationRepo.SetEventPublisher(socketService)
 	notificationService := services.NewNotificationsService(notificationRepo)
 
 	chatRepo := repositories.NewChatRepository(r.db, snowFlakeNode, postRepo, notificationRepo)
 
 	userService := services.NewUserService(userRepo, postRepo, mediaRepo, engagementRepo, notificationRepo)
-	postService := services.NewPostService(userRepo, postRepo, mediaRepo)
+	linkUnfurler := unfurl.New(unfurl.DefaultConfig())
+	feedRepo := repositories.NewFeedRepository(r.db, postRepo)
+	rankerWeights, err := ranking.WeightsFromEnv()
+	if err != nil {
+		log.Printf("Invalid %s, using default feed weights: %v", ranking.WeightsEnv, err)
+	}
+	postService := services.NewPostService(userRepo, postRepo, mediaRepo, feedRepo, notificationRepo, ranking.NewLinearRanker(rankerWeights), linkUnfurler)
+	userService.OnFollowChange(postService.RefreshFeed)
 	matchesService := services.NewMatchService(userRepo, postRepo, mediaRepo, matchesRepo)
-	chatService := services.NewChatService(socketService, userRepo, postRepo, mediaRepo, matchesRepo, chatRepo, notificationRepo)
+	chatService := services.NewChatService(socketService, userRepo, postRepo, mediaRepo, matchesRepo, chatRepo, notificationRepo, userService, linkUnfurler)
 
 	r.action.Register(constants.CMD_INITIAL_SYNC, handlers.HandleInitialSync(r.db))         // middleware yok
 	r.action.Register(constants.CMD_GET_VAPID_PUBLIC_KEY, handlers.HandleVapidGetKey(r.db)) // middleware yok vapid
@@ -74,6 +85,30 @@ func NewRouter(db *gorm.DB, snowFlakeNode *helpers.Node) *Router {
 	r.action.Register(constants.CMD_SEARCH_LOOKUP_USER, handlers.HandleGetUsersStartingWith(userService))
 	r.action.Register(constants.CMD_SEARCH_TRENDS, handlers.HandleGetTrends(postService))
 
+	r.action.Register(
+		constants.CMD_HASHTAG_FETCH_POSTS,
+		handlers.HandleHashtagPosts(postService),        // handler
+		middleware.AuthMiddlewareWithoutCheck(userRepo), // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_HASHTAG_FOLLOW,
+		handlers.HandleHashtagFollow(postService), // handler
+		middleware.AuthMiddleware(userRepo),       // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_HASHTAG_UNFOLLOW,
+		handlers.HandleHashtagUnfollow(postService), // handler
+		middleware.AuthMiddleware(userRepo),         // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_HASHTAG_FOLLOWED,
+		handlers.HandleFollowedHashtags(postService), // handler
+		middleware.AuthMiddleware(userRepo),          // middleware
+	)
+
 	r.action.Register( // access token'a gore user bilgisi
 		constants.CMD_AUTH_USER_INFO,
 		handlers.HandleUserInfo(userService),
@@ -218,6 +253,11 @@ func NewRouter(db *gorm.DB, snowFlakeNode *helpers.Node) *Router {
 		handlers.HandleUserToggleBlock(userService), // handler
 		middleware.AuthMiddleware(userRepo),         // middleware
 	)
+	r.action.Register(
+		constants.CMD_USER_TOGGLE_MUTE,
+		handlers.HandleUserToggleMute(userService), // handler
+		middleware.AuthMiddleware(userRepo),        // middleware
+	)
 
 	// POST
 	//	r.action.Register(constants.CMD_POST_CREATE, middleware.AuthMiddleware(userRepo) handlers.HandleCreate(postService))
@@ -227,6 +267,54 @@ func NewRouter(db *gorm.DB, snowFlakeNode *helpers.Node) *Router {
 		middleware.AuthMiddleware(userRepo), // middleware
 	)
 
+	r.action.Register(
+		constants.CMD_POST_UPDATE,
+		handlers.HandleUpdate(postService),  // handler
+		middleware.AuthMiddleware(userRepo), // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_POST_DELETE,
+		handlers.HandleDelete(postService),  // handler
+		middleware.AuthMiddleware(userRepo), // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_POST_REPOST,
+		handlers.HandleRepost(postService),  // handler
+		middleware.AuthMiddleware(userRepo), // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_POST_THREAD,
+		handlers.HandlePostThread(postService),          // handler
+		middleware.AuthMiddlewareWithoutCheck(userRepo), // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_POST_DRAFTS,
+		handlers.HandleDrafts(postService),  // handler
+		middleware.AuthMiddleware(userRepo), // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_POST_SCHEDULED,
+		handlers.HandleScheduledPosts(postService), // handler
+		middleware.AuthMiddleware(userRepo),        // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_POST_SCHEDULE_CANCEL,
+		handlers.HandleCancelScheduledPost(postService), // handler
+		middleware.AuthMiddleware(userRepo),             // middleware
+	)
+
+	r.action.Register(
+		constants.CMD_POST_REVISIONS,
+		handlers.HandlePostRevisions(postService),       // handler
+		middleware.AuthMiddlewareWithoutCheck(userRepo), // middleware
+	)
+
 	r.action.Register(
 		constants.CMD_POST_VOTE,
 		handlers.HandleVote(postService),    // handler
@@ -239,9 +327,31 @@ func NewRouter(db *gorm.DB, snowFlakeNode *helpers.Node) *Router {
 	r.action.Register(constants.CMD_POST_BOOKMARK, handlers.HandlePostBookmark(postService), middleware.AuthMiddleware(userRepo))
 	r.action.Register(constants.CMD_POST_REPORT, handlers.HandlePostReport(postService), middleware.AuthMiddleware(userRepo))
 	r.action.Register(constants.CMD_POST_VIEW, handlers.HandlePostView(postService), middleware.AuthMiddleware(userRepo))
-	r.action.Register(constants.CMD_POST_FETCH, handlers.HandleGetByID(postService))
-	r.action.Register(constants.CMD_POST_TIMELINE, handlers.HandleTimeline(postService))
-	r.action.Register(constants.CMD_POST_VIBES, handlers.HandleTimelineVibes(postService))
+	r.action.Register(
+		constants.CMD_POST_FETCH,
+		handlers.HandleGetByID(postService),             // handler
+		middleware.AuthMiddlewareWithoutCheck(userRepo), // middleware
+	)
+	r.action.Register(
+		constants.CMD_POST_TIMELINE,
+		handlers.HandleTimeline(postService),            // handler
+		middleware.AuthMiddlewareWithoutCheck(userRepo), // middleware
+	)
+	r.action.Register(
+		constants.CMD_POST_VIBES,
+		handlers.HandleTimelineVibes(postService),       // handler
+		middleware.AuthMiddlewareWithoutCheck(userRepo), // middleware
+	)
+	r.action.Register(
+		constants.CMD_POST_TIMELINE_FOLLOWING,
+		handlers.HandleFollowingTimeline(postService), // handler
+		middleware.AuthMiddleware(userRepo),           // middleware
+	)
+	r.action.Register(
+		constants.CMD_POST_TIMELINE_FOR_YOU,
+		handlers.HandleForYouTimeline(postService), // handler
+		middleware.AuthMiddleware(userRepo),        // middleware
+	)
 
 	r.action.Register(constants.CMD_USER_FETCH_STORIES, handlers.HandleFetchStories(userService))
 	r.action.Register(constants.CMD_USER_FETCH_NEARBY_USERS, handlers.HandleFetchNearbyUsers(userService), middleware.AuthMiddlewareWithoutCheck(userRepo))
@@ -306,6 +416,156 @@ func NewRouter(db *gorm.DB, snowFlakeNode *helpers.Node) *Router {
 		handlers.HandleGetMessagesByChatID(chatService), // handler
 		middleware.AuthMiddleware(userRepo),             // middleware
 	)
+	r.action.Register(
+		constants.CMD_MARK_READ,
+		handlers.HandleMarkRead(chatService), // handler
+		middleware.AuthMiddleware(userRepo),  // middleware
+	)
+	r.action.Register(
+		constants.CMD_PIN_MESSAGE,
+		handlers.HandlePinMessage(chatService), // handler
+		middleware.AuthMiddleware(userRepo),    // middleware
+	)
+	r.action.Register(
+		constants.CMD_GROUP_CREATE,
+		handlers.HandleCreateGroup(chatService), // handler
+		middleware.AuthMiddleware(userRepo),     // middleware
+	)
+	r.action.Register(
+		constants.CMD_GROUP_INVITE,
+		handlers.HandleGroupInvite(chatService), // handler
+		middleware.AuthMiddleware(userRepo),     // middleware
+	)
+	r.action.Register(
+		constants.CMD_GROUP_REMOVE,
+		handlers.HandleGroupRemove(chatService), // handler
+		middleware.AuthMiddleware(userRepo),     // middleware
+	)
+	r.action.Register(
+		constants.CMD_GROUP_PROMOTE,
+		handlers.HandleGroupPromote(chatService), // handler
+		middleware.AuthMiddleware(userRepo),      // middleware
+	)
+	r.action.Register(
+		constants.CMD_GROUP_LEAVE,
+		handlers.HandleGroupLeave(chatService), // handler
+		middleware.AuthMiddleware(userRepo),    // middleware
+	)
+	r.action.Register(
+		constants.CMD_GROUP_TRANSFER,
+		handlers.HandleGroupTransferOwnership(chatService), // handler
+		middleware.AuthMiddleware(userRepo),                // middleware
+	)
+	r.action.Register(
+		constants.CMD_EDIT_MESSAGE,
+		handlers.HandleEditMessage(chatService), // handler
+		middleware.AuthMiddleware(userRepo),     // middleware
+	)
+	r.action.Register(
+		constants.CMD_MESSAGE_EDITS,
+		handlers.HandleMessageEdits(chatService), // handler
+		middleware.AuthMiddleware(userRepo),      // middleware
+	)
+	r.action.Register(
+		constants.CMD_DELETE_MESSAGE,
+		handlers.HandleDeleteMessage(chatService), // handler
+		middleware.AuthMiddleware(userRepo),       // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_MUTE,
+		handlers.HandleMuteChat(chatService), // handler
+		middleware.AuthMiddleware(userRepo),  // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_UNMUTE,
+		handlers.HandleUnmuteChat(chatService), // handler
+		middleware.AuthMiddleware(userRepo),    // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_ARCHIVE,
+		handlers.HandleArchiveChat(chatService), // handler
+		middleware.AuthMiddleware(userRepo),     // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_UNARCHIVE,
+		handlers.HandleUnarchiveChat(chatService), // handler
+		middleware.AuthMiddleware(userRepo),       // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_PIN,
+		handlers.HandlePinChat(chatService), // handler
+		middleware.AuthMiddleware(userRepo), // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_UNPIN,
+		handlers.HandleUnpinChat(chatService), // handler
+		middleware.AuthMiddleware(userRepo),   // middleware
+	)
+	r.action.Register(
+		constants.CMD_DELETE_CHAT,
+		handlers.HandleDeleteChat(chatService), // handler
+		middleware.AuthMiddleware(userRepo),    // middleware
+	)
+	r.action.Register(
+		constants.CMD_REACT_MESSAGE,
+		handlers.HandleReactMessage(chatService), // handler
+		middleware.AuthMiddleware(userRepo),      // middleware
+	)
+	r.action.Register(
+		constants.CMD_FORWARD_MESSAGE,
+		handlers.HandleForwardMessage(chatService), // handler
+		middleware.AuthMiddleware(userRepo),        // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_SEARCH,
+		handlers.HandleSearchMessages(chatService), // handler
+		middleware.AuthMiddleware(userRepo),        // middleware
+	)
+	r.action.Register(
+		constants.CMD_SET_DISAPPEARING,
+		handlers.HandleSetDisappearing(chatService), // handler
+		middleware.AuthMiddleware(userRepo),         // middleware
+	)
+	r.action.Register(
+		constants.CMD_OPEN_VIEW_ONCE,
+		handlers.HandleOpenViewOnce(chatService), // handler
+		middleware.AuthMiddleware(userRepo),      // middleware
+	)
+	r.action.Register(
+		constants.CMD_LIVE_LOCATION_START,
+		handlers.HandleStartLiveLocation(chatService), // handler
+		middleware.AuthMiddleware(userRepo),           // middleware
+	)
+	r.action.Register(
+		constants.CMD_LIVE_LOCATION_STOP,
+		handlers.HandleStopLiveLocation(chatService), // handler
+		middleware.AuthMiddleware(userRepo),          // middleware
+	)
+	r.action.Register(
+		constants.CMD_LIVE_LOCATION_FETCH,
+		handlers.HandleFetchLiveLocations(chatService), // handler
+		middleware.AuthMiddleware(userRepo),            // middleware
+	)
+	r.action.Register(
+		constants.CMD_FETCH_CHAT_REQUESTS,
+		handlers.HandleGetChatRequests(chatService), // handler
+		middleware.AuthMiddleware(userRepo),         // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_REQUEST_ACCEPT,
+		handlers.HandleAcceptChatRequest(chatService), // handler
+		middleware.AuthMiddleware(userRepo),           // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_REQUEST_DELETE,
+		handlers.HandleDeleteChatRequest(chatService), // handler
+		middleware.AuthMiddleware(userRepo),           // middleware
+	)
+	r.action.Register(
+		constants.CMD_CHAT_REQUEST_BLOCK,
+		handlers.HandleBlockChatRequest(chatService), // handler
+		middleware.AuthMiddleware(userRepo),          // middleware
+	)
 
 	r.mux.HandleFunc("/", r.handlePacket)
 	r.mux.HandleFunc("/test", r.handlePacket)
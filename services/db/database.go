@@ -8,6 +8,7 @@ import (
 	"coolvibes/models/post"
 	"coolvibes/models/utils"
 
+	chat_payloads "coolvibes/models/chat/payloads"
 	post_payloads "coolvibes/models/post/payloads"
 
 	seed "coolvibes/seeders"
@@ -89,6 +90,8 @@ func Migrate(db *gorm.DB) error {
 
 		&models.Mention{},
 		&models.Hashtag{},
+		&models.HashtagTrendBucket{},
+		&models.HashtagFollow{},
 
 		&models.MatchSeen{},
 		&models.Follow{},
@@ -104,6 +107,10 @@ func Migrate(db *gorm.DB) error {
 		&post_payloads.EventKind{},
 		&post_payloads.Event{}, // Event tablosu artık Post tablosundan sonra
 		&post_payloads.EventAttendee{},
+		&post.FeedItem{},
+		&post.FeedState{},
+		&post.ForYouSnapshot{},
+		&post.PostRevision{},
 
 		&utils.Location{},
 
@@ -113,8 +120,31 @@ func Migrate(db *gorm.DB) error {
 
 		&chat.ChatParticipant{},
 		&chat.MessageRead{},
+		&chat.MessageRevision{},
+		&chat.MessageHidden{},
+		&chat.MessageReaction{},
+		&chat_payloads.Call{},
+		&chat.LiveLocation{},
 	)
 
+	// messages_reads artık posts tablosundaki sohbet mesajlarını işaret ediyor,
+	// eski messages tablosuna olan foreign key'leri kaldır
+	db.Exec(`ALTER TABLE messages_reads DROP CONSTRAINT IF EXISTS fk_messages_reads_message`)
+	db.Exec(`ALTER TABLE messages_reads DROP CONSTRAINT IF EXISTS fk_messages_reads`)
+
+	// Sohbet mesajı ekleri önceden owner_type "chat" ile kaydediliyordu; Post.Attachments
+	// ile yüklenebilmeleri için post'a taşı (grup avatarları chat'e ait kalır)
+	db.Exec(`UPDATE medias SET owner_type = 'post'
+		WHERE owner_type = 'chat' AND role IN ('chat_image', 'chat_media', 'chat_video', 'chat_audio')`)
+
+	// Hashtag sayfaları ve takip edilen etiketler büyük/küçük harf duyarsız eşlenir
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_hashtags_lower_tag ON hashtags (LOWER(tag), taggable_type)`)
+
+	// chat.search için sohbet mesajlarında tam metin index'i
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_posts_chat_content_fts ON posts
+		USING GIN (jsonb_to_tsvector('simple', content, '["string"]'))
+		WHERE contentable_type = 'chat'`)
+
 	/*
 		db.Exec(`
 		DO $$
@@ -0,0 +1,198 @@
+package ranking
+
+import (
+	"coolvibes/types"
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sinyal adları; RankedCandidate.Signals anahtarları
+const (
+	SignalVelocity  = "velocity"
+	SignalAffinity  = "affinity"
+	SignalProximity = "proximity"
+	SignalLanguage  = "language"
+	SignalFreshness = "freshness"
+)
+
+// Ağırlıkları ezmek için kullanılan ortam değişkeni (JSON)
+const WeightsEnv = "FEED_RANKER_WEIGHTS"
+
+// Viewer akışı isteyen kullanıcı
+type Viewer struct {
+	UserID   uuid.UUID
+	Language string // User.DefaultLanguage
+}
+
+// FeedRanker aday postları izleyiciye göre sıralar
+type FeedRanker interface {
+	Name() string
+	Rank(viewer Viewer, candidates []types.FeedCandidate, now time.Time) []types.RankedCandidate
+}
+
+// Weights sinyal ağırlıkları ve normalizasyon ölçekleri
+type Weights struct {
+	Velocity  float64 `json:"velocity"`
+	Affinity  float64 `json:"affinity"`
+	Proximity float64 `json:"proximity"`
+	Language  float64 `json:"language"`
+	Freshness float64 `json:"freshness"`
+
+	VelocityScale          float64 `json:"velocity_scale"`            // Saatlik etkileşim; bu değerde sinyal ~0.63
+	AffinityScale          float64 `json:"affinity_scale"`            // Etkileşim sayısı; bu değerde sinyal ~0.63
+	FollowingBonus         float64 `json:"following_bonus"`           // Takip edilen yazar için eklenen etkileşim
+	ProximityScaleKm       float64 `json:"proximity_scale_km"`        // Bu uzaklıkta sinyal ~0.37
+	FreshnessHalfLifeHours float64 `json:"freshness_half_life_hours"` // Tazelik sinyalinin yarılanma süresi
+}
+
+func DefaultWeights() Weights {
+	return Weights{
+		Velocity:  0.45,
+		Affinity:  0.25,
+		Proximity: 0.10,
+		Language:  0.10,
+		Freshness: 0.10,
+
+		VelocityScale:          3,
+		AffinityScale:          5,
+		FollowingBonus:         3,
+		ProximityScaleKm:       50,
+		FreshnessHalfLifeHours: 12,
+	}
+}
+
+// LoadWeights JSON'daki alanları varsayılan ağırlıkların üzerine yazar
+func LoadWeights(raw string) (Weights, error) {
+	weights := DefaultWeights()
+	if strings.TrimSpace(raw) == "" {
+		return weights, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &weights); err != nil {
+		return DefaultWeights(), err
+	}
+	return weights, nil
+}
+
+// WeightsFromEnv FEED_RANKER_WEIGHTS ortam değişkenini okur; geçersizse
+// varsayılan ağırlıkları ve hatayı döndürür
+func WeightsFromEnv() (Weights, error) {
+	return LoadWeights(os.Getenv(WeightsEnv))
+}
+
+// LinearRanker normalize edilmiş sinyallerin ağırlıklı toplamıyla sıralar
+type LinearRanker struct {
+	Weights Weights
+}
+
+func NewLinearRanker(weights Weights) *LinearRanker {
+	return &LinearRanker{Weights: weights}
+}
+
+func (r *LinearRanker) Name() string {
+	return "linear"
+}
+
+func (r *LinearRanker) Rank(viewer Viewer, candidates []types.FeedCandidate, now time.Time) []types.RankedCandidate {
+	w := r.Weights
+	ranked := make([]types.RankedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		signals := Signals(w, viewer, c, now)
+		score := w.Velocity*signals[SignalVelocity] +
+			w.Affinity*signals[SignalAffinity] +
+			w.Proximity*signals[SignalProximity] +
+			w.Language*signals[SignalLanguage] +
+			w.Freshness*signals[SignalFreshness]
+		ranked = append(ranked, types.RankedCandidate{FeedCandidate: c, Score: score, Signals: signals})
+	}
+	sortRanked(ranked)
+	return ranked
+}
+
+// Signals adayın 0-1 aralığına normalize edilmiş sinyalleri
+func Signals(w Weights, viewer Viewer, c types.FeedCandidate, now time.Time) map[string]float64 {
+	ageHours := now.Sub(c.CreatedAt).Hours()
+	if ageHours < 0 {
+		ageHours = 0
+	}
+
+	// Son saatlerdeki etkileşimler ile toplam etkileşimin yaşa oranı
+	rate := float64(c.RecentEngagements) + c.Engagement/math.Max(ageHours, 1)
+	affinity := c.Affinity
+	if c.Following {
+		affinity += w.FollowingBonus
+	}
+
+	proximity := 0.0
+	if c.DistanceMeters != nil && w.ProximityScaleKm > 0 {
+		proximity = math.Exp(-(*c.DistanceMeters / 1000) / w.ProximityScaleKm)
+	}
+
+	freshness := 0.0
+	if w.FreshnessHalfLifeHours > 0 {
+		freshness = math.Pow(0.5, ageHours/w.FreshnessHalfLifeHours)
+	}
+
+	return map[string]float64{
+		SignalVelocity:  saturate(math.Max(rate, 0), w.VelocityScale),
+		SignalAffinity:  saturate(math.Max(affinity, 0), w.AffinityScale),
+		SignalProximity: proximity,
+		SignalLanguage:  languageMatch(viewer.Language, c.Languages),
+		SignalFreshness: freshness,
+	}
+}
+
+// saturate x'i 0-1 aralığına sıkıştırır; x == scale iken ~0.63
+func saturate(x, scale float64) float64 {
+	if scale <= 0 {
+		return 0
+	}
+	return 1 - math.Exp(-x/scale)
+}
+
+// languageMatch içerik izleyicinin dilindeyse 1, dil bilgisi yoksa 0.5
+func languageMatch(viewerLanguage, languages string) float64 {
+	if languages == "" || viewerLanguage == "" {
+		return 0.5
+	}
+	viewerLanguage = strings.ToLower(viewerLanguage)
+	for _, lang := range strings.Split(languages, ",") {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		// "en-US" ile "en" eşleşir
+		if lang == viewerLanguage || strings.SplitN(lang, "-", 2)[0] == strings.SplitN(viewerLanguage, "-", 2)[0] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// ChronologicalRanker en yeni postları öne alır; değerlendirme için taban çizgisi
+type ChronologicalRanker struct{}
+
+func (ChronologicalRanker) Name() string {
+	return "chronological"
+}
+
+func (ChronologicalRanker) Rank(viewer Viewer, candidates []types.FeedCandidate, now time.Time) []types.RankedCandidate {
+	ranked := make([]types.RankedCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		ranked = append(ranked, types.RankedCandidate{FeedCandidate: c, Score: float64(c.CreatedAt.Unix())})
+	}
+	sortRanked(ranked)
+	return ranked
+}
+
+// Eşit skorlarda yeni post önde; sıralama deterministik kalır
+func sortRanked(ranked []types.RankedCandidate) {
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].PublicID > ranked[j].PublicID
+	})
+}
@@ -0,0 +1,165 @@
+package socket
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Kullanıcı başına tutulacak maksimum event sayısı
+	defaultEventLogSize = 500
+
+	// Bu süre boyunca event almayan ve bağlı olmayan kullanıcıların geçmişi silinir
+	eventStreamIdleTimeout = 24 * time.Hour
+	eventStreamEvictEvery  = 10 * time.Minute
+)
+
+// UserEvent, kullanıcıya gönderilmiş (veya gönderilmesi gereken) tek bir event
+type UserEvent struct {
+	Seq       int64     `json:"seq"`
+	Event     string    `json:"event"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Epoch geçmiş her oluşturulduğunda (sunucu yeniden başladığında ya da geçmiş
+// silindiğinde) değişir; seq numaraları yalnızca aynı epoch içinde anlamlıdır
+type eventStream struct {
+	epoch      string
+	seq        int64
+	events     []UserEvent
+	lastActive time.Time
+}
+
+// EventLog, her kullanıcı için artan sequence numarası ve sınırlı boyutta
+// bir event geçmişi tutar. Yeniden bağlanan istemciler last_seq sonrasını
+// bu geçmişten alır.
+type EventLog struct {
+	mu      sync.Mutex
+	limit   int
+	streams map[uuid.UUID]*eventStream
+}
+
+func NewEventLog(limit int) *EventLog {
+	if limit <= 0 {
+		limit = defaultEventLogSize
+	}
+	return &EventLog{
+		limit:   limit,
+		streams: make(map[uuid.UUID]*eventStream),
+	}
+}
+
+func (l *EventLog) stream(userID uuid.UUID) *eventStream {
+	st, ok := l.streams[userID]
+	if !ok {
+		st = &eventStream{epoch: uuid.NewString()}
+		l.streams[userID] = st
+	}
+	st.lastActive = time.Now()
+	return st
+}
+
+// Publish yeni bir sequence numarası üretir, event'i kaydeder ve deliver
+// fonksiyonunu kilit altında çağırır. Böylece replay ile canlı teslimat
+// birbirinin arasına giremez.
+func (l *EventLog) Publish(userID uuid.UUID, event string, build func(seq int64) (string, error), deliver func(UserEvent)) (UserEvent, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := l.stream(userID)
+	seq := st.seq + 1
+
+	payload, err := build(seq)
+	if err != nil {
+		return UserEvent{}, err
+	}
+
+	item := UserEvent{
+		Seq:       seq,
+		Event:     event,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}
+	st.seq = seq
+	st.events = append(st.events, item)
+	if len(st.events) > l.limit {
+		st.events = st.events[len(st.events)-l.limit:]
+	}
+
+	if deliver != nil {
+		deliver(item)
+	}
+	return item, nil
+}
+
+// Replay lastSeq'ten sonraki event'leri döndürür ve resume fonksiyonunu kilit
+// altında çağırır. truncated=true ise istemcinin beklediği event'lerin bir
+// kısmı log'dan düşmüştür (veya sunucu yeniden başlamıştır), istemci tam
+// senkronizasyon yapmalıdır. epoch istemcinin son sync'te aldığı değerdir;
+// boşsa (eski istemciler) kontrol edilmez.
+func (l *EventLog) Replay(userID uuid.UUID, epoch string, lastSeq int64, resume func(events []UserEvent, currentEpoch string, currentSeq int64, truncated bool)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := l.stream(userID)
+
+	// last_seq gönderilmediyse (ilk bağlantı) replay yapılmaz
+	if lastSeq <= 0 {
+		resume(nil, st.epoch, st.seq, false)
+		return
+	}
+
+	// Geçmiş yeniden oluşturulmuş ya da istemci sunucudan daha ileri bir seq
+	// biliyor: sunucu yeniden başlamış
+	if (epoch != "" && epoch != st.epoch) || lastSeq > st.seq {
+		resume(nil, st.epoch, st.seq, true)
+		return
+	}
+
+	var missed []UserEvent
+	for _, e := range st.events {
+		if e.Seq > lastSeq {
+			missed = append(missed, e)
+		}
+	}
+
+	truncated := false
+	if len(missed) > 0 && missed[0].Seq > lastSeq+1 {
+		truncated = true
+	}
+	if len(missed) == 0 && st.seq > lastSeq {
+		truncated = true
+	}
+
+	resume(missed, st.epoch, st.seq, truncated)
+}
+
+// EvictIdle idle süresinden uzun süredir kullanılmayan geçmişleri siler;
+// connected true dönen kullanıcıların geçmişi tutulur
+func (l *EventLog) EvictIdle(idle time.Duration, connected func(userID uuid.UUID) bool) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := time.Now().Add(-idle)
+	evicted := 0
+	for userID, st := range l.streams {
+		if st.lastActive.Before(cutoff) && !connected(userID) {
+			delete(l.streams, userID)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// CurrentSeq kullanıcının son sequence numarasını döndürür
+func (l *EventLog) CurrentSeq(userID uuid.UUID) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if st, ok := l.streams[userID]; ok {
+		return st.seq
+	}
+	return 0
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,10 +27,46 @@ import (
 var Server *socketio.Server
 var userConnections = make(map[string]socketio.Conn)
 var userPublicIDs = make(map[string]int64) // map[socketID]publicID
+var userIDs = make(map[string]uuid.UUID)   // map[socketID]userID
+var sessionMu sync.RWMutex
+var eventLog = NewEventLog(defaultEventLogSize)
+
+// İstemciden gelen ve servis katmanında işlenen event'ler.
+// Router kurulurken SocketService.On ile kaydedilir, ListenServer'da bağlanır.
+var clientHandlers = make(map[string]func(userID uuid.UUID, msg string))
+
+// Kullanıcının son bağlantısı kapandığında çağrılan handler'lar
+// (ör. devam eden aramaları kapatmak için). SocketService.OnUserOffline ile kaydedilir.
+var offlineHandlers []func(userID uuid.UUID)
 var allowOriginFunc = func(r *http.Request) bool {
 	return true
 }
 
+// Kullanıcının kişisel odası; sequence numaralı event'ler buraya gönderilir
+func userRoom(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+// auth event'i eski istemciler için düz "Bearer <token>" string'i,
+// yeni istemciler için {"token": "...", "epoch": "...", "last_seq": 42} JSON'u
+// kabul eder. epoch istemcinin son sync mesajında aldığı değerdir.
+type authMessage struct {
+	Token   string `json:"token"`
+	Epoch   string `json:"epoch"`
+	LastSeq int64  `json:"last_seq"`
+}
+
+func parseAuthMessage(msg string) authMessage {
+	trimmed := strings.TrimSpace(msg)
+	if strings.HasPrefix(trimmed, "{") {
+		var m authMessage
+		if err := json.Unmarshal([]byte(trimmed), &m); err == nil {
+			return m
+		}
+	}
+	return authMessage{Token: trimmed}
+}
+
 func updateUserRooms(s socketio.Conn, db *gorm.DB, publicID int64, join bool) error {
 	var chatIDs []uuid.UUID
 
@@ -102,7 +139,8 @@ func ListenServer(db *gorm.DB, notificationManager *managers.NotificationManager
 	})
 
 	Server.OnEvent("/", "auth", func(s socketio.Conn, msg string) {
-		authHeader := msg
+		authMsg := parseAuthMessage(msg)
+		authHeader := authMsg.Token
 		if authHeader == "" {
 			return
 		}
@@ -120,10 +158,42 @@ func ListenServer(db *gorm.DB, notificationManager *managers.NotificationManager
 		}
 
 		userPublicIDs[s.ID()] = claims.PublicID
+		sessionMu.Lock()
+		userIDs[s.ID()] = claims.UserID
+		sessionMu.Unlock()
 		updateUserRooms(s, db, claims.PublicID, true)
 
+		// Kaçırılan event'leri canlı teslimat başlamadan önce gönder.
+		// Kişisel odaya katılım replay ile aynı kilit altında yapılır,
+		// böylece arada yayınlanan event'ler kaybolmaz veya çift gelmez.
+		eventLog.Replay(claims.UserID, authMsg.Epoch, authMsg.LastSeq, func(events []UserEvent, currentEpoch string, currentSeq int64, truncated bool) {
+			for _, e := range events {
+				s.Emit(e.Event, e.Payload)
+			}
+			s.Join(userRoom(claims.UserID))
+
+			syncMessage, _ := json.Marshal(map[string]interface{}{
+				"epoch":     currentEpoch,
+				"seq":       currentSeq,
+				"replayed":  len(events),
+				"truncated": truncated,
+			})
+			s.Emit("sync", string(syncMessage))
+		})
 	})
 
+	for event, handler := range clientHandlers {
+		Server.OnEvent("/", event, func(s socketio.Conn, msg string) {
+			sessionMu.RLock()
+			userID, ok := userIDs[s.ID()]
+			sessionMu.RUnlock()
+			if !ok {
+				return
+			}
+			handler(userID, msg)
+		})
+	}
+
 	Server.OnEvent("/", "join", func(s socketio.Conn, msg string) {
 		fmt.Println("chatJoin:", msg)
 		s.Emit("auth", "have "+msg)
@@ -163,9 +233,26 @@ func ListenServer(db *gorm.DB, notificationManager *managers.NotificationManager
 			updateUserRooms(s, db, publicID, false) // false = leave rooms
 			delete(userPublicIDs, s.ID())
 		}
+		sessionMu.Lock()
+		userID, authed := userIDs[s.ID()]
+		offline := false
+		if authed {
+			s.Leave(userRoom(userID))
+			delete(userIDs, s.ID())
+			offline = !isConnected(userID)
+		}
+		sessionMu.Unlock()
 		fmt.Println("Disconnected:", s.ID())
+
+		if offline {
+			for _, handler := range offlineHandlers {
+				go handler(userID)
+			}
+		}
 	})
 
+	go runEventLogEviction(eventStreamEvictEvery)
+
 	go func() {
 		if err := Server.Serve(); err != nil {
 			log.Fatalf("socketio listen error: %s\n", err)
@@ -188,6 +275,38 @@ func ListenServer(db *gorm.DB, notificationManager *managers.NotificationManager
 
 }
 
+// isConnected kullanıcının auth olmuş başka bir bağlantısı var mı.
+// sessionMu tutulurken çağrılmalı.
+func isConnected(userID uuid.UUID) bool {
+	for _, id := range userIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// runEventLogEviction bağlı olmayan kullanıcıların eski event geçmişlerini
+// periyodik olarak siler. Silinen geçmiş yeniden oluşturulduğunda epoch
+// değişir; istemci bir sonraki auth'ta tam senkronizasyon yapar.
+func runEventLogEviction(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sessionMu.RLock()
+		connected := make(map[uuid.UUID]bool, len(userIDs))
+		for _, userID := range userIDs {
+			connected[userID] = true
+		}
+		sessionMu.RUnlock()
+
+		eventLog.EvictIdle(eventStreamIdleTimeout, func(userID uuid.UUID) bool {
+			return connected[userID]
+		})
+	}
+}
+
 type SocketService struct {
 	db *gorm.DB
 }
@@ -207,6 +326,105 @@ func (socketService *SocketService) BroadcastToNamespace(namespace string, event
 
 }
 
+// On istemcinin gönderdiği bir event için handler kaydeder. Handler sadece
+// auth olmuş bağlantılar için, kullanıcının ID'si ile çağrılır.
+func (socketService *SocketService) On(event string, handler func(userID uuid.UUID, msg string)) {
+	clientHandlers[event] = handler
+}
+
+// OnUserOffline kullanıcının son bağlantısı kapandığında çağrılacak handler kaydeder
+func (socketService *SocketService) OnUserOffline(handler func(userID uuid.UUID)) {
+	offlineHandlers = append(offlineHandlers, handler)
+}
+
+// EmitToUser event'i kullanıcının event log'una sequence numarasıyla ekler ve
+// kullanıcının kişisel odasına gönderir. Kullanıcı o an bağlı değilse event
+// log'da kalır ve bir sonraki auth'ta last_seq ile tekrar gönderilir.
+func (socketService *SocketService) EmitToUser(userID uuid.UUID, event string, message map[string]interface{}) error {
+	_, err := eventLog.Publish(userID, event, func(seq int64) (string, error) {
+		envelope := make(map[string]interface{}, len(message)+1)
+		for k, v := range message {
+			envelope[k] = v
+		}
+		envelope["seq"] = seq
+
+		b, err := json.Marshal(envelope)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}, func(e UserEvent) {
+		if Server == nil {
+			return
+		}
+		Server.BroadcastToRoom("/", userRoom(userID), e.Event, e.Payload)
+	})
+	return err
+}
+
+// BroadcastToChat event'i sohbetin aktif katılımcılarının her birine kendi
+// sequence numarasıyla gönderir
+func (socketService *SocketService) BroadcastToChat(chatID uuid.UUID, event string, message map[string]interface{}) error {
+	var participantIDs []uuid.UUID
+	err := socketService.db.
+		Table("chat_participants").
+		Select("user_id").
+		Where("chat_id = ?", chatID).
+		Scan(&participantIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, participantID := range participantIDs {
+		if err := socketService.EmitToUser(participantID, event, message); err != nil {
+			log.Printf("Failed to emit %s to user %s: %v", event, participantID, err)
+		}
+	}
+	return nil
+}
+
+// SignalChat geçici (typing, konum vb.) event'leri sohbetin katılımcılarına
+// event log'a yazmadan gönderir; bu event'ler yeniden bağlanınca replay edilmez
+func (socketService *SocketService) SignalChat(chatID uuid.UUID, event string, message map[string]interface{}) error {
+	if Server == nil {
+		return nil
+	}
+
+	var participantIDs []uuid.UUID
+	err := socketService.db.
+		Table("chat_participants").
+		Select("user_id").
+		Where("chat_id = ?", chatID).
+		Scan(&participantIDs).Error
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	for _, participantID := range participantIDs {
+		Server.BroadcastToRoom("/", userRoom(participantID), event, string(b))
+	}
+	return nil
+}
+
+// SignalUser geçici event'i kullanıcının bağlı tüm cihazlarına event log'a
+// yazmadan gönderir (ör. WebRTC sinyalleri)
+func (socketService *SocketService) SignalUser(userID uuid.UUID, event string, message map[string]interface{}) error {
+	if Server == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	Server.BroadcastToRoom("/", userRoom(userID), event, string(b))
+	return nil
+}
+
 func (socketService *SocketService) SendMessageToUser(userId uuid.UUID, event string, message string) error {
 	/*
 		userRepo := &db.UserRepositoryImpl{DB: repo.DB}
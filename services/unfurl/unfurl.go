@@ -0,0 +1,485 @@
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrInvalidURL     = errors.New("unfurl: invalid url")
+	ErrBlockedAddress = errors.New("unfurl: address is not allowed")
+	ErrNotHTML        = errors.New("unfurl: response is not html")
+	ErrNoMetadata     = errors.New("unfurl: no preview metadata found")
+)
+
+type Config struct {
+	Timeout      time.Duration // Tek bir önizleme için toplam süre (oEmbed dahil)
+	MaxBodyBytes int64         // Okunacak maksimum gövde boyutu
+	MaxRedirects int
+	CacheTTL     time.Duration // Başarılı sonuçların önbellekte kalma süresi
+	ErrorTTL     time.Duration // Başarısız sonuçların önbellekte kalma süresi
+	CacheSize    int
+	UserAgent    string
+
+	// Yerel ve özel ağ adreslerine izin verir. Sadece test fixture'ları içindir.
+	AllowPrivateNetworks bool
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout:      5 * time.Second,
+		MaxBodyBytes: 512 * 1024,
+		MaxRedirects: 3,
+		CacheTTL:     6 * time.Hour,
+		ErrorTTL:     10 * time.Minute,
+		CacheSize:    2000,
+		UserAgent:    "CoolVibesBot/1.0 (+link preview)",
+	}
+}
+
+// Preview post / mesaj Extras'ına yazılan bağlantı önizlemesi
+type Preview struct {
+	URL          string    `json:"url"`
+	CanonicalURL string    `json:"canonical_url,omitempty"`
+	Type         string    `json:"type,omitempty"` // website, article, video, photo...
+	Title        string    `json:"title,omitempty"`
+	Description  string    `json:"description,omitempty"`
+	SiteName     string    `json:"site_name,omitempty"`
+	Image        string    `json:"image,omitempty"`
+	ImageWidth   string    `json:"image_width,omitempty"`
+	ImageHeight  string    `json:"image_height,omitempty"`
+	AuthorName   string    `json:"author_name,omitempty"`
+	TwitterCard  string    `json:"twitter_card,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+type cacheEntry struct {
+	preview   *Preview
+	err       error
+	expiresAt time.Time
+}
+
+type Unfurler struct {
+	cfg    Config
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+func New(cfg Config) *Unfurler {
+	u := &Unfurler{cfg: cfg, cache: make(map[string]cacheEntry)}
+
+	dialer := &net.Dialer{
+		Timeout: cfg.Timeout,
+		// DNS çözümlemesinden sonra bağlanılan gerçek adres kontrol edilir;
+		// böylece DNS rebinding ile iç ağa erişilemez
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return u.checkAddress(address)
+		},
+	}
+	transport := &http.Transport{
+		Proxy:                 nil, // ortam proxy'si SSRF kontrolünü atlatmasın
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   cfg.Timeout,
+		ResponseHeaderTimeout: cfg.Timeout,
+		MaxIdleConns:          20,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	u.client = &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > cfg.MaxRedirects {
+				return fmt.Errorf("unfurl: stopped after %d redirects", cfg.MaxRedirects)
+			}
+			return validateURL(req.URL)
+		},
+	}
+	return u
+}
+
+// checkAddress loopback, özel, link-local vb. adreslere bağlantıyı engeller
+func (u *Unfurler) checkAddress(address string) error {
+	if u.cfg.AllowPrivateNetworks {
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrBlockedAddress
+	}
+	if port != "80" && port != "443" {
+		return ErrBlockedAddress
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return ErrBlockedAddress
+	}
+	if !IsPublicAddr(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmark
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64, iç IPv4 adreslerine çevrilebilir
+}
+
+// IsPublicAddr adres internette yönlendirilebilir genel bir adres mi
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrInvalidURL
+	}
+	if u.Hostname() == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// Unfurl bağlantının önizlemesini döndürür. Sonuçlar (hatalar dahil) önbelleğe alınır.
+func (u *Unfurler) Unfurl(ctx context.Context, rawURL string) (*Preview, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	if err := validateURL(parsed); err != nil {
+		return nil, err
+	}
+	parsed.Fragment = ""
+	key := parsed.String()
+
+	if entry, ok := u.cached(key); ok {
+		return entry.preview, entry.err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.cfg.Timeout)
+	defer cancel()
+
+	preview, err := u.fetch(ctx, parsed)
+	u.store(key, preview, err)
+	return preview, err
+}
+
+func (u *Unfurler) cached(key string) (cacheEntry, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, ok := u.cache[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(u.cache, key)
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (u *Unfurler) store(key string, preview *Preview, err error) {
+	// İptal edilen istekler önbelleğe alınmaz
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	ttl := u.cfg.CacheTTL
+	if err != nil {
+		ttl = u.cfg.ErrorTTL
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if len(u.cache) >= u.cfg.CacheSize {
+		now := time.Now()
+		for k, e := range u.cache {
+			if now.After(e.expiresAt) {
+				delete(u.cache, k)
+			}
+		}
+		// Hâlâ doluysa rastgele bir kaydı çıkar (map iterasyon sırası rastgele)
+		for k := range u.cache {
+			if len(u.cache) < u.cfg.CacheSize {
+				break
+			}
+			delete(u.cache, k)
+		}
+	}
+	u.cache[key] = cacheEntry{preview: preview, err: err, expiresAt: time.Now().Add(ttl)}
+}
+
+// get isteği atar ve gövdenin en fazla MaxBodyBytes kadarını okur
+func (u *Unfurler) get(ctx context.Context, target string, accept string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", u.cfg.UserAgent)
+	req.Header.Set("Accept", accept)
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, nil, fmt.Errorf("unfurl: unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, u.cfg.MaxBodyBytes))
+	if err != nil {
+		return resp, nil, err
+	}
+	return resp, body, nil
+}
+
+func (u *Unfurler) fetch(ctx context.Context, target *url.URL) (*Preview, error) {
+	resp, body, err := u.get(ctx, target.String(), "text/html,application/xhtml+xml")
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	finalURL := resp.Request.URL
+	doc := parseDocument(string(body))
+
+	preview := &Preview{
+		URL:         target.String(),
+		Type:        doc.first("og:type"),
+		Title:       doc.first("og:title", "twitter:title"),
+		Description: doc.first("og:description", "twitter:description", "description"),
+		SiteName:    doc.first("og:site_name", "application-name"),
+		Image:       doc.first("og:image:secure_url", "og:image", "og:image:url", "twitter:image", "twitter:image:src"),
+		ImageWidth:  doc.first("og:image:width"),
+		ImageHeight: doc.first("og:image:height"),
+		AuthorName:  doc.first("author", "article:author", "twitter:creator"),
+		TwitterCard: doc.first("twitter:card"),
+		FetchedAt:   time.Now(),
+	}
+	if preview.Title == "" {
+		preview.Title = doc.title
+	}
+	preview.CanonicalURL = resolveURL(finalURL, firstNonEmpty(doc.first("og:url"), doc.canonical))
+	preview.Image = resolveURL(finalURL, preview.Image)
+
+	if doc.oembed != "" {
+		if oembedURL := resolveURL(finalURL, doc.oembed); oembedURL != "" {
+			if err := u.applyOEmbed(ctx, oembedURL, preview); err != nil {
+				// oEmbed isteğe bağlı; sayfa metadatası yeterli olabilir
+				fmt.Println("WARN: oembed fetch failed:", err)
+			}
+		}
+	}
+
+	if preview.Title == "" && preview.Description == "" && preview.Image == "" {
+		return nil, ErrNoMetadata
+	}
+	preview.Title = truncate(preview.Title, 300)
+	preview.Description = truncate(preview.Description, 1000)
+	return preview, nil
+}
+
+type oembedResponse struct {
+	Type         string      `json:"type"`
+	Title        string      `json:"title"`
+	AuthorName   string      `json:"author_name"`
+	ProviderName string      `json:"provider_name"`
+	ThumbnailURL string      `json:"thumbnail_url"`
+	Width        json.Number `json:"thumbnail_width"`
+	Height       json.Number `json:"thumbnail_height"`
+}
+
+// applyOEmbed sayfanın bildirdiği oEmbed JSON'u ile eksik alanları doldurur.
+// oEmbed "html" alanı güvenlik nedeniyle kullanılmaz.
+func (u *Unfurler) applyOEmbed(ctx context.Context, oembedURL string, preview *Preview) error {
+	_, body, err := u.get(ctx, oembedURL, "application/json")
+	if err != nil {
+		return err
+	}
+
+	var data oembedResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return err
+	}
+
+	if preview.Title == "" {
+		preview.Title = data.Title
+	}
+	if preview.AuthorName == "" {
+		preview.AuthorName = data.AuthorName
+	}
+	if preview.SiteName == "" {
+		preview.SiteName = data.ProviderName
+	}
+	if preview.Type == "" {
+		preview.Type = data.Type
+	}
+	if preview.Image == "" && data.ThumbnailURL != "" {
+		if thumb, err := url.Parse(data.ThumbnailURL); err == nil && validateURL(thumb) == nil {
+			preview.Image = thumb.String()
+			preview.ImageWidth = data.Width.String()
+			preview.ImageHeight = data.Height.String()
+		}
+	}
+	return nil
+}
+
+// --- HTML ayrıştırma ---
+
+var (
+	metaTagRe   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	linkTagRe   = regexp.MustCompile(`(?is)<link\s[^>]*>`)
+	titleRe     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	attrRe      = regexp.MustCompile(`(?s)([a-zA-Z_:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	spaceRe     = regexp.MustCompile(`\s+`)
+	headEndRe   = regexp.MustCompile(`(?i)</head>`)
+	urlInTextRe = regexp.MustCompile(`https?://[^\s<>"']+`)
+)
+
+type document struct {
+	meta      map[string]string
+	title     string
+	canonical string
+	oembed    string
+}
+
+func parseAttrs(tag string) map[string]string {
+	attrs := map[string]string{}
+	for _, m := range attrRe.FindAllStringSubmatch(tag, -1) {
+		value := m[2] + m[3] + m[4]
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(value)
+	}
+	return attrs
+}
+
+func parseDocument(body string) document {
+	// Metadata <head> içinde olmalı; gövdenin geri kalanını taramaya gerek yok
+	if loc := headEndRe.FindStringIndex(body); loc != nil {
+		body = body[:loc[0]]
+	}
+
+	doc := document{meta: map[string]string{}}
+	for _, tag := range metaTagRe.FindAllString(body, -1) {
+		attrs := parseAttrs(tag)
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		content := cleanText(attrs["content"])
+		if key == "" || content == "" {
+			continue
+		}
+		// Aynı anahtar birden çok kez varsa ilki geçerli
+		if _, exists := doc.meta[key]; !exists {
+			doc.meta[key] = content
+		}
+	}
+
+	for _, tag := range linkTagRe.FindAllString(body, -1) {
+		attrs := parseAttrs(tag)
+		rel := strings.ToLower(attrs["rel"])
+		switch {
+		case rel == "canonical" && doc.canonical == "":
+			doc.canonical = attrs["href"]
+		case rel == "alternate" && strings.EqualFold(attrs["type"], "application/json+oembed") && doc.oembed == "":
+			doc.oembed = attrs["href"]
+		}
+	}
+
+	if m := titleRe.FindStringSubmatch(body); m != nil {
+		doc.title = cleanText(html.UnescapeString(m[1]))
+	}
+	return doc
+}
+
+func (d document) first(keys ...string) string {
+	for _, key := range keys {
+		if v := d.meta[key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func cleanText(s string) string {
+	return strings.TrimSpace(spaceRe.ReplaceAllString(s, " "))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// resolveURL göreli adresi sayfa adresine göre çözer; sadece http(s) kabul edilir
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	resolved := base.ResolveReference(parsed)
+	if validateURL(resolved) != nil {
+		return ""
+	}
+	return resolved.String()
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+// FirstURL metindeki ilk http(s) bağlantısını döndürür
+func FirstURL(text string) string {
+	match := urlInTextRe.FindString(text)
+	// Cümle sonundaki noktalama bağlantıya dahil değildir
+	return strings.TrimRight(match, ".,;:!?)]}'\"")
+}
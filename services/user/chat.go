@@ -1,13 +1,15 @@
 package services
 
 import (
+	"context"
 	"coolvibes/constants"
 	"coolvibes/models"
 	"coolvibes/models/chat"
 	"coolvibes/models/post"
 	"coolvibes/repositories"
 	"coolvibes/services/socket"
-	"encoding/json"
+	"coolvibes/services/unfurl"
+	"coolvibes/types"
 	"errors"
 	"fmt"
 	"log"
@@ -16,6 +18,19 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	defaultSearchPageSize  = 20
+	defaultMessagePageSize = 30
+	maxMessagePageSize     = 100
+)
+
+var (
+	ErrNotChatParticipant = errors.New("user is not a participant of this chat")
+	// Sesli mesaj maksimum süreyi aşıyor ya da süresi okunamıyor
+	ErrVoiceMessageTooLong    = repositories.ErrAudioTooLong
+	ErrVoiceMessageUnreadable = repositories.ErrAudioUnreadable
+)
+
 type ChatService struct {
 	socketService    *socket.SocketService
 	mediaRepo        *repositories.MediaRepository
@@ -24,6 +39,11 @@ type ChatService struct {
 	matchesRepo      *repositories.MatchesRepository
 	chatRepo         *repositories.ChatRepository
 	notificationRepo *repositories.NotificationRepository
+	userService      *UserService
+	unfurler         *unfurl.Unfurler
+	typing           *TypingTracker
+	callTimers       callTimers
+	liveLocations    liveLocationThrottle
 }
 
 func NewChatService(
@@ -33,30 +53,59 @@ func NewChatService(
 	mediaRepo *repositories.MediaRepository,
 	matchesRepo *repositories.MatchesRepository,
 	chatRepo *repositories.ChatRepository,
-	notificationRepo *repositories.NotificationRepository) *ChatService {
-	return &ChatService{
-		socketService: socketService, postRepo: postRepo, mediaRepo: mediaRepo, userRepo: userRepo, matchesRepo: matchesRepo, chatRepo: chatRepo, notificationRepo: notificationRepo}
+	notificationRepo *repositories.NotificationRepository,
+	userService *UserService,
+	unfurler *unfurl.Unfurler) *ChatService {
+	s := &ChatService{
+		socketService: socketService, postRepo: postRepo, mediaRepo: mediaRepo, userRepo: userRepo, matchesRepo: matchesRepo, chatRepo: chatRepo, notificationRepo: notificationRepo, userService: userService, unfurler: unfurler}
+	s.typing = NewTypingTracker(s.publishTypingEvent)
+	socketService.On(constants.CMD_MARK_DELIVERED, s.handleDeliveryAck)
+	socketService.On(constants.CMD_LIVE_LOCATION_UPDATE, s.handleLiveLocationUpdate)
+	userService.OnBlock(s.stopLiveLocationsOnBlock)
+	s.registerCallHandlers()
+	go s.runMessageSweeper(messageSweepInterval)
+	return s
 }
 
 func (s *ChatService) UserRepo() *repositories.UserRepository {
 	return s.userRepo
 }
 
+// SendTypingEvent yazma durumunu başlatır (typing=true) veya durdurur.
+// Yayın TypingTracker üzerinden throttle edilerek yapılır.
 func (s *ChatService) SendTypingEvent(chatID, userID uuid.UUID, typing bool) error {
-	message, _ := s.chatRepo.SendTypingEvent(chatID, userID, typing)
-	jsonMessage, err := json.Marshal(message)
+	if !typing {
+		s.typing.Stop(chatID, userID)
+		return nil
+	}
+
+	isParticipant, err := s.chatRepo.IsParticipant(chatID, userID)
 	if err != nil {
-		log.Printf("Error marshalling typing event: %v", err)
 		return err
 	}
-	log.Printf("User %s is typing in chat %s: %v", userID, chatID, typing)
-	err = s.socketService.BroadcastToRoom("/", chatID.String(), "chat", string(jsonMessage))
+	if !isParticipant {
+		return ErrNotChatParticipant
+	}
 
+	user, err := s.userRepo.GetUserByUUIDdWithoutRelations(userID)
 	if err != nil {
+		return err
+	}
+
+	s.typing.Start(chatID, types.TypingUser{
+		ID:          user.ID,
+		PublicID:    user.PublicID,
+		UserName:    user.UserName,
+		DisplayName: user.DisplayName,
+	})
+	return nil
+}
+
+func (s *ChatService) publishTypingEvent(chatID, userID uuid.UUID, typing bool, users []types.TypingUser, summary string) {
+	message, _ := s.chatRepo.SendTypingEvent(chatID, userID, typing, users, summary)
+	if err := s.socketService.SignalChat(chatID, "chat", message); err != nil {
 		log.Printf("Error broadcasting typing event: %v", err)
-		return nil
 	}
-	return err
 }
 
 func (s *ChatService) CreateChat(participantUserId, userID uuid.UUID, chatType string) (*chat.Chat, error) {
@@ -69,11 +118,26 @@ func (s *ChatService) CreateChat(participantUserId, userID uuid.UUID, chatType s
 		return nil, errors.New("you cannot create a chat with yourself")
 	}
 
+	ctx := context.Background()
+	blocked, err := s.isBlockedBetween(ctx, userID, participantUserId)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserBlocked
+	}
+
 	if chatType == string(chat.ChatTypePrivate) {
 		chat, err := s.chatRepo.GetPrivateChatBetweenUsers(participantUserId, userID)
 		if err != nil {
+			// Takip etmediği ve eşleşmediği birinden geliyorsa mesaj isteği olarak açılır
+			isRequest, err := s.needsMessageRequest(ctx, userID, participantUserId)
+			if err != nil {
+				return nil, err
+			}
+
 			// Eğer private chat bulunamazsa yeni oluştur
-			chat, err := s.chatRepo.CreatePrivateChat(userID, participantUserId)
+			chat, err := s.chatRepo.CreatePrivateChat(userID, participantUserId, isRequest)
 			if err != nil {
 				return nil, errors.New("failed to create chat")
 			}
@@ -86,11 +150,26 @@ func (s *ChatService) CreateChat(participantUserId, userID uuid.UUID, chatType s
 	return nil, errors.New("unsupported chat type")
 }
 
-func (s *ChatService) GetChatsByUserID(userID uuid.UUID) ([]chat.Chat, error) {
-	return s.chatRepo.GetChatsByUserID(userID)
+func (s *ChatService) GetChatsByUserID(userID uuid.UUID, archived bool) ([]chat.Chat, error) {
+	return s.chatRepo.GetChatsByUserID(userID, archived)
 }
 
 func (s *ChatService) AddMessageToChat(request map[string][]string, files []*multipart.FileHeader, author *models.User) (*post.Post, error) {
+	var chatID uuid.UUID
+	if values := request["chat_id"]; len(values) > 0 {
+		parsed, err := uuid.Parse(values[0])
+		if err != nil {
+			return nil, err
+		}
+		chatID = parsed
+	}
+	if err := s.checkCanSend(context.Background(), chatID, author); err != nil {
+		return nil, err
+	}
+	if err := s.acceptOnReply(chatID, author); err != nil {
+		return nil, err
+	}
+
 	_post, err := s.chatRepo.AddMessageToChat(request, files, author)
 	fmt.Println("CODER", "CHAT1")
 
@@ -98,19 +177,51 @@ func (s *ChatService) AddMessageToChat(request map[string][]string, files []*mul
 		return nil, err
 	}
 
-	message := map[string]interface{}{
-		"action":  constants.CMD_SEND_MESSAGE,
-		"message": _post,
-	}
-	jsonMessage, _ := json.Marshal(message)
-	err = s.socketService.BroadcastToRoom("/", _post.ContentableID.String(), "chat", string(jsonMessage))
+	// Mesaj gönderen kullanıcı artık yazmıyor
+	s.typing.Stop(*_post.ContentableID, author.ID)
+
+	err = s.broadcastMessage(*_post.ContentableID, _post)
 	if err != nil {
 		log.Printf("Failed to broadcast message: %v", err)
 		return _post, err
 	}
+
+	go s.unfurlMessage(_post)
 	return _post, nil
 }
 
-func (s *ChatService) GetMessagesByChatID(userID uuid.UUID, chatID uuid.UUID) ([]post.Post, error) {
-	return s.chatRepo.GetMessagesByChatID(userID, chatID)
+func (s *ChatService) GetMessagesByChatID(userID uuid.UUID, chatID uuid.UUID, page types.MessagePage) (types.MessagesResult, error) {
+	isParticipant, err := s.chatRepo.IsParticipant(chatID, userID)
+	if err != nil {
+		return types.MessagesResult{}, err
+	}
+	if !isParticipant {
+		return types.MessagesResult{}, ErrNotChatParticipant
+	}
+
+	if page.Limit <= 0 {
+		page.Limit = defaultMessagePageSize
+	}
+	page.Limit = min(page.Limit, maxMessagePageSize)
+
+	result, err := s.chatRepo.GetMessagesByChatID(userID, chatID, page)
+	if err != nil {
+		return types.MessagesResult{}, err
+	}
+
+	// Mesaj isteği kabul edilmeden ekler gösterilmez; tek seferlik ekler
+	// sadece chat.open_view_once ile açılır
+	participant, err := s.chatRepo.GetParticipant(chatID, userID)
+	isRequest := err == nil && participant.IsRequest
+	for i := range result.Messages {
+		redactMessage(&result.Messages[i], userID, isRequest)
+	}
+
+	// Mesajlar kullanıcıya ulaştı; okundu bilgisi chat.mark_read ile gelir
+	if len(result.Messages) > 0 {
+		if err := s.MarkDelivered(userID, chatID, result.Messages[len(result.Messages)-1].PublicID); err != nil {
+			log.Printf("Failed to mark messages delivered: %v", err)
+		}
+	}
+	return result, nil
 }
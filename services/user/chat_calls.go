@@ -0,0 +1,442 @@
+package services
+
+import (
+	"context"
+	"coolvibes/constants"
+	"coolvibes/models/chat"
+	"coolvibes/models/chat/payloads"
+	"coolvibes/models/notifications"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cevaplanmayan arama bu süre sonunda cevapsız olarak kapanır
+const callRingTimeout = 45 * time.Second
+
+var (
+	ErrCallNotFound = errors.New("call not found")
+	ErrCallBusy     = errors.New("user is already in a call")
+	ErrCallState    = errors.New("call is not in a valid state for this action")
+)
+
+// callSignal istemciden gelen arama sinyali. SDP ve ICE adayları sunucu
+// tarafından yorumlanmadan karşı tarafa iletilir.
+type callSignal struct {
+	CallID    uuid.UUID       `json:"call_id"`
+	ChatID    uuid.UUID       `json:"chat_id"`
+	CallType  string          `json:"call_type"` // audio, video
+	SDP       json.RawMessage `json:"sdp,omitempty"`
+	Candidate json.RawMessage `json:"candidate,omitempty"`
+}
+
+// callTimers çalan aramaların zaman aşımı sayaçları
+type callTimers struct {
+	mu     sync.Mutex
+	timers map[uuid.UUID]*time.Timer
+}
+
+func (t *callTimers) start(callID uuid.UUID, after time.Duration, fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timers == nil {
+		t.timers = make(map[uuid.UUID]*time.Timer)
+	}
+	t.timers[callID] = time.AfterFunc(after, fn)
+}
+
+func (t *callTimers) stop(callID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if timer, ok := t.timers[callID]; ok {
+		timer.Stop()
+		delete(t.timers, callID)
+	}
+}
+
+// registerCallHandlers arama sinyal event'lerini socket'e bağlar
+func (s *ChatService) registerCallHandlers() {
+	handlers := map[string]func(userID uuid.UUID, signal callSignal) error{
+		constants.CMD_CHAT_SEND_CALL: s.startCall,
+		constants.CMD_CALL_RINGING:   s.callRinging,
+		constants.CMD_CALL_ACCEPT:    s.acceptCall,
+		constants.CMD_CALL_ANSWER:    s.answerCall,
+		constants.CMD_CALL_OFFER:     s.relayOffer,
+		constants.CMD_CALL_ICE:       s.relayCandidate,
+		constants.CMD_CALL_REJECT:    s.rejectCall,
+		constants.CMD_CALL_HANGUP:    s.hangupCall,
+	}
+
+	for event, handler := range handlers {
+		event, handler := event, handler
+		s.socketService.On(event, func(userID uuid.UUID, msg string) {
+			var signal callSignal
+			err := json.Unmarshal([]byte(msg), &signal)
+			if err == nil {
+				err = handler(userID, signal)
+			}
+			if err != nil {
+				log.Printf("Call signal %s failed: %v", event, err)
+				s.signalCall(userID, map[string]interface{}{
+					"action":  constants.CMD_CALL_ERROR,
+					"event":   event,
+					"call_id": signal.CallID.String(),
+					"error":   err.Error(),
+				})
+			}
+		})
+	}
+	s.socketService.OnUserOffline(s.endCallOnDisconnect)
+}
+
+// endCallOnDisconnect kullanıcının son bağlantısı koptuğunda aramasını kapatır.
+// Çalan aramada aranan kişi push bildirimiyle dönebileceği için arama
+// çalma zaman aşımına bırakılır.
+func (s *ChatService) endCallOnDisconnect(userID uuid.UUID) {
+	call, err := s.chatRepo.GetActiveCallForUser(userID)
+	if err != nil {
+		return
+	}
+	if call.Status == payloads.CallRinging && call.ReceiverID == userID {
+		return
+	}
+	if err := s.hangupCall(userID, callSignal{CallID: call.ID}); err != nil && !errors.Is(err, ErrCallState) {
+		log.Printf("Failed to end call %s after disconnect: %v", call.ID, err)
+	}
+}
+
+func (s *ChatService) signalCall(userID uuid.UUID, message map[string]interface{}) {
+	if err := s.socketService.SignalUser(userID, "call", message); err != nil {
+		log.Printf("Failed to signal call event to %s: %v", userID, err)
+	}
+}
+
+// activeCall kullanıcının taraf olduğu aktif aramayı döndürür
+func (s *ChatService) activeCall(userID, callID uuid.UUID) (*payloads.Call, error) {
+	call, err := s.chatRepo.GetCall(callID)
+	if err != nil {
+		return nil, ErrCallNotFound
+	}
+	if call.CallerID != userID && call.ReceiverID != userID {
+		return nil, ErrCallNotFound
+	}
+	if !call.IsActive() {
+		return nil, ErrCallState
+	}
+	return call, nil
+}
+
+// startCall özel sohbette arama başlatır ve offer'ı karşı tarafa iletir
+func (s *ChatService) startCall(userID uuid.UUID, signal callSignal) error {
+	if signal.CallType != "audio" && signal.CallType != "video" {
+		return errors.New("invalid call type")
+	}
+
+	chatObj, err := s.chatRepo.GetChatByIDWithoutRelations(signal.ChatID)
+	if err != nil {
+		return err
+	}
+	if chatObj.Type != chat.ChatTypePrivate {
+		return errors.New("calls are only supported in private chats")
+	}
+	if _, err := s.chatRepo.GetParticipant(signal.ChatID, userID); err != nil {
+		return ErrNotChatParticipant
+	}
+
+	participants, err := s.chatRepo.GetParticipants(signal.ChatID)
+	if err != nil {
+		return err
+	}
+	var receiver *chat.ChatParticipant
+	for i := range participants {
+		if participants[i].UserID != userID {
+			receiver = &participants[i]
+		}
+	}
+	if receiver == nil {
+		return ErrParticipantMissing
+	}
+	// Kabul edilmemiş mesaj isteğinden arama yapılamaz
+	if receiver.IsRequest {
+		return ErrNotPermitted
+	}
+
+	blocked, err := s.isBlockedBetween(context.Background(), userID, receiver.UserID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrUserBlocked
+	}
+
+	for _, id := range []uuid.UUID{userID, receiver.UserID} {
+		if _, err := s.chatRepo.GetActiveCallForUser(id); err == nil {
+			return ErrCallBusy
+		}
+	}
+
+	call := &payloads.Call{
+		ID:         uuid.New(),
+		ChatID:     signal.ChatID,
+		CallerID:   userID,
+		ReceiverID: receiver.UserID,
+		CallType:   signal.CallType,
+		Status:     payloads.CallRinging,
+	}
+	if err := s.chatRepo.CreateCall(call); err != nil {
+		return err
+	}
+
+	s.callTimers.start(call.ID, callRingTimeout, func() {
+		if err := s.finishCall(call.ID, payloads.CallRinging, payloads.CallMissed); err != nil && !errors.Is(err, ErrCallState) {
+			log.Printf("Failed to time out call %s: %v", call.ID, err)
+		}
+	})
+
+	caller, err := s.userRepo.GetUserByUUIDdWithoutRelations(userID)
+	if err != nil {
+		return err
+	}
+
+	s.signalCall(userID, map[string]interface{}{
+		"action":    constants.CMD_CALL_STARTED,
+		"call_id":   call.ID.String(),
+		"chat_id":   call.ChatID.String(),
+		"call_type": call.CallType,
+	})
+	s.signalCall(receiver.UserID, map[string]interface{}{
+		"action":    constants.CMD_CHAT_SEND_CALL,
+		"call_id":   call.ID.String(),
+		"chat_id":   call.ChatID.String(),
+		"call_type": call.CallType,
+		"caller": map[string]interface{}{
+			"id":          caller.ID,
+			"public_id":   fmt.Sprint(caller.PublicID),
+			"username":    caller.UserName,
+			"displayname": caller.DisplayName,
+		},
+		"sdp": signal.SDP,
+	})
+	return nil
+}
+
+// callRinging aranan cihazın çaldığını arayana bildirir
+func (s *ChatService) callRinging(userID uuid.UUID, signal callSignal) error {
+	call, err := s.activeCall(userID, signal.CallID)
+	if err != nil {
+		return err
+	}
+	if call.ReceiverID != userID || call.Status != payloads.CallRinging {
+		return ErrCallState
+	}
+
+	s.signalCall(call.CallerID, map[string]interface{}{
+		"action":  constants.CMD_CALL_RINGING,
+		"call_id": call.ID.String(),
+	})
+	return nil
+}
+
+// acceptCall aramayı cevaplar; aranan kişinin diğer cihazları da çalmayı bırakır
+func (s *ChatService) acceptCall(userID uuid.UUID, signal callSignal) error {
+	call, err := s.activeCall(userID, signal.CallID)
+	if err != nil {
+		return err
+	}
+	if call.ReceiverID != userID || call.Status != payloads.CallRinging {
+		return ErrCallState
+	}
+
+	now := time.Now()
+	call.Status = payloads.CallOngoing
+	call.StartedAt = &now
+	ok, err := s.chatRepo.UpdateCallStatus(call, payloads.CallRinging)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCallState
+	}
+	s.callTimers.stop(call.ID)
+
+	accepted := map[string]interface{}{
+		"action":  constants.CMD_CALL_ACCEPT,
+		"call_id": call.ID.String(),
+	}
+	s.signalCall(call.CallerID, accepted)
+	s.signalCall(call.ReceiverID, accepted)
+	return nil
+}
+
+// answerCall SDP answer'ı arayana iletir; çalan aramada answer kabul sayılır
+func (s *ChatService) answerCall(userID uuid.UUID, signal callSignal) error {
+	call, err := s.activeCall(userID, signal.CallID)
+	if err != nil {
+		return err
+	}
+	if call.Status == payloads.CallRinging {
+		if err := s.acceptCall(userID, signal); err != nil {
+			return err
+		}
+	}
+
+	s.signalCall(call.OtherParty(userID), map[string]interface{}{
+		"action":  constants.CMD_CALL_ANSWER,
+		"call_id": call.ID.String(),
+		"sdp":     signal.SDP,
+	})
+	return nil
+}
+
+// relayOffer devam eden aramada yeniden müzakere (ör. kamerayı açma) offer'ı
+func (s *ChatService) relayOffer(userID uuid.UUID, signal callSignal) error {
+	call, err := s.activeCall(userID, signal.CallID)
+	if err != nil {
+		return err
+	}
+	if call.Status != payloads.CallOngoing {
+		return ErrCallState
+	}
+
+	s.signalCall(call.OtherParty(userID), map[string]interface{}{
+		"action":  constants.CMD_CALL_OFFER,
+		"call_id": call.ID.String(),
+		"sdp":     signal.SDP,
+	})
+	return nil
+}
+
+func (s *ChatService) relayCandidate(userID uuid.UUID, signal callSignal) error {
+	call, err := s.activeCall(userID, signal.CallID)
+	if err != nil {
+		return err
+	}
+
+	s.signalCall(call.OtherParty(userID), map[string]interface{}{
+		"action":    constants.CMD_CALL_ICE,
+		"call_id":   call.ID.String(),
+		"candidate": signal.Candidate,
+	})
+	return nil
+}
+
+func (s *ChatService) rejectCall(userID uuid.UUID, signal callSignal) error {
+	call, err := s.activeCall(userID, signal.CallID)
+	if err != nil {
+		return err
+	}
+	if call.ReceiverID != userID || call.Status != payloads.CallRinging {
+		return ErrCallState
+	}
+	return s.finishCall(call.ID, payloads.CallRinging, payloads.CallRejected)
+}
+
+// hangupCall aramayı kapatır. Çalarken arayan kapatırsa cevapsız, aranan
+// kapatırsa reddedilmiş; görüşme sırasında kapatılırsa tamamlanmış sayılır.
+func (s *ChatService) hangupCall(userID uuid.UUID, signal callSignal) error {
+	call, err := s.activeCall(userID, signal.CallID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case call.Status == payloads.CallOngoing:
+		return s.finishCall(call.ID, payloads.CallOngoing, payloads.CallEnded)
+	case call.CallerID == userID:
+		return s.finishCall(call.ID, payloads.CallRinging, payloads.CallMissed)
+	default:
+		return s.finishCall(call.ID, payloads.CallRinging, payloads.CallRejected)
+	}
+}
+
+// finishCall aramayı kapatır, iki tarafa bildirir ve arama geçmişini sohbete
+// mesaj olarak ekler
+func (s *ChatService) finishCall(callID uuid.UUID, from, to payloads.CallStatus) error {
+	call, err := s.chatRepo.GetCall(callID)
+	if err != nil {
+		return ErrCallNotFound
+	}
+	if call.Status != from {
+		return ErrCallState
+	}
+
+	now := time.Now()
+	call.Status = to
+	call.EndedAt = &now
+	if call.StartedAt != nil {
+		call.Duration = int(now.Sub(*call.StartedAt).Seconds())
+	}
+	ok, err := s.chatRepo.UpdateCallStatus(call, from)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCallState
+	}
+	s.callTimers.stop(call.ID)
+
+	ended := map[string]interface{}{
+		"action":   constants.CMD_CALL_ENDED,
+		"call_id":  call.ID.String(),
+		"status":   call.Status,
+		"duration": call.Duration,
+	}
+	s.signalCall(call.CallerID, ended)
+	s.signalCall(call.ReceiverID, ended)
+
+	message, err := s.chatRepo.AddCallMessage(call, callSummary(call))
+	if err != nil {
+		return err
+	}
+	if err := s.broadcastMessage(call.ChatID, message); err != nil {
+		log.Printf("Failed to broadcast call message: %v", err)
+	}
+
+	if call.Status == payloads.CallMissed {
+		s.notifyMissedCall(call)
+	}
+	return nil
+}
+
+func (s *ChatService) notifyMissedCall(call *payloads.Call) {
+	caller, err := s.userRepo.GetUserByUUIDdWithoutRelations(call.CallerID)
+	if err != nil {
+		return
+	}
+	receiver, err := s.userRepo.GetUserByUUIDdWithoutRelations(call.ReceiverID)
+	if err != nil {
+		return
+	}
+
+	title := "Missed Call"
+	text := fmt.Sprintf("You missed a %s call from %s.", call.CallType, displayName(caller))
+	payload := notifications.NotificationPayload{
+		Title: title,
+		Body:  text,
+	}
+	if err := s.notificationRepo.SendNotificationToUser(*caller, *receiver, notifications.NotificationTypeMissedCall, title, text, payload); err != nil {
+		log.Printf("Failed to send missed call notification: %v", err)
+	}
+}
+
+// callSummary sohbet geçmişinde görünen arama metni
+func callSummary(call *payloads.Call) string {
+	kind := "voice call"
+	if call.CallType == "video" {
+		kind = "video call"
+	}
+
+	switch call.Status {
+	case payloads.CallMissed:
+		return "Missed " + kind
+	case payloads.CallRejected:
+		return "Declined " + kind
+	default:
+		return fmt.Sprintf("Ended %s · %s", kind, time.Duration(call.Duration)*time.Second)
+	}
+}
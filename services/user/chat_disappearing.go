@@ -0,0 +1,203 @@
+package services
+
+import (
+	"coolvibes/constants"
+	"coolvibes/models"
+	"coolvibes/models/chat"
+	"coolvibes/models/post"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Kaybolan mesaj süresi sınırları
+	minDisappearAfter = 5 * time.Second
+	maxDisappearAfter = 90 * 24 * time.Hour
+
+	// Tek seferlik ek açıldıktan sonra istemcinin dosyayı indirebilmesi için
+	// dosyalar bu süre sonunda diskten silinir
+	viewOnceGracePeriod = 2 * time.Minute
+
+	messageSweepInterval = 30 * time.Second
+	messageSweepBatch    = 200
+)
+
+var (
+	ErrInvalidDisappearAfter = errors.New("invalid disappearing message duration")
+	ErrNotViewOnce           = errors.New("message is not a view-once message")
+	ErrViewOnceOpened        = errors.New("view-once media was already opened")
+)
+
+// SetDisappearingMessages sohbetin kaybolan mesaj süresini ayarlar; 0 kapatır.
+// Grup sohbetlerinde sadece sahip ve yöneticiler değiştirebilir.
+func (s *ChatService) SetDisappearingMessages(actor *models.User, chatID uuid.UUID, after time.Duration) error {
+	if after != 0 && (after < minDisappearAfter || after > maxDisappearAfter) {
+		return ErrInvalidDisappearAfter
+	}
+
+	chatObj, err := s.chatRepo.GetChatByIDWithoutRelations(chatID)
+	if err != nil {
+		return err
+	}
+	if chatObj.Type == chat.ChatTypeGroup {
+		if _, err := s.requireGroupRole(chatID, actor.ID, chat.RoleOwner, chat.RoleAdmin); err != nil {
+			return err
+		}
+	} else if _, err := s.chatRepo.GetParticipant(chatID, actor.ID); err != nil {
+		return ErrNotChatParticipant
+	}
+
+	var seconds *int64
+	if after > 0 {
+		value := int64(after / time.Second)
+		seconds = &value
+	}
+	if err := s.chatRepo.SetDisappearAfter(chatID, seconds); err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("%s turned off disappearing messages", displayName(actor))
+	if seconds != nil {
+		text = fmt.Sprintf("%s set disappearing messages to %s", displayName(actor), after)
+	}
+	s.emitSystemMessage(chatID, actor, chat.SystemEventDisappearingChanged, text, map[string]any{
+		"disappear_after": seconds,
+	})
+	return nil
+}
+
+// OpenViewOnce tek seferlik mesajın eklerini alıcıya bir kez döndürür ve
+// gönderene bildirir. Tüm alıcılar açtığında dosyalar diskten silinir.
+func (s *ChatService) OpenViewOnce(actor *models.User, chatID uuid.UUID, publicID int64) (*post.Post, error) {
+	message, _, err := s.findChatMessage(actor.ID, chatID, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if !chat.IsViewOnce(message) {
+		return nil, ErrNotViewOnce
+	}
+	if message.AuthorID == actor.ID {
+		return nil, ErrNotPermitted
+	}
+	if _, consumed := message.GetExtra(chat.ExtraViewOnceConsumed); consumed {
+		return nil, ErrViewOnceOpened
+	}
+
+	opened, err := s.chatRepo.MarkViewOnceOpened(message.ID, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !opened {
+		return nil, ErrViewOnceOpened
+	}
+
+	openedAt := time.Now()
+	err = s.socketService.EmitToUser(message.AuthorID, "chat", map[string]interface{}{
+		"action":     constants.CMD_VIEW_ONCE_OPENED,
+		"chat_id":    chatID.String(),
+		"message_id": message.ID.String(),
+		"user_id":    actor.ID.String(),
+		"opened_at":  openedAt,
+	})
+	if err != nil {
+		log.Printf("Failed to emit view-once opened: %v", err)
+	}
+
+	if err := s.scheduleViewOncePurgeIfDone(message, openedAt); err != nil {
+		log.Printf("Failed to schedule view-once purge: %v", err)
+	}
+	return message, nil
+}
+
+// scheduleViewOncePurgeIfDone diğer tüm katılımcılar mesajı açtıysa dosyaların
+// silinme zamanını yazar; silme işlemini sweeper yapar
+func (s *ChatService) scheduleViewOncePurgeIfDone(message *post.Post, openedAt time.Time) error {
+	updated, err := s.chatRepo.FindMessageByPublicID(*message.ContentableID, message.PublicID)
+	if err != nil {
+		return err
+	}
+
+	opened := map[string]bool{}
+	for _, id := range chat.ViewOnceOpenedBy(updated) {
+		opened[id] = true
+	}
+
+	participants, err := s.chatRepo.GetParticipants(*message.ContentableID)
+	if err != nil {
+		return err
+	}
+	for _, participant := range participants {
+		if participant.UserID == message.AuthorID {
+			continue
+		}
+		if !opened[participant.UserID.String()] {
+			return nil
+		}
+	}
+	return s.chatRepo.ScheduleViewOncePurge(message.ID, openedAt.Add(viewOnceGracePeriod))
+}
+
+// runMessageSweeper süresi dolan kaybolan mesajları, açılmış tek seferlik
+// eklerin dosyalarını ve süresi dolan canlı konumları periyodik olarak temizler
+func (s *ChatService) runMessageSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.sweepExpiredMessages()
+		s.sweepViewOnceMedia()
+		s.sweepLiveLocations()
+	}
+}
+
+func (s *ChatService) sweepExpiredMessages() {
+	messages, err := s.chatRepo.GetExpiredMessages(messageSweepBatch)
+	if err != nil {
+		log.Printf("Failed to load expired messages: %v", err)
+		return
+	}
+
+	for i := range messages {
+		message := &messages[i]
+		if err := s.mediaRepo.DeleteMediaByOwner(message.ID); err != nil {
+			log.Printf("Failed to delete media of expired message %s: %v", message.ID, err)
+			continue
+		}
+		if err := s.chatRepo.PurgeMessage(message); err != nil {
+			log.Printf("Failed to purge expired message %s: %v", message.ID, err)
+			continue
+		}
+
+		err := s.socketService.BroadcastToChat(*message.ContentableID, "chat", map[string]interface{}{
+			"action":     constants.CMD_DELETE_MESSAGE,
+			"scope":      "expired",
+			"chat_id":    message.ContentableID.String(),
+			"message_id": message.ID.String(),
+		})
+		if err != nil {
+			log.Printf("Failed to broadcast expired message: %v", err)
+		}
+	}
+}
+
+func (s *ChatService) sweepViewOnceMedia() {
+	messages, err := s.chatRepo.GetViewOnceDue(messageSweepBatch)
+	if err != nil {
+		log.Printf("Failed to load view-once messages: %v", err)
+		return
+	}
+
+	for _, message := range messages {
+		if err := s.mediaRepo.DeleteMediaByOwner(message.ID); err != nil {
+			log.Printf("Failed to delete view-once media of %s: %v", message.ID, err)
+			continue
+		}
+		if err := s.chatRepo.MarkViewOnceConsumed(message.ID); err != nil {
+			log.Printf("Failed to mark view-once message %s consumed: %v", message.ID, err)
+		}
+	}
+}
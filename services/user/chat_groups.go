@@ -0,0 +1,294 @@
+package services
+
+import (
+	"coolvibes/constants"
+	"coolvibes/models"
+	"coolvibes/models/chat"
+	"coolvibes/models/media"
+	"coolvibes/models/post"
+	"coolvibes/models/utils"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotGroupChat       = errors.New("chat is not a group chat")
+	ErrNotPermitted       = errors.New("you do not have permission for this action")
+	ErrParticipantMissing = errors.New("user is not a member of this group")
+)
+
+func displayName(u *models.User) string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.UserName
+}
+
+// CreateGroup yeni bir grup sohbeti oluşturur; oluşturan kişi grubun sahibi olur
+func (s *ChatService) CreateGroup(creator *models.User, participantIDs []uuid.UUID, title, description string, avatar *multipart.FileHeader) (*chat.Chat, error) {
+	if title == "" {
+		return nil, errors.New("group title is required")
+	}
+
+	memberIDs := make([]uuid.UUID, 0, len(participantIDs))
+	for _, id := range participantIDs {
+		if _, err := s.userRepo.GetUserByUUIDdWithoutRelations(id); err != nil {
+			return nil, fmt.Errorf("user %s does not exist", id)
+		}
+		memberIDs = append(memberIDs, id)
+	}
+
+	lang := creator.DefaultLanguage
+	groupChat, err := s.chatRepo.CreateGroupChat(
+		creator.ID,
+		memberIDs,
+		utils.MakeLocalizedString(lang, title),
+		utils.MakeLocalizedString(lang, description),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if avatar != nil {
+		avatarMedia, err := s.mediaRepo.AddMedia(groupChat.ID, media.OwnerChat, creator.ID, media.RoleAvatar, avatar)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload group avatar: %w", err)
+		}
+		if err := s.chatRepo.SetAvatar(groupChat.ID, avatarMedia.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.emitSystemMessage(groupChat.ID, creator, chat.SystemEventGroupCreated,
+		fmt.Sprintf("%s created the group \"%s\"", displayName(creator), title), nil)
+
+	return s.chatRepo.GetChatByIDWithoutRelations(groupChat.ID)
+}
+
+// InviteToGroup kullanıcıları gruba ekler (sahip veya admin)
+func (s *ChatService) InviteToGroup(actor *models.User, chatID uuid.UUID, userIDs []uuid.UUID) error {
+	if _, err := s.requireGroupRole(chatID, actor.ID, chat.RoleOwner, chat.RoleAdmin); err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		user, err := s.userRepo.GetUserByUUIDdWithoutRelations(userID)
+		if err != nil {
+			return fmt.Errorf("user %s does not exist", userID)
+		}
+		if existing, err := s.chatRepo.GetParticipant(chatID, userID); err == nil && existing != nil {
+			continue
+		}
+		if err := s.chatRepo.AddParticipant(chatID, userID, chat.RoleMember); err != nil {
+			return err
+		}
+
+		s.emitSystemMessage(chatID, actor, chat.SystemEventMemberAdded,
+			fmt.Sprintf("%s added %s", displayName(actor), displayName(user)),
+			map[string]any{chat.ExtraTargetID: user.ID})
+	}
+	return nil
+}
+
+// RemoveFromGroup bir üyeyi gruptan çıkarır. Admin sadece üyeleri, sahip herkesi çıkarabilir.
+func (s *ChatService) RemoveFromGroup(actor *models.User, chatID, userID uuid.UUID) error {
+	if actor.ID == userID {
+		return s.LeaveGroup(actor, chatID)
+	}
+
+	actorParticipant, err := s.requireGroupRole(chatID, actor.ID, chat.RoleOwner, chat.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.chatRepo.GetParticipant(chatID, userID)
+	if err != nil {
+		return ErrParticipantMissing
+	}
+	if target.Role == chat.RoleOwner || (target.Role == chat.RoleAdmin && actorParticipant.Role != chat.RoleOwner) {
+		return ErrNotPermitted
+	}
+
+	user, err := s.userRepo.GetUserByUUIDdWithoutRelations(userID)
+	if err != nil {
+		return err
+	}
+
+	// Sistem mesajı çıkarılan kişiye de ulaşsın diye önce gönderilir
+	s.emitSystemMessage(chatID, actor, chat.SystemEventMemberRemoved,
+		fmt.Sprintf("%s removed %s", displayName(actor), displayName(user)),
+		map[string]any{chat.ExtraTargetID: user.ID})
+	s.stopUserLiveLocations(chatID, []uuid.UUID{userID}, "left")
+
+	return s.chatRepo.RemoveParticipant(chatID, userID)
+}
+
+// LeaveGroup kullanıcı gruptan ayrılır. Sahip ayrılırsa sahiplik en eski
+// admine, admin yoksa en eski üyeye devredilir; devredilemezse sahip
+// ayrılamaz. Gruptaki son kişi sahip olsa da ayrılabilir.
+func (s *ChatService) LeaveGroup(actor *models.User, chatID uuid.UUID) error {
+	participant, err := s.requireGroupRole(chatID, actor.ID)
+	if err != nil {
+		return err
+	}
+
+	if participant.Role == chat.RoleOwner {
+		successor, err := s.chatRepo.GetSuccessor(chatID, actor.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			if err := s.TransferOwnership(actor, chatID, successor.UserID); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.emitSystemMessage(chatID, actor, chat.SystemEventMemberLeft,
+		fmt.Sprintf("%s left the group", displayName(actor)), nil)
+	s.stopUserLiveLocations(chatID, []uuid.UUID{actor.ID}, "left")
+
+	return s.chatRepo.RemoveParticipant(chatID, actor.ID)
+}
+
+// PromoteAdmin bir üyeyi admin yapar (sadece sahip)
+func (s *ChatService) PromoteAdmin(actor *models.User, chatID, userID uuid.UUID) error {
+	if _, err := s.requireGroupRole(chatID, actor.ID, chat.RoleOwner); err != nil {
+		return err
+	}
+
+	target, err := s.chatRepo.GetParticipant(chatID, userID)
+	if err != nil {
+		return ErrParticipantMissing
+	}
+	if target.Role != chat.RoleMember && target.Role != "" {
+		return nil
+	}
+
+	user, err := s.userRepo.GetUserByUUIDdWithoutRelations(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.chatRepo.UpdateParticipantRole(chatID, userID, chat.RoleAdmin); err != nil {
+		return err
+	}
+
+	s.emitSystemMessage(chatID, actor, chat.SystemEventAdminPromoted,
+		fmt.Sprintf("%s made %s an admin", displayName(actor), displayName(user)),
+		map[string]any{chat.ExtraTargetID: user.ID})
+	return nil
+}
+
+// TransferOwnership grubun sahipliğini başka bir üyeye devreder (sadece sahip)
+func (s *ChatService) TransferOwnership(actor *models.User, chatID, newOwnerID uuid.UUID) error {
+	if actor.ID == newOwnerID {
+		return nil
+	}
+	if _, err := s.requireGroupRole(chatID, actor.ID, chat.RoleOwner); err != nil {
+		return err
+	}
+	if _, err := s.chatRepo.GetParticipant(chatID, newOwnerID); err != nil {
+		return ErrParticipantMissing
+	}
+
+	user, err := s.userRepo.GetUserByUUIDdWithoutRelations(newOwnerID)
+	if err != nil {
+		return err
+	}
+	if err := s.chatRepo.TransferOwnership(chatID, actor.ID, newOwnerID); err != nil {
+		return err
+	}
+
+	s.emitSystemMessage(chatID, actor, chat.SystemEventOwnershipTransferred,
+		fmt.Sprintf("%s transferred ownership to %s", displayName(actor), displayName(user)),
+		map[string]any{chat.ExtraTargetID: user.ID})
+	return nil
+}
+
+// PinMessage mesajı sohbetin sabit mesajı yapar; messagePublicID nil ise kaldırır.
+// Grup sohbetlerinde sadece sahip ve adminler sabitleyebilir.
+func (s *ChatService) PinMessage(actor *models.User, chatID uuid.UUID, messagePublicID *int64) error {
+	chatObj, err := s.chatRepo.GetChatByIDWithoutRelations(chatID)
+	if err != nil {
+		return err
+	}
+
+	participant, err := s.chatRepo.GetParticipant(chatID, actor.ID)
+	if err != nil {
+		return ErrNotChatParticipant
+	}
+	if chatObj.Type == chat.ChatTypeGroup && participant.Role != chat.RoleOwner && participant.Role != chat.RoleAdmin {
+		return ErrNotPermitted
+	}
+
+	if messagePublicID == nil {
+		if err := s.chatRepo.SetPinnedMessage(chatID, nil); err != nil {
+			return err
+		}
+		s.emitSystemMessage(chatID, actor, chat.SystemEventMessageUnpinned,
+			fmt.Sprintf("%s unpinned a message", displayName(actor)), nil)
+		return nil
+	}
+
+	message, err := s.chatRepo.FindMessageByPublicID(chatID, *messagePublicID)
+	if err != nil {
+		return ErrMessageNotFound
+	}
+	if err := s.chatRepo.SetPinnedMessage(chatID, &message.ID); err != nil {
+		return err
+	}
+
+	s.emitSystemMessage(chatID, actor, chat.SystemEventMessagePinned,
+		fmt.Sprintf("%s pinned a message", displayName(actor)),
+		map[string]any{"message_id": message.ID, "message_public_id": fmt.Sprint(message.PublicID)})
+	return nil
+}
+
+// requireGroupRole sohbetin grup olduğunu ve kullanıcının verilen rollerden
+// birine sahip olduğunu kontrol eder. Rol verilmezse üyelik yeterlidir.
+func (s *ChatService) requireGroupRole(chatID, userID uuid.UUID, roles ...chat.ParticipantRole) (*chat.ChatParticipant, error) {
+	chatObj, err := s.chatRepo.GetChatByIDWithoutRelations(chatID)
+	if err != nil {
+		return nil, err
+	}
+	if chatObj.Type != chat.ChatTypeGroup {
+		return nil, ErrNotGroupChat
+	}
+
+	participant, err := s.chatRepo.GetParticipant(chatID, userID)
+	if err != nil {
+		return nil, ErrNotChatParticipant
+	}
+	if len(roles) == 0 {
+		return participant, nil
+	}
+	for _, role := range roles {
+		if participant.Role == role {
+			return participant, nil
+		}
+	}
+	return nil, ErrNotPermitted
+}
+
+// emitSystemMessage sistem mesajını kaydeder ve sohbetin katılımcılarına gönderir
+func (s *ChatService) emitSystemMessage(chatID uuid.UUID, actor *models.User, event chat.SystemEvent, text string, data map[string]any) *post.Post {
+	message, err := s.chatRepo.AddSystemMessage(chatID, actor.ID, event, text, data)
+	if err != nil {
+		log.Printf("Failed to create system message: %v", err)
+		return nil
+	}
+
+	err = s.socketService.BroadcastToChat(chatID, "chat", map[string]interface{}{
+		"action":  constants.CMD_SEND_MESSAGE,
+		"message": message,
+	})
+	if err != nil {
+		log.Printf("Failed to broadcast system message: %v", err)
+	}
+	return message
+}
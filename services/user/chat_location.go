@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"coolvibes/constants"
+	"coolvibes/models"
+	"coolvibes/models/chat"
+	"coolvibes/models/post"
+	"coolvibes/repositories"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minLiveLocationDuration = time.Minute
+	maxLiveLocationDuration = 8 * time.Hour
+	// Aynı oturumdan gelen konum güncellemeleri bu aralıktan sık yayınlanmaz
+	liveLocationUpdateInterval = 2 * time.Second
+)
+
+var (
+	ErrInvalidCoordinates       = repositories.ErrInvalidCoordinates
+	ErrInvalidLiveLocationLimit = errors.New("invalid live location duration")
+	ErrLiveLocationNotFound     = errors.New("live location session not found")
+	ErrLiveLocationEnded        = errors.New("live location session has ended")
+)
+
+// LiveLocationPosition canlı konum güncellemesi
+type LiveLocationPosition struct {
+	Latitude  float64  `json:"lat"`
+	Longitude float64  `json:"lng"`
+	Accuracy  *float64 `json:"accuracy,omitempty"`
+	Heading   *float64 `json:"heading,omitempty"`
+}
+
+// StartLiveLocation süre sınırlı canlı konum paylaşımı başlatır. Paylaşım
+// sohbete bir konum mesajı olarak düşer; güncellemeler socket ile yayınlanır.
+func (s *ChatService) StartLiveLocation(actor *models.User, chatID uuid.UUID, duration time.Duration, position LiveLocationPosition) (*post.Post, error) {
+	if duration < minLiveLocationDuration || duration > maxLiveLocationDuration {
+		return nil, ErrInvalidLiveLocationLimit
+	}
+	if !repositories.ValidCoordinates(position.Latitude, position.Longitude) {
+		return nil, ErrInvalidCoordinates
+	}
+	if err := s.checkCanSend(context.Background(), chatID, actor); err != nil {
+		return nil, err
+	}
+
+	chatObj, err := s.chatRepo.GetChatByIDWithoutRelations(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &chat.LiveLocation{
+		ChatID:    chatID,
+		UserID:    actor.ID,
+		StartedAt: now,
+		ExpiresAt: now.Add(duration),
+		Latitude:  position.Latitude,
+		Longitude: position.Longitude,
+		Accuracy:  position.Accuracy,
+		Heading:   position.Heading,
+		UpdatedAt: now,
+	}
+	message, err := s.chatRepo.StartLiveLocation(chatObj, actor, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.broadcastMessage(chatID, message); err != nil {
+		log.Printf("Failed to broadcast live location message: %v", err)
+	}
+	return message, nil
+}
+
+// liveLocationThrottle oturum başına son yayınlanan güncellemenin zamanı.
+// Sık gelen güncellemeler veritabanına gitmeden atlanır; anahtar kullanıcıyı
+// da içerdiğinden başkasının oturum ID'siyle gönderilen güncellemeler sahibini
+// yavaşlatamaz.
+type liveLocationThrottle struct {
+	mu   sync.Mutex
+	last map[[2]uuid.UUID]time.Time
+}
+
+// allow güncelleme aralık dolduysa true döner ve zamanı kaydeder
+func (t *liveLocationThrottle) allow(userID, sessionID uuid.UUID, now time.Time, interval time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := [2]uuid.UUID{userID, sessionID}
+	if last, ok := t.last[key]; ok && now.Sub(last) < interval {
+		return false
+	}
+	if t.last == nil {
+		t.last = make(map[[2]uuid.UUID]time.Time)
+	}
+	// Aralığı dolan kayıtlar yokmuş gibi davranır; biriken oturumlar temizlenir
+	for k, last := range t.last {
+		if now.Sub(last) >= interval {
+			delete(t.last, k)
+		}
+	}
+	t.last[key] = now
+	return true
+}
+
+type liveLocationUpdate struct {
+	SessionID uuid.UUID `json:"session_id"`
+	LiveLocationPosition
+}
+
+// handleLiveLocationUpdate istemcinin socket üzerinden gönderdiği konumu
+// sohbete yayınlar. Güncellemeler geçicidir; sadece son konum saklanır.
+func (s *ChatService) handleLiveLocationUpdate(userID uuid.UUID, msg string) {
+	var update liveLocationUpdate
+	if err := json.Unmarshal([]byte(msg), &update); err != nil {
+		log.Printf("Invalid live location update: %v", err)
+		return
+	}
+	if err := s.UpdateLiveLocation(userID, update.SessionID, update.LiveLocationPosition); err != nil {
+		log.Printf("Live location update rejected: %v", err)
+	}
+}
+
+func (s *ChatService) UpdateLiveLocation(userID, sessionID uuid.UUID, position LiveLocationPosition) error {
+	if !repositories.ValidCoordinates(position.Latitude, position.Longitude) {
+		return ErrInvalidCoordinates
+	}
+	// Throttle: çok sık gelen güncellemeler sessizce atlanır
+	if !s.liveLocations.allow(userID, sessionID, time.Now(), liveLocationUpdateInterval) {
+		return nil
+	}
+
+	session, err := s.chatRepo.GetLiveLocation(sessionID)
+	if err != nil || session.UserID != userID {
+		return ErrLiveLocationNotFound
+	}
+	if !session.IsActive(time.Now()) {
+		return ErrLiveLocationEnded
+	}
+	// Paylaşım sürerken gruptan çıkarılan veya engellenen kullanıcının konumu yayınlanmaz
+	if err := s.checkCanSend(context.Background(), session.ChatID, &models.User{ID: userID}); err != nil {
+		if endErr := s.endLiveLocation(session, "not_permitted"); endErr != nil {
+			log.Printf("Failed to end live location %s: %v", session.ID, endErr)
+		}
+		return err
+	}
+
+	session.Latitude = position.Latitude
+	session.Longitude = position.Longitude
+	session.Accuracy = position.Accuracy
+	session.Heading = position.Heading
+	updated, err := s.chatRepo.UpdateLiveLocation(session, liveLocationUpdateInterval)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		// Başka bir sunucu bu aralıkta güncellemiş olabilir
+		return nil
+	}
+
+	return s.socketService.SignalChat(session.ChatID, "chat", map[string]interface{}{
+		"action":     constants.CMD_LIVE_LOCATION_UPDATE,
+		"chat_id":    session.ChatID.String(),
+		"session_id": session.ID.String(),
+		"user_id":    session.UserID.String(),
+		"lat":        position.Latitude,
+		"lng":        position.Longitude,
+		"accuracy":   position.Accuracy,
+		"heading":    position.Heading,
+		"at":         time.Now(),
+	})
+}
+
+// StopLiveLocation paylaşımı süresi dolmadan sonlandırır; sadece sahibi durdurabilir
+func (s *ChatService) StopLiveLocation(actor *models.User, sessionID uuid.UUID) error {
+	session, err := s.chatRepo.GetLiveLocation(sessionID)
+	if err != nil || session.UserID != actor.ID {
+		return ErrLiveLocationNotFound
+	}
+	if !session.IsActive(time.Now()) {
+		return ErrLiveLocationEnded
+	}
+	return s.endLiveLocation(session, "stopped")
+}
+
+func (s *ChatService) endLiveLocation(session *chat.LiveLocation, reason string) error {
+	if err := s.chatRepo.StopLiveLocation(session.ID); err != nil {
+		return err
+	}
+
+	err := s.socketService.BroadcastToChat(session.ChatID, "chat", map[string]interface{}{
+		"action":     constants.CMD_LIVE_LOCATION_STOP,
+		"chat_id":    session.ChatID.String(),
+		"session_id": session.ID.String(),
+		"message_id": session.MessageID.String(),
+		"user_id":    session.UserID.String(),
+		"reason":     reason,
+	})
+	if err != nil {
+		log.Printf("Failed to broadcast live location stop: %v", err)
+	}
+	return nil
+}
+
+// stopUserLiveLocations kullanıcıların sohbetteki aktif paylaşımlarını sonlandırır
+func (s *ChatService) stopUserLiveLocations(chatID uuid.UUID, userIDs []uuid.UUID, reason string) {
+	sessions, err := s.chatRepo.GetActiveLiveLocations(chatID)
+	if err != nil {
+		log.Printf("Failed to load live locations of chat %s: %v", chatID, err)
+		return
+	}
+	for i := range sessions {
+		for _, userID := range userIDs {
+			if sessions[i].UserID != userID {
+				continue
+			}
+			if err := s.endLiveLocation(&sessions[i], reason); err != nil {
+				log.Printf("Failed to stop live location %s: %v", sessions[i].ID, err)
+			}
+		}
+	}
+}
+
+// stopLiveLocationsOnBlock engellenen ve engelleyen kullanıcının aralarındaki
+// özel sohbetteki paylaşımlarını sonlandırır
+func (s *ChatService) stopLiveLocationsOnBlock(blockerID, blockedID uuid.UUID) {
+	chatObj, err := s.chatRepo.GetPrivateChatBetweenUsers(blockerID, blockedID)
+	if err != nil {
+		return
+	}
+	s.stopUserLiveLocations(chatObj.ID, []uuid.UUID{blockerID, blockedID}, "blocked")
+}
+
+// GetLiveLocations sohbetteki aktif paylaşımları son konumlarıyla döndürür
+func (s *ChatService) GetLiveLocations(userID, chatID uuid.UUID) ([]chat.LiveLocation, error) {
+	isParticipant, err := s.chatRepo.IsParticipant(chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isParticipant {
+		return nil, ErrNotChatParticipant
+	}
+	return s.chatRepo.GetActiveLiveLocations(chatID)
+}
+
+func (s *ChatService) sweepLiveLocations() {
+	sessions, err := s.chatRepo.GetExpiredLiveLocations(messageSweepBatch)
+	if err != nil {
+		log.Printf("Failed to load expired live locations: %v", err)
+		return
+	}
+	for i := range sessions {
+		if err := s.endLiveLocation(&sessions[i], "expired"); err != nil {
+			log.Printf("Failed to expire live location %s: %v", sessions[i].ID, err)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"coolvibes/constants"
+	"coolvibes/models"
+	"coolvibes/models/chat"
+	"coolvibes/models/post"
+	"coolvibes/models/utils"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Mesajın herkesten silinebileceği süre
+const deleteForEveryoneWindow = 48 * time.Hour
+
+var (
+	ErrMessageNotFound     = errors.New("message not found")
+	ErrMessageNotEditable  = errors.New("message cannot be modified")
+	ErrDeleteWindowExpired = errors.New("message can no longer be deleted for everyone")
+)
+
+// findChatMessage üyeliği kontrol eder ve sohbete ait mesajı bulur
+func (s *ChatService) findChatMessage(userID, chatID uuid.UUID, publicID int64) (*post.Post, *chat.ChatParticipant, error) {
+	participant, err := s.chatRepo.GetParticipant(chatID, userID)
+	if err != nil {
+		return nil, nil, ErrNotChatParticipant
+	}
+	message, err := s.chatRepo.FindMessageByPublicID(chatID, publicID)
+	if err != nil {
+		return nil, nil, ErrMessageNotFound
+	}
+	return message, participant, nil
+}
+
+// EditMessage mesajın içeriğini değiştirir, eski içerik revizyon olarak saklanır
+func (s *ChatService) EditMessage(actor *models.User, chatID uuid.UUID, publicID int64, content string) (*post.Post, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("content is required")
+	}
+
+	message, _, err := s.findChatMessage(actor.ID, chatID, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if message.AuthorID != actor.ID || chat.IsSystemMessage(message) || chat.IsTombstoned(message) {
+		return nil, ErrMessageNotEditable
+	}
+
+	if err := s.chatRepo.EditMessage(message, actor.ID, utils.MakeLocalizedString(actor.DefaultLanguage, content)); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.postRepo.GetPostByID(message.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bağlantı kalmadıysa eski önizleme silinir
+	if firstLink(updated) == "" {
+		if _, ok := updated.GetExtra(post.ExtraLinkPreview); ok {
+			if err := s.postRepo.DeletePostExtra(updated.ID, post.ExtraLinkPreview); err != nil {
+				log.Printf("Failed to remove link preview: %v", err)
+			}
+			delete(*updated.Extras, post.ExtraLinkPreview)
+		}
+	}
+
+	err = s.broadcastMessageAction(chatID, updated, constants.CMD_MESSAGE_UPDATED)
+	if err != nil {
+		log.Printf("Failed to broadcast message update: %v", err)
+	}
+
+	go s.unfurlMessage(updated)
+	return updated, nil
+}
+
+func (s *ChatService) GetMessageRevisions(userID, chatID uuid.UUID, publicID int64) ([]chat.MessageRevision, error) {
+	message, _, err := s.findChatMessage(userID, chatID, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.IsTombstoned(message) {
+		return []chat.MessageRevision{}, nil
+	}
+	return s.chatRepo.GetMessageRevisions(message.ID)
+}
+
+// DeleteMessage forEveryone=false ise mesaj sadece kullanıcı için gizlenir.
+// forEveryone=true ise yazar (süre sınırı içinde) veya grup sahibi/adminleri
+// mesajı herkesten siler; mesaj sohbette tombstone olarak kalır.
+func (s *ChatService) DeleteMessage(actor *models.User, chatID uuid.UUID, publicID int64, forEveryone bool) error {
+	message, participant, err := s.findChatMessage(actor.ID, chatID, publicID)
+	if err != nil {
+		return err
+	}
+
+	if !forEveryone {
+		if err := s.chatRepo.HideMessageForUser(message.ID, actor.ID); err != nil {
+			return err
+		}
+		// Sadece kullanıcının kendi cihazlarına
+		err := s.socketService.EmitToUser(actor.ID, "chat", map[string]interface{}{
+			"action":     constants.CMD_DELETE_MESSAGE,
+			"scope":      "me",
+			"chat_id":    chatID.String(),
+			"message_id": message.ID.String(),
+		})
+		if err != nil {
+			log.Printf("Failed to emit message delete: %v", err)
+		}
+		return nil
+	}
+
+	if chat.IsSystemMessage(message) || chat.IsTombstoned(message) {
+		return ErrMessageNotEditable
+	}
+
+	isModerator := participant.Role == chat.RoleOwner || participant.Role == chat.RoleAdmin
+	if message.AuthorID != actor.ID && !isModerator {
+		return ErrNotPermitted
+	}
+	if message.AuthorID == actor.ID && time.Since(message.CreatedAt) > deleteForEveryoneWindow {
+		return ErrDeleteWindowExpired
+	}
+
+	if err := s.chatRepo.TombstoneMessage(message, actor.ID); err != nil {
+		return err
+	}
+	// Dosyalar geri alınamadığından mesaj silindikten sonra kaldırılır
+	if err := s.mediaRepo.DeleteMediaByOwner(message.ID); err != nil {
+		log.Printf("Failed to delete message media %s: %v", message.ID, err)
+	}
+
+	tombstone, err := s.postRepo.GetPostByID(message.ID)
+	if err != nil {
+		return err
+	}
+
+	err = s.socketService.BroadcastToChat(chatID, "chat", map[string]interface{}{
+		"action":     constants.CMD_DELETE_MESSAGE,
+		"scope":      "everyone",
+		"chat_id":    chatID.String(),
+		"message_id": message.ID.String(),
+		"message":    tombstone,
+	})
+	if err != nil {
+		log.Printf("Failed to broadcast message delete: %v", err)
+	}
+	return nil
+}
+
+// HideChat sohbeti kullanıcının listesinden kaldırır; yeni mesaj gelirse
+// sohbet sadece yeni mesajlarla tekrar görünür
+func (s *ChatService) HideChat(actor *models.User, chatID uuid.UUID) error {
+	if _, err := s.chatRepo.GetParticipant(chatID, actor.ID); err != nil {
+		return ErrNotChatParticipant
+	}
+	if err := s.chatRepo.HideChatForUser(chatID, actor.ID); err != nil {
+		return err
+	}
+
+	err := s.socketService.EmitToUser(actor.ID, "chat", map[string]interface{}{
+		"action":  constants.CMD_DELETE_CHAT,
+		"chat_id": chatID.String(),
+	})
+	if err != nil {
+		log.Printf("Failed to emit chat delete: %v", err)
+	}
+	return nil
+}
+
+// Tek bir tepkinin maksimum uzunluğu (birleşik emojiler birden fazla rune olabilir)
+const maxReactionRunes = 16
+
+// ReactToMessage emoji tepkisini ekler / kaldırır ve güncel özeti yayınlar
+func (s *ChatService) ReactToMessage(actor *models.User, chatID uuid.UUID, publicID int64, emoji string) ([]post.ReactionSummary, bool, error) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxReactionRunes || strings.ContainsAny(emoji, " \t\n") {
+		return nil, false, errors.New("invalid reaction")
+	}
+
+	message, _, err := s.findChatMessage(actor.ID, chatID, publicID)
+	if err != nil {
+		return nil, false, err
+	}
+	if chat.IsSystemMessage(message) || chat.IsTombstoned(message) {
+		return nil, false, ErrMessageNotEditable
+	}
+
+	added, err := s.chatRepo.ToggleReaction(message, actor.ID, emoji)
+	if err != nil {
+		return nil, false, err
+	}
+
+	summaries, err := s.chatRepo.GetReactionSummaries([]uuid.UUID{message.ID})
+	if err != nil {
+		return nil, false, err
+	}
+	reactions := summaries[message.ID]
+
+	err = s.socketService.BroadcastToChat(chatID, "chat", map[string]interface{}{
+		"action":     constants.CMD_REACT_MESSAGE,
+		"chat_id":    chatID.String(),
+		"message_id": message.ID.String(),
+		"user_id":    actor.ID.String(),
+		"emoji":      emoji,
+		"added":      added,
+		"reactions":  reactions,
+	})
+	if err != nil {
+		log.Printf("Failed to broadcast reaction: %v", err)
+	}
+	return reactions, added, nil
+}
+
+// ForwardMessage mesajı kullanıcının üyesi olduğu diğer sohbetlere iletir
+func (s *ChatService) ForwardMessage(actor *models.User, chatID uuid.UUID, publicID int64, targetChatIDs []uuid.UUID) ([]*post.Post, error) {
+	message, _, err := s.findChatMessage(actor.ID, chatID, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if chat.IsSystemMessage(message) || chat.IsTombstoned(message) {
+		return nil, ErrMessageNotEditable
+	}
+	// Tek seferlik ekler iletilemez
+	if chat.IsViewOnce(message) {
+		return nil, ErrNotPermitted
+	}
+
+	forwarded := make([]*post.Post, 0, len(targetChatIDs))
+	for _, targetID := range targetChatIDs {
+		if err := s.checkCanSend(context.Background(), targetID, actor); err != nil {
+			return forwarded, err
+		}
+
+		copied, err := s.chatRepo.ForwardMessage(message, targetID, actor)
+		if err != nil {
+			return forwarded, err
+		}
+		forwarded = append(forwarded, copied)
+
+		err = s.broadcastMessage(targetID, copied)
+		if err != nil {
+			log.Printf("Failed to broadcast forwarded message: %v", err)
+		}
+	}
+	return forwarded, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"coolvibes/constants"
+	"coolvibes/models/chat"
+	"encoding/json"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Socket üzerinden gelen iletim onayı: {"chat_id": "...", "message_id": "123"}
+// message_id mesajın public_id'sidir ve o mesaja kadar olan tüm mesajları kapsar
+type deliveryAck struct {
+	ChatID    uuid.UUID       `json:"chat_id"`
+	MessageID json.RawMessage `json:"message_id"`
+}
+
+func (s *ChatService) handleDeliveryAck(userID uuid.UUID, msg string) {
+	var ack deliveryAck
+	if err := json.Unmarshal([]byte(msg), &ack); err != nil {
+		log.Printf("Invalid delivery ack: %v", err)
+		return
+	}
+
+	upTo, err := ParseUpToPublicID(strings.Trim(string(ack.MessageID), `"`))
+	if err != nil {
+		log.Printf("Invalid delivery ack message id: %v", err)
+		return
+	}
+
+	if err := s.MarkDelivered(userID, ack.ChatID, upTo); err != nil {
+		log.Printf("Failed to mark messages delivered: %v", err)
+	}
+}
+
+// ParseUpToPublicID boş değeri "en son mesaja kadar" olarak yorumlar
+func ParseUpToPublicID(value string) (int64, error) {
+	if value == "" || value == "null" {
+		return math.MaxInt64, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// MarkDelivered mesajları kullanıcıya iletildi olarak işaretler ve diğer
+// katılımcılara iletim bildirimi gönderir
+func (s *ChatService) MarkDelivered(userID, chatID uuid.UUID, upToPublicID int64) error {
+	isParticipant, err := s.chatRepo.IsParticipant(chatID, userID)
+	if err != nil {
+		return err
+	}
+	if !isParticipant {
+		return ErrNotChatParticipant
+	}
+
+	messageIDs, err := s.chatRepo.MarkMessagesDelivered(chatID, userID, upToPublicID)
+	if err != nil {
+		return err
+	}
+	return s.broadcastReceipts(chatID, userID, chat.Delivered, messageIDs)
+}
+
+// MarkRead upToPublicID'ye kadar olan mesajları okundu olarak işaretler,
+// kalan okunmamış mesaj sayısını döndürür
+func (s *ChatService) MarkRead(userID, chatID uuid.UUID, upToPublicID int64) (int, error) {
+	isParticipant, err := s.chatRepo.IsParticipant(chatID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if !isParticipant {
+		return 0, ErrNotChatParticipant
+	}
+
+	messageIDs, unreadCount, err := s.chatRepo.MarkMessagesRead(chatID, userID, upToPublicID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.broadcastReceipts(chatID, userID, chat.Seen, messageIDs); err != nil {
+		log.Printf("Failed to broadcast read receipts: %v", err)
+	}
+	return unreadCount, nil
+}
+
+// broadcastReceipts değişen mesajların güncel özetlerini işlemi yapan
+// kullanıcı dışındaki katılımcılara gönderir
+func (s *ChatService) broadcastReceipts(chatID, actorID uuid.UUID, status chat.MessageStatus, messageIDs []uuid.UUID) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	receipts, err := s.chatRepo.GetMessageReceipts(messageIDs)
+	if err != nil {
+		return err
+	}
+
+	participants, err := s.chatRepo.GetParticipants(chatID)
+	if err != nil {
+		return err
+	}
+
+	message := map[string]interface{}{
+		"action":   constants.CMD_RECEIPT,
+		"chat_id":  chatID.String(),
+		"user_id":  actorID.String(),
+		"status":   status,
+		"receipts": receipts,
+	}
+	for _, participant := range participants {
+		if participant.UserID == actorID {
+			continue
+		}
+		if err := s.socketService.EmitToUser(participant.UserID, "chat", message); err != nil {
+			log.Printf("Failed to emit receipt to user %s: %v", participant.UserID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"coolvibes/constants"
+	"coolvibes/models"
+	"coolvibes/models/chat"
+	"coolvibes/models/post"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+var ErrUserBlocked = errors.New("you cannot message this user")
+
+// needsMessageRequest alıcı başlatanı takip etmiyor ve eşleşmemişlerse
+// sohbet alıcının mesaj istekleri kutusuna düşer
+func (s *ChatService) needsMessageRequest(ctx context.Context, initiatorID, recipientID uuid.UUID) (bool, error) {
+	engagementRepo := s.userRepo.GetEngagementRepository()
+
+	follows, err := engagementRepo.HasUserEngaged(ctx, recipientID, initiatorID, models.EngagementKindFollowing)
+	if err != nil {
+		return false, err
+	}
+	if follows {
+		return false, nil
+	}
+
+	matched, err := s.matchesRepo.IsMatched(ctx, initiatorID, recipientID)
+	if err != nil {
+		return false, err
+	}
+	return !matched, nil
+}
+
+// isBlockedBetween iki kullanıcıdan biri diğerini engellemiş mi
+func (s *ChatService) isBlockedBetween(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	engagementRepo := s.userRepo.GetEngagementRepository()
+
+	blocked, err := engagementRepo.HasUserEngaged(ctx, a, b, models.EngagementKindBlocking)
+	if err != nil || blocked {
+		return blocked, err
+	}
+	return engagementRepo.HasUserEngaged(ctx, b, a, models.EngagementKindBlocking)
+}
+
+// checkCanSend gönderenin sohbete mesaj yazabileceğini kontrol eder; bir şey
+// değiştirmez. İletme ve konum güncellemeleri de bu kontrolden geçer.
+func (s *ChatService) checkCanSend(ctx context.Context, chatID uuid.UUID, sender *models.User) error {
+	if _, err := s.chatRepo.GetParticipant(chatID, sender.ID); err != nil {
+		return ErrNotChatParticipant
+	}
+
+	chatObj, err := s.chatRepo.GetChatByIDWithoutRelations(chatID)
+	if err != nil {
+		return err
+	}
+	if chatObj.Type == chat.ChatTypePrivate {
+		participants, err := s.chatRepo.GetParticipants(chatID)
+		if err != nil {
+			return err
+		}
+		for _, p := range participants {
+			if p.UserID == sender.ID {
+				continue
+			}
+			blocked, err := s.isBlockedBetween(ctx, sender.ID, p.UserID)
+			if err != nil {
+				return err
+			}
+			if blocked {
+				return ErrUserBlocked
+			}
+		}
+	}
+	return nil
+}
+
+// acceptOnReply istek durumundaki alıcı cevap yazarsa isteği kabul eder
+func (s *ChatService) acceptOnReply(chatID uuid.UUID, sender *models.User) error {
+	participant, err := s.chatRepo.GetParticipant(chatID, sender.ID)
+	if err != nil {
+		return ErrNotChatParticipant
+	}
+	if !participant.IsRequest {
+		return nil
+	}
+	return s.chatRepo.AcceptChatRequest(chatID, sender.ID)
+}
+
+// hideMedia mesaj isteklerinde ekleri gizler; sadece ek sayısı gösterilir
+func hideMedia(message *post.Post) {
+	if len(message.Attachments) == 0 {
+		return
+	}
+	message.SetExtra("media_hidden", len(message.Attachments))
+	message.Attachments = nil
+}
+
+// copyMessage Extras'ı paylaşmayan yüzeysel bir kopya döndürür
+func copyMessage(message *post.Post) post.Post {
+	copied := *message
+	if message.Extras != nil {
+		extras := make(map[string]any, len(*message.Extras))
+		for k, v := range *message.Extras {
+			extras[k] = v
+		}
+		copied.Extras = &extras
+	}
+	return copied
+}
+
+// redactMessage izleyiciye gösterilmemesi gereken ekleri çıkarır: kabul
+// edilmemiş mesaj isteklerinin ekleri ve tek seferlik ekler
+func redactMessage(message *post.Post, viewerID uuid.UUID, isRequest bool) {
+	if isRequest && message.AuthorID != viewerID {
+		hideMedia(message)
+	}
+	if chat.IsViewOnce(message) {
+		message.Attachments = nil
+	}
+}
+
+// broadcastMessage yeni mesajı katılımcılara gönderir; mesaj isteği
+// durumundaki alıcılar eksiz bir kopya alır
+func (s *ChatService) broadcastMessage(chatID uuid.UUID, message *post.Post) error {
+	return s.broadcastMessageAction(chatID, message, constants.CMD_SEND_MESSAGE)
+}
+
+// broadcastMessageAction mesajı her katılımcıya görebileceği haliyle gönderir
+func (s *ChatService) broadcastMessageAction(chatID uuid.UUID, message *post.Post, action string) error {
+	participants, err := s.chatRepo.GetParticipants(chatID)
+	if err != nil {
+		return err
+	}
+
+	for _, participant := range participants {
+		payload := message
+		if (participant.IsRequest && participant.UserID != message.AuthorID) || chat.IsViewOnce(message) {
+			copied := copyMessage(message)
+			redactMessage(&copied, participant.UserID, participant.IsRequest)
+			payload = &copied
+		}
+
+		err := s.socketService.EmitToUser(participant.UserID, "chat", map[string]interface{}{
+			"action":  action,
+			"chat_id": chatID.String(),
+			"message": payload,
+		})
+		if err != nil {
+			log.Printf("Failed to emit message to user %s: %v", participant.UserID, err)
+		}
+	}
+	return nil
+}
+
+func (s *ChatService) GetChatRequests(userID uuid.UUID) ([]chat.Chat, error) {
+	return s.chatRepo.GetChatRequestsByUserID(userID)
+}
+
+// requestSender istek durumundaki özel sohbette karşı tarafı döndürür
+func (s *ChatService) requestSender(chatID, recipientID uuid.UUID) (*models.User, error) {
+	participant, err := s.chatRepo.GetParticipant(chatID, recipientID)
+	if err != nil {
+		return nil, ErrNotChatParticipant
+	}
+	if !participant.IsRequest {
+		return nil, errors.New("chat is not a message request")
+	}
+
+	participants, err := s.chatRepo.GetParticipants(chatID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range participants {
+		if p.UserID != recipientID {
+			return s.userRepo.GetUserByUUIDdWithoutRelations(p.UserID)
+		}
+	}
+	return nil, errors.New("message request sender not found")
+}
+
+// AcceptChatRequest isteği kabul eder; sohbet normal sohbetlere taşınır ve
+// ekler görünür olur
+func (s *ChatService) AcceptChatRequest(actor *models.User, chatID uuid.UUID) error {
+	if _, err := s.requestSender(chatID, actor.ID); err != nil {
+		return err
+	}
+	if err := s.chatRepo.AcceptChatRequest(chatID, actor.ID); err != nil {
+		return err
+	}
+
+	err := s.socketService.BroadcastToChat(chatID, "chat", map[string]interface{}{
+		"action":  constants.CMD_CHAT_REQUEST_ACCEPT,
+		"chat_id": chatID.String(),
+		"user_id": actor.ID.String(),
+	})
+	if err != nil {
+		log.Printf("Failed to broadcast request accept: %v", err)
+	}
+	return nil
+}
+
+// DeleteChatRequest isteği kullanıcının kutusundan kaldırır. Gönderen tekrar
+// yazarsa sohbet yine istekler kutusunda görünür.
+func (s *ChatService) DeleteChatRequest(actor *models.User, chatID uuid.UUID) error {
+	if _, err := s.requestSender(chatID, actor.ID); err != nil {
+		return err
+	}
+	return s.HideChat(actor, chatID)
+}
+
+// BlockChatRequest isteği gönderen kişiyi engeller ve isteği siler
+func (s *ChatService) BlockChatRequest(ctx context.Context, actor *models.User, chatID uuid.UUID) error {
+	sender, err := s.requestSender(chatID, actor.ID)
+	if err != nil {
+		return err
+	}
+
+	// UserService.Block bir toggle; zaten engelliyse tekrar çağırmıyoruz
+	alreadyBlocked, err := s.userRepo.GetEngagementRepository().HasUserEngaged(ctx, actor.ID, sender.ID, models.EngagementKindBlocking)
+	if err != nil {
+		return err
+	}
+	if !alreadyBlocked {
+		if _, err := s.userService.Block(ctx, *actor, actor.PublicID, sender.PublicID); err != nil {
+			return err
+		}
+	}
+	return s.HideChat(actor, chatID)
+}
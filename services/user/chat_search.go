@@ -0,0 +1,57 @@
+package services
+
+import (
+	"coolvibes/types"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrEmptySearchQuery = errors.New("search query is empty")
+
+// SearchMessages kullanıcının kendi sohbetlerinde mesaj arar
+func (s *ChatService) SearchMessages(userID uuid.UUID, search types.MessageSearch) (types.MessageSearchResult, error) {
+	search.Query = strings.TrimSpace(search.Query)
+	if search.Query == "" {
+		return types.MessageSearchResult{}, ErrEmptySearchQuery
+	}
+
+	if search.ChatID != nil {
+		isParticipant, err := s.chatRepo.IsParticipant(*search.ChatID, userID)
+		if err != nil {
+			return types.MessageSearchResult{}, err
+		}
+		if !isParticipant {
+			return types.MessageSearchResult{}, ErrNotChatParticipant
+		}
+	}
+
+	if search.Limit <= 0 {
+		search.Limit = defaultSearchPageSize
+	}
+	search.Limit = min(search.Limit, maxMessagePageSize)
+
+	result, err := s.chatRepo.SearchMessages(userID, search)
+	if err != nil {
+		return types.MessageSearchResult{}, err
+	}
+
+	// Kabul edilmemiş mesaj isteklerindeki ve tek seferlik ekler aramada da gizli kalır
+	requestChats := map[uuid.UUID]bool{}
+	for i := range result.Hits {
+		message := &result.Hits[i].Message
+		if message.ContentableID == nil {
+			continue
+		}
+		chatID := *message.ContentableID
+		isRequest, ok := requestChats[chatID]
+		if !ok {
+			participant, err := s.chatRepo.GetParticipant(chatID, userID)
+			isRequest = err == nil && participant.IsRequest
+			requestChats[chatID] = isRequest
+		}
+		redactMessage(message, userID, isRequest)
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"coolvibes/constants"
+	"coolvibes/models"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Aynı anda sabitlenebilecek en fazla sohbet sayısı
+const maxPinnedChats = 5
+
+var (
+	ErrInvalidMuteUntil   = errors.New("mute time must be in the future")
+	ErrTooManyPinnedChats = errors.New("pinned chat limit reached")
+)
+
+// MuteChat sohbeti until zamanına kadar sessize alır; until nil ise süresiz.
+// Sessizdeki sohbetler için push bildirimi gönderilmez.
+func (s *ChatService) MuteChat(actor *models.User, chatID uuid.UUID, until *time.Time) error {
+	if until != nil && !until.After(time.Now()) {
+		return ErrInvalidMuteUntil
+	}
+	return s.updateChatSettings(actor, chatID, constants.CMD_CHAT_MUTE, func() error {
+		return s.chatRepo.SetMuted(chatID, actor.ID, true, until)
+	})
+}
+
+func (s *ChatService) UnmuteChat(actor *models.User, chatID uuid.UUID) error {
+	return s.updateChatSettings(actor, chatID, constants.CMD_CHAT_UNMUTE, func() error {
+		return s.chatRepo.SetMuted(chatID, actor.ID, false, nil)
+	})
+}
+
+// ArchiveChat sohbeti arşive taşır; arşivdeki sohbetler chat.fetch_chats
+// listesinde sadece archived=true ile gelir ve bildirim göndermez
+func (s *ChatService) ArchiveChat(actor *models.User, chatID uuid.UUID) error {
+	return s.updateChatSettings(actor, chatID, constants.CMD_CHAT_ARCHIVE, func() error {
+		return s.chatRepo.SetArchived(chatID, actor.ID, true)
+	})
+}
+
+func (s *ChatService) UnarchiveChat(actor *models.User, chatID uuid.UUID) error {
+	return s.updateChatSettings(actor, chatID, constants.CMD_CHAT_UNARCHIVE, func() error {
+		return s.chatRepo.SetArchived(chatID, actor.ID, false)
+	})
+}
+
+// PinChat sohbeti listenin başına sabitler
+func (s *ChatService) PinChat(actor *models.User, chatID uuid.UUID) error {
+	return s.updateChatSettings(actor, chatID, constants.CMD_CHAT_PIN, func() error {
+		participant, err := s.chatRepo.GetParticipant(chatID, actor.ID)
+		if err != nil {
+			return err
+		}
+		if participant.PinnedAt != nil {
+			return nil
+		}
+
+		count, err := s.chatRepo.CountPinnedChats(actor.ID)
+		if err != nil {
+			return err
+		}
+		if count >= maxPinnedChats {
+			return ErrTooManyPinnedChats
+		}
+		return s.chatRepo.SetPinned(chatID, actor.ID, true)
+	})
+}
+
+func (s *ChatService) UnpinChat(actor *models.User, chatID uuid.UUID) error {
+	return s.updateChatSettings(actor, chatID, constants.CMD_CHAT_UNPIN, func() error {
+		return s.chatRepo.SetPinned(chatID, actor.ID, false)
+	})
+}
+
+// updateChatSettings kullanıcıya özel sohbet ayarını günceller ve kullanıcının
+// diğer cihazlarına güncel katılımcı kaydını gönderir
+func (s *ChatService) updateChatSettings(actor *models.User, chatID uuid.UUID, action string, update func() error) error {
+	if _, err := s.chatRepo.GetParticipant(chatID, actor.ID); err != nil {
+		return ErrNotChatParticipant
+	}
+	if err := update(); err != nil {
+		return err
+	}
+
+	participant, err := s.chatRepo.GetParticipant(chatID, actor.ID)
+	if err != nil {
+		return err
+	}
+	err = s.socketService.EmitToUser(actor.ID, "chat", map[string]interface{}{
+		"action":      action,
+		"chat_id":     chatID.String(),
+		"participant": participant,
+	})
+	if err != nil {
+		log.Printf("Failed to emit chat settings: %v", err)
+	}
+	return nil
+}
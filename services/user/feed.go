@@ -0,0 +1,302 @@
+package services
+
+import (
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/services/ranking"
+	"coolvibes/types"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Bu sayıdan fazla hesabı takip eden kullanıcıların akışı önceden hesaplanır
+	precomputedFeedThreshold = 300
+	// Önceden hesaplanan akışta tutulan en fazla post
+	precomputedFeedSize = 1000
+
+	// "Senin İçin" aday üretimi
+	forYouCandidateWindow = 7 * 24 * time.Hour
+	forYouVelocityWindow  = 6 * time.Hour
+	forYouAffinityWindow  = 90 * 24 * time.Hour
+	forYouPoolSize        = 200
+	// Sıralanmış "Senin İçin" listesi bu süre boyunca sayfalanabilir
+	forYouSnapshotTTL = time.Hour
+	// Akış kararı bu süre boyunca yeniden kontrol edilmez; takip
+	// değişikliklerinde hemen yenilenir
+	feedModeTTL = 10 * time.Minute
+)
+
+var ErrInvalidFeedCursor = errors.New("invalid feed cursor")
+
+// GetFollowingTimeline kullanıcının kendi postları, takip ettiklerinin ve
+// takip ettiği etiketlerin postlarından oluşan akış. Engellenen ve sessize
+// alınan yazarlar gösterilmez.
+func (s *PostService) GetFollowingTimeline(userID uuid.UUID, limit int, cursor *int64) (types.TimelineResult, error) {
+	if !s.usePrecomputedFeed(userID) {
+		return s.feedRepo.GetFollowingTimeline(userID, limit, cursor)
+	}
+
+	result, err := s.feedRepo.GetPrecomputedTimeline(userID, limit, cursor)
+	if err != nil {
+		return types.TimelineResult{}, err
+	}
+
+	// Önceden hesaplanan pencere bittiyse daha eski postlar okuma anında hesaplanır
+	if len(result.Posts) < limit {
+		next := cursor
+		if len(result.Posts) > 0 {
+			last := result.Posts[len(result.Posts)-1].PublicID
+			next = &last
+		}
+		older, err := s.feedRepo.GetFollowingTimeline(userID, limit-len(result.Posts), next)
+		if err != nil {
+			return types.TimelineResult{}, err
+		}
+		result.Posts = append(result.Posts, older.Posts...)
+		result.NextCursor = nil
+		if older.NextCursor != nil {
+			c := strconv.FormatInt(result.Posts[len(result.Posts)-1].PublicID, 10)
+			result.NextCursor = &c
+		}
+	}
+	return result, nil
+}
+
+// feedModes kullanıcı başına akışın önceden hesaplanıp hesaplanmadığı kararı.
+// Karar feedModeTTL boyunca istek yolunda veritabanına gitmeden kullanılır.
+type feedModes struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]feedMode
+}
+
+type feedMode struct {
+	precomputed bool
+	checkedAt   time.Time
+	refreshing  bool
+}
+
+// lookup kararı döndürür. Karar yoksa ya da eskidiyse yenilemeyi çağırana
+// bırakır (refresh true); aynı anda tek yenileme çalışır.
+func (m *feedModes) lookup(userID uuid.UUID, now time.Time) (precomputed bool, refresh bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := m.entries[userID]
+	if entry.refreshing || (!entry.checkedAt.IsZero() && now.Sub(entry.checkedAt) < feedModeTTL) {
+		return entry.precomputed, false
+	}
+	if m.entries == nil {
+		m.entries = make(map[uuid.UUID]feedMode)
+	}
+	entry.refreshing = true
+	m.entries[userID] = entry
+	return entry.precomputed, true
+}
+
+// invalidate kararı eskimiş sayar; bir sonraki istek yeniler
+func (m *feedModes) invalidate(userID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry, ok := m.entries[userID]; ok {
+		entry.checkedAt = time.Time{}
+		m.entries[userID] = entry
+	}
+}
+
+func (m *feedModes) set(userID uuid.UUID, precomputed bool, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil {
+		m.entries = make(map[uuid.UUID]feedMode)
+	}
+	m.entries[userID] = feedMode{precomputed: precomputed, checkedAt: now}
+}
+
+// usePrecomputedFeed önbellekteki kararı döndürür. Karar yoksa ya da
+// eskidiyse akış arka planda hazırlanır; hazır olana kadar akış okuma anında
+// hesaplanır.
+func (s *PostService) usePrecomputedFeed(userID uuid.UUID) bool {
+	precomputed, refresh := s.feedModes.lookup(userID, time.Now())
+	if refresh {
+		go s.refreshFeed(userID)
+	}
+	return precomputed
+}
+
+// RefreshFeed takip edilen hesaplar ya da etiketler değişince akışı arka
+// planda yeniden değerlendirir
+func (s *PostService) RefreshFeed(userID uuid.UUID) {
+	s.feedModes.invalidate(userID)
+	s.usePrecomputedFeed(userID)
+}
+
+func (s *PostService) refreshFeed(userID uuid.UUID) {
+	precomputed, err := s.ensureFeed(userID)
+	if err != nil {
+		log.Printf("Failed to prepare precomputed feed for %s: %v", userID, err)
+		precomputed = false
+	}
+	s.feedModes.set(userID, precomputed, time.Now())
+}
+
+// ensureFeed kullanıcının akışının önceden hesaplanıp hesaplanmayacağına karar
+// verir; gerekiyorsa akışı oluşturur, kaldırır ya da kırpar
+func (s *PostService) ensureFeed(userID uuid.UUID) (bool, error) {
+	following, err := s.feedRepo.CountFollowing(userID)
+	if err != nil {
+		return false, err
+	}
+	state, err := s.feedRepo.GetFeedState(userID)
+	if err != nil {
+		return false, err
+	}
+
+	if following < precomputedFeedThreshold {
+		if state != nil {
+			return false, s.feedRepo.DropFeed(userID)
+		}
+		return false, nil
+	}
+
+	if state != nil {
+		// Akış oluşturulduktan sonra takip edilen hesapların eski postları
+		// akışta yok; yeniden oluştur. Takipten çıkılan yazarlar okuma anında
+		// elendiğinden yeniden oluşturmayı gerektirmez.
+		lastFollow, err := s.feedRepo.LastFollowAt(userID)
+		if err != nil {
+			return false, err
+		}
+		if lastFollow == nil || !lastFollow.After(state.BuiltAt) {
+			// Fan-out ile büyüyen akış kırpılır
+			return true, s.feedRepo.TrimFeed(userID, precomputedFeedSize)
+		}
+	}
+
+	if err := s.feedRepo.BuildFeed(userID, precomputedFeedSize); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fanOutPost yeni ana postu önceden hesaplanan akışlara yazar
+func (s *PostService) fanOutPost(p *post.Post) {
+	if p.ParentID != nil {
+		return
+	}
+	if err := s.feedRepo.FanOutPost(p); err != nil {
+		log.Printf("Failed to fan out post %s: %v", p.ID, err)
+	}
+}
+
+// GetForYouTimeline aday postları FeedRanker ile sıralar. İlk sayfada sıralanan
+// liste saklanır ve cursor bu listeyi ve sıradaki konumu taşır; böylece sonraki
+// sayfalar yeniden sıralamadan etkilenmez, post tekrarlanmaz veya atlanmaz.
+// Süresi dolmuş listenin cursor'ı ErrInvalidFeedCursor döner, istemci akışı baştan alır.
+func (s *PostService) GetForYouTimeline(viewer *models.User, limit int, cursor string) (types.TimelineResult, error) {
+	var (
+		ids        []uuid.UUID
+		snapshotID uuid.UUID
+		offset     int
+	)
+	if cursor != "" {
+		var err error
+		if snapshotID, offset, err = DecodeForYouCursor(cursor); err != nil {
+			return types.TimelineResult{}, err
+		}
+		snapshot, err := s.feedRepo.GetForYouSnapshot(snapshotID, viewer.ID)
+		if err != nil {
+			return types.TimelineResult{}, err
+		}
+		if snapshot == nil || time.Since(snapshot.CreatedAt) > forYouSnapshotTTL {
+			return types.TimelineResult{}, ErrInvalidFeedCursor
+		}
+		for _, id := range snapshot.PostIDs {
+			if parsed, err := uuid.Parse(id); err == nil {
+				ids = append(ids, parsed)
+			}
+		}
+	} else {
+		now := time.Now()
+		candidates, err := s.feedRepo.GetForYouCandidates(viewer.ID, types.FeedCandidateQuery{
+			Since:         now.Add(-forYouCandidateWindow),
+			PoolSize:      forYouPoolSize,
+			VelocitySince: now.Add(-forYouVelocityWindow),
+			AffinitySince: now.Add(-forYouAffinityWindow),
+		})
+		if err != nil {
+			return types.TimelineResult{}, err
+		}
+
+		ranked := s.ranker.Rank(ranking.Viewer{UserID: viewer.ID, Language: viewer.DefaultLanguage}, candidates, now)
+		ids = make([]uuid.UUID, len(ranked))
+		for i, c := range ranked {
+			ids[i] = c.PostID
+		}
+		// Tek sayfaya sığan liste saklanmaz
+		if len(ids) > limit {
+			snapshot, err := s.feedRepo.SaveForYouSnapshot(viewer.ID, ids, forYouSnapshotTTL)
+			if err != nil {
+				return types.TimelineResult{}, err
+			}
+			snapshotID = snapshot.ID
+		}
+	}
+
+	if offset >= len(ids) {
+		return types.TimelineResult{Posts: []post.Post{}}, nil
+	}
+	page := ids[offset:min(offset+limit, len(ids))]
+
+	posts, err := s.postRepo.GetTimelinePostsByIDs(page, &viewer.ID)
+	if err != nil {
+		return types.TimelineResult{}, err
+	}
+
+	// GetTimelinePostsByIDs yeniden eskiye döner; sıralayıcının sırasına getir
+	byID := make(map[uuid.UUID]post.Post, len(posts))
+	for _, p := range posts {
+		byID[p.ID] = p
+	}
+	ordered := make([]post.Post, 0, len(page))
+	for _, id := range page {
+		if p, ok := byID[id]; ok {
+			ordered = append(ordered, p)
+		}
+	}
+
+	result := types.TimelineResult{Posts: ordered}
+	if next := offset + len(page); next < len(ids) {
+		c := EncodeForYouCursor(snapshotID, next)
+		result.NextCursor = &c
+	}
+	return result, nil
+}
+
+// EncodeForYouCursor sıralanmış liste ve sıradaki konumdan cursor üretir
+func EncodeForYouCursor(snapshotID uuid.UUID, offset int) string {
+	return fmt.Sprintf("%s:%d", snapshotID, offset)
+}
+
+// DecodeForYouCursor EncodeForYouCursor'ın ürettiği cursor'ı çözer
+func DecodeForYouCursor(cursor string) (uuid.UUID, int, error) {
+	parts := strings.SplitN(cursor, ":", 2)
+	if len(parts) != 2 {
+		return uuid.Nil, 0, ErrInvalidFeedCursor
+	}
+	snapshotID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, 0, ErrInvalidFeedCursor
+	}
+	offset, err := strconv.Atoi(parts[1])
+	if err != nil || offset < 0 {
+		return uuid.Nil, 0, ErrInvalidFeedCursor
+	}
+	return snapshotID, offset, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/types"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidHashtag = errors.New("invalid hashtag")
+
+// GetHashtagPage etiketin izleyiciye açık postlarını ve ilk sayfada etiket
+// bilgilerini döndürür
+func (s *PostService) GetHashtagPage(rawTag string, limit int, cursor *int64, viewerID *uuid.UUID) (*types.HashtagPage, error) {
+	tag, ok := helpers.NormalizeHashtag(rawTag)
+	if !ok {
+		return nil, ErrInvalidHashtag
+	}
+
+	posts, err := s.postRepo.GetHashtagPosts(tag, limit, cursor, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	page := &types.HashtagPage{TimelineResult: posts}
+	if cursor != nil {
+		return page, nil
+	}
+
+	info, err := s.postRepo.GetHashtagInfo(tag)
+	if err != nil {
+		return nil, err
+	}
+	if viewerID != nil {
+		if info.Following, err = s.postRepo.IsFollowingHashtag(*viewerID, tag); err != nil {
+			return nil, err
+		}
+	}
+	page.Hashtag = &info
+	return page, nil
+}
+
+// FollowHashtag etiketi takip eder; etiketin normalize edilmiş halini döndürür
+func (s *PostService) FollowHashtag(user *models.User, rawTag string) (string, error) {
+	tag, ok := helpers.NormalizeHashtag(rawTag)
+	if !ok {
+		return "", ErrInvalidHashtag
+	}
+	if err := s.postRepo.FollowHashtag(user.ID, tag); err != nil {
+		return "", err
+	}
+	s.RefreshFeed(user.ID)
+	return tag, nil
+}
+
+func (s *PostService) UnfollowHashtag(user *models.User, rawTag string) (string, error) {
+	tag, ok := helpers.NormalizeHashtag(rawTag)
+	if !ok {
+		return "", ErrInvalidHashtag
+	}
+	if err := s.postRepo.UnfollowHashtag(user.ID, tag); err != nil {
+		return "", err
+	}
+	s.RefreshFeed(user.ID)
+	return tag, nil
+}
+
+func (s *PostService) GetFollowedHashtags(user *models.User) ([]models.HashtagFollow, error) {
+	return s.postRepo.GetFollowedHashtags(user.ID)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"coolvibes/constants"
+	"coolvibes/models/chat"
+	"coolvibes/models/post"
+	"coolvibes/repositories"
+	"coolvibes/services/unfurl"
+	"errors"
+	"log"
+	"sort"
+)
+
+// firstLink içeriğin (tüm dillerdeki) ilk bağlantısı
+func firstLink(p *post.Post) string {
+	if p.Content == nil {
+		return ""
+	}
+	langs := make([]string, 0, len(*p.Content))
+	for lang := range *p.Content {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	for _, lang := range langs {
+		if link := unfurl.FirstURL((*p.Content)[lang]); link != "" {
+			return link
+		}
+	}
+	return ""
+}
+
+// storeLinkPreview içerikteki ilk bağlantının önizlemesini Extras'a yazar.
+// Bağlantı yoksa, önizleme alınamazsa veya post bu arada silinip
+// düzenlendiyse nil döner.
+func storeLinkPreview(unfurler *unfurl.Unfurler, postRepo *repositories.PostRepository, p *post.Post) *unfurl.Preview {
+	if unfurler == nil {
+		return nil
+	}
+	link := firstLink(p)
+	if link == "" {
+		return nil
+	}
+
+	preview, err := unfurler.Unfurl(context.Background(), link)
+	if err != nil {
+		if !errors.Is(err, unfurl.ErrNoMetadata) && !errors.Is(err, unfurl.ErrNotHTML) {
+			log.Printf("Link preview failed for %s: %v", link, err)
+		}
+		return nil
+	}
+
+	stored, err := postRepo.SetLinkPreview(p, preview)
+	if err != nil {
+		log.Printf("Failed to store link preview: %v", err)
+		return nil
+	}
+	if !stored {
+		return nil
+	}
+	return preview
+}
+
+// unfurlMessage mesajdaki bağlantının önizlemesini hazırlar ve katılımcılara
+// sadece önizleme alanını gönderir
+func (s *ChatService) unfurlMessage(message *post.Post) {
+	if chat.IsSystemMessage(message) || message.ContentableID == nil {
+		return
+	}
+	preview := storeLinkPreview(s.unfurler, s.postRepo, message)
+	if preview == nil {
+		return
+	}
+
+	err := s.socketService.BroadcastToChat(*message.ContentableID, "chat", map[string]interface{}{
+		"action":       constants.CMD_MESSAGE_UPDATED,
+		"chat_id":      message.ContentableID.String(),
+		"message_id":   message.ID.String(),
+		"link_preview": preview,
+	})
+	if err != nil {
+		log.Printf("Failed to broadcast link preview: %v", err)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"coolvibes/models/post"
 
 	"coolvibes/repositories"
+	"coolvibes/services/ranking"
+	"coolvibes/services/unfurl"
 	"coolvibes/types"
 	"fmt"
 	"mime/multipart"
@@ -13,99 +15,126 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrPostNotFound     = repositories.ErrPostNotFound
+	ErrInvalidAudience  = repositories.ErrInvalidAudience
+	ErrRepostNotAllowed = repositories.ErrRepostNotAllowed
+)
+
 type PostService struct {
-	mediaRepo *repositories.MediaRepository
-	userRepo  *repositories.UserRepository
-	postRepo  *repositories.PostRepository
+	mediaRepo        *repositories.MediaRepository
+	userRepo         *repositories.UserRepository
+	postRepo         *repositories.PostRepository
+	feedRepo         *repositories.FeedRepository
+	notificationRepo *repositories.NotificationRepository
+	ranker           ranking.FeedRanker
+	unfurler         *unfurl.Unfurler
+	feedModes        feedModes
 }
 
 func NewPostService(
 	userRepo *repositories.UserRepository,
 	postRepo *repositories.PostRepository,
-	mediaRepo *repositories.MediaRepository) *PostService {
-	return &PostService{postRepo: postRepo, mediaRepo: mediaRepo, userRepo: userRepo}
+	mediaRepo *repositories.MediaRepository,
+	feedRepo *repositories.FeedRepository,
+	notificationRepo *repositories.NotificationRepository,
+	ranker ranking.FeedRanker,
+	unfurler *unfurl.Unfurler) *PostService {
+	s := &PostService{postRepo: postRepo, mediaRepo: mediaRepo, userRepo: userRepo, feedRepo: feedRepo, notificationRepo: notificationRepo, ranker: ranker, unfurler: unfurler}
+	go s.runPostScheduler(postSchedulerInterval)
+	go s.runTrendRollup(trendRollupInterval)
+	return s
 }
 
 func (s *PostService) CreatePost(request map[string][]string, files []*multipart.FileHeader, author *models.User) (*post.Post, error) {
+	if values := request["status"]; len(values) > 0 {
+		if err := s.checkScheduleLimit(author, values[0]); err != nil {
+			return nil, err
+		}
+	}
 	_post, err := s.postRepo.CreateContentablePost(request, files, author, "post", nil)
 	if err != nil {
 		return nil, err
 	}
-	return s.GetPostByID(_post.ID)
+
+	// Önizleme ve akışlara dağıtım arka planda yapılır; post oluşturmayı bekletmez
+	go storeLinkPreview(s.unfurler, s.postRepo, _post)
+	if !_post.Published {
+		// Akış ve bildirimler yayın anında
+		return s.getOwnPost(_post.ID, author)
+	}
+	go s.fanOutPost(_post)
+	go s.notifyMentions(author, _post, mentionUserIDs(_post))
+	go s.notifyRepost(author, _post)
+	return s.GetPostByID(_post.ID, &author.ID)
 }
 
-func (s *PostService) GetPostByID(id uuid.UUID) (*post.Post, error) {
-	postData, err := s.postRepo.GetPostByID(id)
+// Okuma fonksiyonlarında viewerID nil ise izleyici anonimdir; postlar
+// kitlesine (audience) göre filtrelenir
+func (s *PostService) GetPostByID(id uuid.UUID, viewerID *uuid.UUID) (*post.Post, error) {
+	postData, err := s.postRepo.GetVisiblePostByID(id, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("GetPostByID error: %w", err)
 	}
 	return postData, nil
 }
 
-func (s *PostService) GetPostByPublicID(id int64) (*post.Post, error) {
-	postData, err := s.postRepo.GetPostByPublicID(id)
+func (s *PostService) GetPostByPublicID(id int64, viewerID *uuid.UUID) (*post.Post, error) {
+	postData, err := s.postRepo.GetPostByPublicID(id, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("GetPostByID error: %w", err)
 	}
 	return postData, nil
 }
 
-func (s *PostService) GetTimeline(limit int, cursor *int64) (types.TimelineResult, error) {
+func (s *PostService) GetTimeline(limit int, cursor *int64, viewerID *uuid.UUID) (types.TimelineResult, error) {
 	// Repo fonksiyonunu çağırıyoruz
-	posts, err := s.postRepo.GetTimeline(limit, cursor)
+	posts, err := s.postRepo.GetTimeline(limit, cursor, viewerID)
 	if err != nil {
 		return types.TimelineResult{}, err
 	}
 	return posts, nil
 }
 
-func (s *PostService) GetPostsByUserID(id int64, limit int, cursor *int64) ([]post.Post, error) {
+func (s *PostService) GetPostsByUserID(id int64, limit int, cursor *int64, viewerID *uuid.UUID) ([]post.Post, error) {
 	userId, err := s.userRepo.GetUserUUIDByPublicID(id)
 	if err != nil {
 		return nil, fmt.Errorf("GetUserUUIDByPublicID error: %w", err)
 	}
-	posts, err := s.postRepo.GetUserPosts(userId, cursor, limit)
+	posts, err := s.postRepo.GetUserPosts(userId, cursor, limit, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("GetPostByID error: %w", err)
 	}
 	return posts, nil
 }
 
-func (s *PostService) GetUserPostReplies(id int64, limit int, cursor *int64) ([]post.Post, error) {
+func (s *PostService) GetUserPostReplies(id int64, limit int, cursor *int64, viewerID *uuid.UUID) ([]post.Post, error) {
 	userId, err := s.userRepo.GetUserUUIDByPublicID(id)
 	if err != nil {
 		return nil, fmt.Errorf("GetUserUUIDByPublicID error: %w", err)
 	}
-	posts, err := s.postRepo.GetUserPostReplies(userId, cursor, limit)
+	posts, err := s.postRepo.GetUserPostReplies(userId, cursor, limit, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("GetPostByID error: %w", err)
 	}
 	return posts, nil
 }
 
-func (s *PostService) GetUserMedias(id int64, limit int, cursor *int64) ([]types.MediaWithUser, *int64, error) {
+func (s *PostService) GetUserMedias(id int64, limit int, cursor *int64, viewerID *uuid.UUID) ([]types.MediaWithUser, *int64, error) {
 	userId, err := s.userRepo.GetUserUUIDByPublicID(id)
 	if err != nil {
 		return nil, nil, fmt.Errorf("GetUserUUIDByPublicID error: %w", err)
 	}
-	medias, lastCursor, err := s.postRepo.GetUserMedias(userId, cursor, limit)
+	medias, lastCursor, err := s.postRepo.GetUserMedias(userId, cursor, limit, viewerID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("GetUserMedias error: %w", err)
 	}
 	return medias, lastCursor, nil
 }
 
-func (s *PostService) GetRecentHashtags(limit int) ([]types.HashtagStats, error) {
-	hashtags, err := s.postRepo.GetRecentHashtags(limit)
-	if err != nil {
-		return nil, fmt.Errorf("GetRecentHashtags error: %w", err)
-	}
-	return hashtags, nil
-}
-
-func (s *PostService) GetTimelineVibes(limit int, cursor *int64) (types.TimelineResult, error) {
+func (s *PostService) GetTimelineVibes(limit int, cursor *int64, viewerID *uuid.UUID) (types.TimelineResult, error) {
 	// Repo fonksiyonunu çağırıyoruz
-	posts, err := s.postRepo.GetTimelineVibes(limit, cursor)
+	posts, err := s.postRepo.GetTimelineVibes(limit, cursor, viewerID)
 	if err != nil {
 		return types.TimelineResult{}, err
 	}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"coolvibes/constants"
+	"coolvibes/models"
+	"coolvibes/models/notifications"
+	"coolvibes/models/post"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Post yayınlandıktan sonra bu süre boyunca düzenlenebilir
+const postEditWindow = time.Hour
+
+var (
+	ErrNotPostAuthor    = errors.New("only the author can edit this post")
+	ErrEditWindowClosed = errors.New("post edit window has closed")
+	ErrDeleteForbidden  = errors.New("only the author or a moderator can delete this post")
+)
+
+// UpdatePost yazarın postunu düzenleme süresi içinde günceller; yeni
+// mention edilen kullanıcılara bildirim gönderilir. Taslak ve zamanlanmış
+// postlar süre sınırı olmadan düzenlenir.
+func (s *PostService) UpdatePost(editor *models.User, publicID int64, request map[string][]string, files []*multipart.FileHeader) (*post.Post, error) {
+	existing, err := s.postRepo.FindPostByPublicID(publicID)
+	// İçeriksiz repostlar düzenlenemez; geri alınabilir
+	if err != nil || existing.ContentableType == nil || *existing.ContentableType != string(post.PostTypePost) || existing.PostKind == post.PostTypeRepost {
+		return nil, ErrPostNotFound
+	}
+	if existing.AuthorID != editor.ID {
+		return nil, ErrNotPostAuthor
+	}
+	// Yayınlanmamış postlarda düzenleme süresi ve revizyon yoktur
+	if !existing.Published {
+		return s.updateUnpublishedPost(editor, existing, request, files)
+	}
+	if time.Since(existing.CreatedAt) > postEditWindow {
+		return nil, ErrEditWindowClosed
+	}
+
+	added, err := s.postRepo.UpdateContentablePost(existing, request, files, editor)
+	if err != nil {
+		return nil, err
+	}
+
+	// İçerik değiştiyse bağlantı önizlemesi yenilenir; bağlantı kalmadıysa silinir
+	if _, ok := request["content"]; ok {
+		if firstLink(existing) == "" {
+			if err := s.postRepo.DeletePostExtra(existing.ID, post.ExtraLinkPreview); err != nil {
+				log.Printf("Failed to remove link preview: %v", err)
+			}
+		} else {
+			go storeLinkPreview(s.unfurler, s.postRepo, existing)
+		}
+	}
+	go s.notifyMentions(editor, existing, added)
+	return s.GetPostByID(existing.ID, &editor.ID)
+}
+
+// DeletePost postu yazarı ya da bir moderatör siler; yanıtlar da tombstone olur
+func (s *PostService) DeletePost(actor *models.User, publicID int64) error {
+	existing, err := s.postRepo.FindPostByPublicID(publicID)
+	if err != nil || existing.ContentableType == nil || *existing.ContentableType != string(post.PostTypePost) {
+		return ErrPostNotFound
+	}
+	if existing.AuthorID != actor.ID && !isModerator(actor) {
+		return ErrDeleteForbidden
+	}
+	_, err = s.postRepo.DeletePost(existing, actor.ID)
+	return err
+}
+
+func isModerator(u *models.User) bool {
+	switch u.UserRole {
+	case constants.UserRoleModerator, constants.UserRoleAdmin, constants.UserRoleSuperAdmin:
+		return true
+	}
+	return false
+}
+
+// GetPostRevisions postun düzenleme geçmişi; post izleyiciye kapalıysa bulunamadı döner
+func (s *PostService) GetPostRevisions(publicID int64, viewerID *uuid.UUID) ([]post.PostRevision, error) {
+	p, err := s.postRepo.FindPostByPublicID(publicID)
+	if err != nil {
+		return nil, ErrPostNotFound
+	}
+	visible, err := s.postRepo.CanViewPost(p.ID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrPostNotFound
+	}
+	return s.postRepo.GetPostRevisions(p.ID)
+}
+
+// notifyMentions postu görebilen mention edilen kullanıcılara bildirim gönderir
+func (s *PostService) notifyMentions(author *models.User, p *post.Post, userIDs []uuid.UUID) {
+	for _, userID := range userIDs {
+		if userID == author.ID {
+			continue
+		}
+		if visible, err := s.postRepo.CanViewPost(p.ID, &userID); err != nil || !visible {
+			continue
+		}
+		receiver, err := s.userRepo.GetUserByUUIDdWithoutRelations(userID)
+		if err != nil {
+			continue
+		}
+
+		title := "New Mention"
+		text := fmt.Sprintf("%s mentioned you in a post.", displayName(author))
+		payload := notifications.NotificationPayload{
+			Title: title,
+			Body:  text,
+			Data: map[string]interface{}{
+				"post_id": strconv.FormatInt(p.PublicID, 10),
+			},
+		}
+		if err := s.notificationRepo.SendNotificationToUser(*author, *receiver, notifications.NotificationTypeMention, title, text, payload); err != nil {
+			log.Printf("Failed to send mention notification: %v", err)
+		}
+	}
+}
+
+func mentionUserIDs(p *post.Post) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(p.Mentions))
+	for _, m := range p.Mentions {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
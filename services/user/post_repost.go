@@ -0,0 +1,79 @@
+package services
+
+import (
+	"coolvibes/models"
+	"coolvibes/models/notifications"
+	"coolvibes/models/post"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// Repost postu kullanıcının akışında yeniden paylaşır; daha önce paylaşılmışsa
+// repost geri alınır ve nil döner
+func (s *PostService) Repost(user *models.User, publicID int64) (*post.Post, error) {
+	original, err := s.postRepo.ResolveRepostTarget(publicID)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := s.postRepo.CanViewPost(original.ID, &user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrPostNotFound
+	}
+
+	existing, err := s.postRepo.FindRepost(original.ID, user.ID)
+	if err == nil {
+		_, err = s.postRepo.DeletePost(existing, user.ID)
+		return nil, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	repost, err := s.postRepo.CreateRepost(original, user)
+	if err != nil {
+		return nil, err
+	}
+	go s.fanOutPost(repost)
+	go s.notifyRepost(user, repost)
+	return s.GetPostByID(repost.ID, &user.ID)
+}
+
+// notifyRepost orijinal postun yazarına repost ya da alıntı bildirimi gönderir
+func (s *PostService) notifyRepost(actor *models.User, p *post.Post) {
+	if p.RepostOfID == nil {
+		return
+	}
+	original, err := s.postRepo.FindPostByID(*p.RepostOfID)
+	if err != nil || original.AuthorID == actor.ID {
+		return
+	}
+	receiver, err := s.userRepo.GetUserByUUIDdWithoutRelations(original.AuthorID)
+	if err != nil {
+		return
+	}
+
+	notificationType, title := notifications.NotificationTypeQuote, "New Quote"
+	text := fmt.Sprintf("%s quoted your post.", displayName(actor))
+	if p.PostKind == post.PostTypeRepost {
+		notificationType, title = notifications.NotificationTypeRepost, "New Repost"
+		text = fmt.Sprintf("%s reposted your post.", displayName(actor))
+	}
+	payload := notifications.NotificationPayload{
+		Title: title,
+		Body:  text,
+		Data: map[string]interface{}{
+			"post_id":   strconv.FormatInt(original.PublicID, 10),
+			"repost_id": strconv.FormatInt(p.PublicID, 10),
+		},
+	}
+	if err := s.notificationRepo.SendNotificationToUser(*actor, *receiver, notificationType, title, text, payload); err != nil {
+		log.Printf("Failed to send repost notification: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/repositories"
+	"coolvibes/types"
+	"errors"
+	"log"
+	"mime/multipart"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	postSchedulerInterval = 30 * time.Second
+	postSchedulerBatch    = 100
+
+	// Bir kullanıcının aynı anda bekleyen en fazla zamanlanmış postu
+	maxScheduledPosts = 100
+)
+
+var (
+	ErrInvalidSchedule       = repositories.ErrInvalidSchedule
+	ErrPostAlreadyPublished  = repositories.ErrPostAlreadyPublished
+	ErrTooManyScheduledPosts = errors.New("too many scheduled posts")
+	ErrPostNotScheduled      = errors.New("post is not scheduled")
+)
+
+// checkScheduleLimit zamanlanan post sayısı sınırını kontrol eder
+func (s *PostService) checkScheduleLimit(author *models.User, status string) error {
+	if status != repositories.PostStatusScheduled {
+		return nil
+	}
+	count, err := s.postRepo.CountScheduledPosts(author.ID)
+	if err != nil {
+		return err
+	}
+	if count >= maxScheduledPosts {
+		return ErrTooManyScheduledPosts
+	}
+	return nil
+}
+
+// publishPost postu yayınlar ve yayın anına bırakılan yan etkileri çalıştırır
+func (s *PostService) publishPost(p *post.Post, author *models.User) error {
+	mentioned, err := s.postRepo.PublishPost(p)
+	if err != nil {
+		return err
+	}
+	go s.fanOutPost(p)
+	go s.notifyMentions(author, p, mentioned)
+	go s.notifyRepost(author, p)
+	return nil
+}
+
+// runPostScheduler zamanı gelen zamanlanmış postları periyodik olarak yayınlar
+func (s *PostService) runPostScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.publishDuePosts()
+	}
+}
+
+func (s *PostService) publishDuePosts() {
+	posts, err := s.postRepo.GetDueScheduledPosts(postSchedulerBatch)
+	if err != nil {
+		log.Printf("Failed to load scheduled posts: %v", err)
+		return
+	}
+
+	for i := range posts {
+		p := &posts[i]
+		author := p.Author
+		// Aynı anda başka bir örnek yayınlamış olabilir
+		if err := s.publishPost(p, &author); err != nil && !errors.Is(err, ErrPostAlreadyPublished) {
+			log.Printf("Failed to publish scheduled post %s: %v", p.ID, err)
+		}
+	}
+}
+
+// getOwnPost yayınlanmamış postu yazarına gösterir; kitle filtresi uygulanmaz
+func (s *PostService) getOwnPost(id uuid.UUID, author *models.User) (*post.Post, error) {
+	posts, err := s.postRepo.GetTimelinePostsByIDs([]uuid.UUID{id}, &author.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, ErrPostNotFound
+	}
+	return &posts[0], nil
+}
+
+// updateUnpublishedPost taslak ya da zamanlanmış postu düzenler. status
+// gönderildiyse post yayınlanır, yeniden zamanlanır ya da taslağa çevrilir.
+func (s *PostService) updateUnpublishedPost(editor *models.User, existing *post.Post, request map[string][]string, files []*multipart.FileHeader) (*post.Post, error) {
+	statusValues, statusSent := request["status"]
+	var status, scheduledAt string
+	if len(statusValues) > 0 {
+		status = statusValues[0]
+	}
+	if values := request["scheduled_at"]; len(values) > 0 {
+		scheduledAt = values[0]
+	}
+	publish, schedule := false, existing.ScheduledAt
+	if statusSent {
+		var err error
+		if publish, schedule, err = repositories.ParsePublishStatus(status, scheduledAt, time.Now()); err != nil {
+			return nil, err
+		}
+		if existing.ScheduledAt == nil {
+			if err := s.checkScheduleLimit(editor, status); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.postRepo.UpdateDraft(existing, request, files, editor); err != nil {
+		return nil, err
+	}
+	if statusSent && !publish {
+		if err := s.postRepo.SetSchedule(existing, schedule); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := request["content"]; ok {
+		go storeLinkPreview(s.unfurler, s.postRepo, existing)
+	}
+
+	if publish {
+		if err := s.publishPost(existing, editor); err != nil {
+			return nil, err
+		}
+		return s.GetPostByID(existing.ID, &editor.ID)
+	}
+	return s.getOwnPost(existing.ID, editor)
+}
+
+// CancelScheduledPost zamanlanmış postun yayınını iptal eder; post taslak olarak kalır
+func (s *PostService) CancelScheduledPost(user *models.User, publicID int64) (*post.Post, error) {
+	existing, err := s.postRepo.FindPostByPublicID(publicID)
+	if err != nil || existing.AuthorID != user.ID {
+		return nil, ErrPostNotFound
+	}
+	if existing.Published || existing.ScheduledAt == nil {
+		return nil, ErrPostNotScheduled
+	}
+	if err := s.postRepo.SetSchedule(existing, nil); err != nil {
+		return nil, err
+	}
+	return s.getOwnPost(existing.ID, user)
+}
+
+func (s *PostService) GetDrafts(user *models.User, limit int, cursor *int64) (types.TimelineResult, error) {
+	return s.postRepo.GetDrafts(user.ID, limit, cursor)
+}
+
+func (s *PostService) GetScheduledPosts(user *models.User) ([]post.Post, error) {
+	return s.postRepo.GetScheduledPosts(user.ID)
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"coolvibes/models/post"
+	"coolvibes/types"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	threadDefaultDepth = 3
+	threadMaxDepth     = 6
+	threadDefaultLimit = 10
+	threadMaxLimit     = 50
+)
+
+var ErrInvalidThreadCursor = errors.New("invalid thread cursor")
+
+// GetThread postun ata zincirini ve depth seviyeye kadar yanıt ağacını
+// döndürür. Her dalda en fazla limit yanıt gösterilir; devamı olan dallarda
+// RepliesCursor ile post.thread o post için tekrar çağrılarak sonraki sayfa alınır.
+// Cursor sıralamayı da taşır.
+func (s *PostService) GetThread(publicID int64, viewerID *uuid.UUID, sort types.ReplySort, depth int, limit int, cursor string) (*types.ThreadResult, error) {
+	if depth <= 0 {
+		depth = threadDefaultDepth
+	}
+	depth = min(depth, threadMaxDepth)
+	if limit <= 0 {
+		limit = threadDefaultLimit
+	}
+	limit = min(limit, threadMaxLimit)
+
+	var after *types.ReplyCursor
+	if cursor != "" {
+		var err error
+		if sort, after, err = decodeReplyCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// İçeriksiz repostun konuşması orijinalin konuşmasıdır
+	focal, err := s.postRepo.ResolveRepostTarget(publicID)
+	if err != nil {
+		return nil, ErrPostNotFound
+	}
+	visible, err := s.postRepo.CanViewPost(focal.ID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrPostNotFound
+	}
+	loaded, err := s.postRepo.GetTimelinePostsByIDs([]uuid.UUID{focal.ID}, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(loaded) == 0 {
+		return nil, ErrPostNotFound
+	}
+	root := loaded[0]
+
+	ancestors, err := s.postRepo.GetPostAncestors(&root, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Yanıtlar seviye seviye alınır; bir fazlası dalın devamı olup olmadığını
+	// gösterir. Son seviyenin altında yanıt olup olmadığı da yoklanır.
+	children := map[uuid.UUID][]types.ThreadReply{}
+	cursors := map[uuid.UUID]*string{}
+	parents := []uuid.UUID{root.ID}
+	var ids []uuid.UUID
+	for level := 0; level <= depth && len(parents) > 0; level++ {
+		query := types.ReplyQuery{ParentIDs: parents, Sort: sort, PerParent: limit + 1}
+		if level == 0 {
+			query.After = after
+		}
+		if level == depth {
+			query.PerParent = 1
+		}
+		replies, err := s.postRepo.GetReplies(query, viewerID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, reply := range replies {
+			children[reply.ParentID] = append(children[reply.ParentID], reply)
+		}
+		next := []uuid.UUID{}
+		for _, parentID := range parents {
+			branch := children[parentID]
+			switch {
+			case level == depth:
+				// Derinlik sınırında yanıtlar yüklenmez; dal baştan açılabilir
+				if len(branch) > 0 {
+					c := encodeReplyCursor(sort, nil)
+					cursors[parentID] = &c
+				}
+				delete(children, parentID)
+				continue
+			case len(branch) > limit:
+				branch = branch[:limit]
+				children[parentID] = branch
+				c := encodeReplyCursor(sort, &branch[len(branch)-1])
+				cursors[parentID] = &c
+			}
+			for _, reply := range branch {
+				ids = append(ids, reply.ID)
+				next = append(next, reply.ID)
+			}
+		}
+		parents = next
+	}
+
+	posts, err := s.postRepo.GetTimelinePostsByIDs(ids, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]post.Post, len(posts))
+	for _, p := range posts {
+		byID[p.ID] = p
+	}
+
+	var build func(parentID uuid.UUID) []post.Post
+	build = func(parentID uuid.UUID) []post.Post {
+		var tree []post.Post
+		for _, reply := range children[parentID] {
+			p, ok := byID[reply.ID]
+			if !ok {
+				continue
+			}
+			p.Children = build(p.ID)
+			p.RepliesCursor = cursors[p.ID]
+			tree = append(tree, p)
+		}
+		return tree
+	}
+	root.Children = build(root.ID)
+	root.RepliesCursor = cursors[root.ID]
+
+	return &types.ThreadResult{
+		Ancestors:  ancestors,
+		Post:       &root,
+		NextCursor: root.RepliesCursor,
+	}, nil
+}
+
+// ParseReplySort boş değer etkileşime göre sıralamadır
+func ParseReplySort(raw string) (types.ReplySort, bool) {
+	switch sort := types.ReplySort(raw); sort {
+	case "":
+		return types.ReplySortTop, true
+	case types.ReplySortTop, types.ReplySortNewest, types.ReplySortOldest:
+		return sort, true
+	}
+	return "", false
+}
+
+// Cursor "sıralama:skor:public_id" biçimindedir; yalnızca sıralamadan oluşan
+// cursor dalı baştan açar
+func encodeReplyCursor(sort types.ReplySort, last *types.ThreadReply) string {
+	if last == nil {
+		return string(sort)
+	}
+	return string(sort) + ":" + strconv.FormatFloat(last.Score, 'f', -1, 64) + ":" + strconv.FormatInt(last.PublicID, 10)
+}
+
+func decodeReplyCursor(cursor string) (types.ReplySort, *types.ReplyCursor, error) {
+	parts := strings.Split(cursor, ":")
+	sort, ok := ParseReplySort(parts[0])
+	if !ok || parts[0] == "" {
+		return "", nil, ErrInvalidThreadCursor
+	}
+	if len(parts) == 1 {
+		return sort, nil, nil
+	}
+	if len(parts) != 3 {
+		return "", nil, ErrInvalidThreadCursor
+	}
+	score, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return "", nil, ErrInvalidThreadCursor
+	}
+	publicID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", nil, ErrInvalidThreadCursor
+	}
+	return sort, &types.ReplyCursor{Score: score, PublicID: publicID}, nil
+}
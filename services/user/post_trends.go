@@ -0,0 +1,73 @@
+package services
+
+import (
+	"coolvibes/repositories"
+	"coolvibes/types"
+	"log"
+	"time"
+)
+
+const (
+	trendRollupInterval = 5 * time.Minute
+
+	// Özetler taban çizgisinden bir gün fazla tutulur
+	trendRetention = repositories.TrendBaseline + 24*time.Hour
+
+	defaultTrendWindow = "24h"
+)
+
+var (
+	ErrInvalidTrendScope  = repositories.ErrInvalidTrendScope
+	ErrInvalidTrendWindow = repositories.ErrInvalidTrendWindow
+)
+
+// search.trends window parametresinin alabileceği değerler
+var trendWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"6h":  6 * time.Hour,
+	"24h": 24 * time.Hour,
+}
+
+// runTrendRollup hashtag trend özetlerini periyodik olarak günceller. İlk
+// çalışmada özet yoksa taban çizgisi için geçmiş de hesaplanır.
+func (s *PostService) runTrendRollup(interval time.Duration) {
+	s.rollupTrends()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.rollupTrends()
+	}
+}
+
+func (s *PostService) rollupTrends() {
+	if err := s.postRepo.RollupRecentTrends(trendRetention); err != nil {
+		log.Printf("Failed to roll up hashtag trends: %v", err)
+	}
+}
+
+// GetTrends kapsam ve pencereye göre yükselişteki hashtag'leri döndürür
+func (s *PostService) GetTrends(scopeType string, country string, city string, window string, limit int) ([]types.HashtagTrend, error) {
+	if window == "" {
+		window = defaultTrendWindow
+	}
+	duration, ok := trendWindows[window]
+	if !ok {
+		return nil, ErrInvalidTrendWindow
+	}
+	scope, err := repositories.TrendScopeKey(scopeType, country, city)
+	if err != nil {
+		return nil, err
+	}
+	if scopeType == "" {
+		scopeType = types.TrendScopeGlobal
+	}
+
+	return s.postRepo.GetTrendingHashtags(types.TrendQuery{
+		ScopeType: scopeType,
+		Scope:     scope,
+		Window:    duration,
+		Limit:     limit,
+	}, time.Now())
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"coolvibes/types"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// İstemci bu süre içinde tekrar "typing" göndermezse otomatik durdurulur
+	typingExpireAfter = 6 * time.Second
+	// Aynı kullanıcının ardışık "typing" event'leri bu aralıktan sık yayınlanmaz
+	typingThrottle = 2 * time.Second
+	// Özet metninde isimleri yazılacak maksimum kullanıcı sayısı
+	typingSummaryNames = 2
+)
+
+type typingEntry struct {
+	user      types.TypingUser
+	startedAt time.Time
+	lastSent  time.Time
+	timer     *time.Timer
+}
+
+// TypingTracker sohbet başına kimin yazdığını tutar; throttle ve
+// otomatik süre dolumu sunucu tarafında yapılır
+type TypingTracker struct {
+	mu      sync.Mutex
+	chats   map[uuid.UUID]map[uuid.UUID]*typingEntry
+	publish func(chatID, userID uuid.UUID, typing bool, users []types.TypingUser, summary string)
+}
+
+func NewTypingTracker(publish func(chatID, userID uuid.UUID, typing bool, users []types.TypingUser, summary string)) *TypingTracker {
+	return &TypingTracker{
+		chats:   make(map[uuid.UUID]map[uuid.UUID]*typingEntry),
+		publish: publish,
+	}
+}
+
+// Start kullanıcıyı yazıyor olarak işaretler. Kullanıcı zaten yazıyorsa
+// sadece süresi uzatılır; throttle aralığı geçmediyse yayın yapılmaz.
+func (t *TypingTracker) Start(chatID uuid.UUID, user types.TypingUser) {
+	t.mu.Lock()
+
+	now := time.Now()
+	users, ok := t.chats[chatID]
+	if !ok {
+		users = make(map[uuid.UUID]*typingEntry)
+		t.chats[chatID] = users
+	}
+
+	entry, exists := users[user.ID]
+	if exists {
+		entry.timer.Reset(typingExpireAfter)
+		if now.Sub(entry.lastSent) < typingThrottle {
+			t.mu.Unlock()
+			return
+		}
+		entry.lastSent = now
+	} else {
+		entry = &typingEntry{user: user, startedAt: now, lastSent: now}
+		entry.timer = time.AfterFunc(typingExpireAfter, func() {
+			t.expire(chatID, user.ID, entry)
+		})
+		users[user.ID] = entry
+	}
+
+	list, summary := t.snapshot(chatID)
+	t.mu.Unlock()
+
+	t.publish(chatID, user.ID, true, list, summary)
+}
+
+// Stop kullanıcının yazma durumunu kaldırır. Kullanıcı yazmıyorsa bir şey yapmaz.
+func (t *TypingTracker) Stop(chatID, userID uuid.UUID) {
+	t.mu.Lock()
+	entry, ok := t.chats[chatID][userID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	entry.timer.Stop()
+	t.remove(chatID, userID)
+
+	list, summary := t.snapshot(chatID)
+	t.mu.Unlock()
+
+	t.publish(chatID, userID, false, list, summary)
+}
+
+// Timer tetiklendiğinde çağrılır; bu arada yeni bir Start gelmişse
+// (farklı entry) dokunulmaz
+func (t *TypingTracker) expire(chatID, userID uuid.UUID, expired *typingEntry) {
+	t.mu.Lock()
+	entry, ok := t.chats[chatID][userID]
+	if !ok || entry != expired {
+		t.mu.Unlock()
+		return
+	}
+	t.remove(chatID, userID)
+
+	list, summary := t.snapshot(chatID)
+	t.mu.Unlock()
+
+	t.publish(chatID, userID, false, list, summary)
+}
+
+func (t *TypingTracker) remove(chatID, userID uuid.UUID) {
+	delete(t.chats[chatID], userID)
+	if len(t.chats[chatID]) == 0 {
+		delete(t.chats, chatID)
+	}
+}
+
+// snapshot yazmaya başlama sırasına göre kullanıcı listesini ve özet metnini döndürür
+func (t *TypingTracker) snapshot(chatID uuid.UUID) ([]types.TypingUser, string) {
+	entries := make([]*typingEntry, 0, len(t.chats[chatID]))
+	for _, e := range t.chats[chatID] {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].startedAt.Before(entries[j].startedAt)
+	})
+
+	list := make([]types.TypingUser, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e.user)
+	}
+	return list, typingSummary(list)
+}
+
+// typingSummary: "Ali is typing", "Ali and Ayşe are typing",
+// "Ali, Ayşe and 3 others are typing"
+func typingSummary(users []types.TypingUser) string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		name := u.DisplayName
+		if name == "" {
+			name = u.UserName
+		}
+		names = append(names, name)
+	}
+
+	switch {
+	case len(names) == 0:
+		return ""
+	case len(names) == 1:
+		return names[0] + " is typing"
+	case len(names) <= typingSummaryNames+1:
+		return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1] + " are typing"
+	default:
+		others := len(names) - typingSummaryNames
+		return fmt.Sprintf("%s and %d others are typing", strings.Join(names[:typingSummaryNames], ", "), others)
+	}
+}
@@ -28,6 +28,19 @@ type UserService struct {
 	postRepo         *repositories.PostRepository
 	engagementRepo   *repositories.EngagementRepository
 	notificationRepo *repositories.NotificationRepository
+	blockHandlers    []func(blockerID, blockedID uuid.UUID)
+	followHandlers   []func(followerID uuid.UUID)
+}
+
+// OnBlock bir kullanıcı diğerini engellediğinde çağrılacak handler kaydeder
+func (s *UserService) OnBlock(handler func(blockerID, blockedID uuid.UUID)) {
+	s.blockHandlers = append(s.blockHandlers, handler)
+}
+
+// OnFollowChange kullanıcı birini takip ettiğinde ya da takipten çıktığında
+// çağrılacak handler kaydeder
+func (s *UserService) OnFollowChange(handler func(followerID uuid.UUID)) {
+	s.followHandlers = append(s.followHandlers, handler)
 }
 
 func NewUserService(
@@ -392,6 +405,9 @@ func (s *UserService) ToggleFollow(ctx context.Context, followerID, followeeID i
 	}
 
 	fmt.Println("isFollowing:", isFollowing)
+	for _, handler := range s.followHandlers {
+		handler(followerUser.ID)
+	}
 
 	if isFollowing {
 		// Follow started
@@ -659,9 +675,36 @@ func (s *UserService) ToggleBlock(ctx context.Context, authUser models.User, blo
 		return status, err
 	}
 
+	if blocked, err := engagementRepo.HasUserEngaged(ctx, blockerUser.ID, blockedUser.ID, engagementKindGiven); err == nil && blocked {
+		for _, handler := range s.blockHandlers {
+			handler(blockerUser.ID, blockedUser.ID)
+		}
+	}
+
 	return true, nil
 }
 
+// ToggleMute kullanıcıyı sessize alır / sessizden çıkarır. Sessize alınan
+// kullanıcının postları akışta gösterilmez; karşı taraf bundan haberdar olmaz.
+func (s *UserService) ToggleMute(ctx context.Context, muterId, mutedId int64) (bool, error) {
+	muterUser, err := s.userRepo.GetUserByPublicIdWithoutRelations(muterId)
+	if err != nil {
+		return false, err
+	}
+	mutedUser, err := s.userRepo.GetUserByPublicIdWithoutRelations(mutedId)
+	if err != nil {
+		return false, err
+	}
+
+	engagementRepo := s.userRepo.GetEngagementRepository()
+	_, err = engagementRepo.ToggleEngagement(ctx, muterUser.ID, mutedUser.ID, models.EngagementKindMuting, muterUser.ID, models.EngagementContentableTypeUser)
+	if err != nil {
+		return false, err
+	}
+
+	return engagementRepo.HasUserEngaged(ctx, muterUser.ID, mutedUser.ID, models.EngagementKindMuting)
+}
+
 func (s *UserService) FetchUserNotifications(ctx context.Context, authUser *models.User, cursor *time.Time, limit int) (items []*notifications.Notification, nextCursor *time.Time, err error) {
 	return s.userRepo.FetchUserNotifications(ctx, authUser, cursor, limit)
 }
@@ -0,0 +1,36 @@
+package test
+
+import (
+	"coolvibes/helpers"
+	"coolvibes/models/chat/payloads"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testChatCalls kapanan aramanın kullanıcıyı meşgul göstermemesini dener
+func testChatCalls(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	f := newChatFixture(db, snowFlakeNode)
+	if f == nil {
+		return
+	}
+
+	now := time.Now()
+	call := &payloads.Call{
+		ID: uuid.New(), ChatID: f.chat.ID, CallerID: f.sender.ID, ReceiverID: f.receiver.ID,
+		CallType: "audio", Status: payloads.CallOngoing, StartedAt: &now,
+	}
+	if err := f.chatRepo.CreateCall(call); err != nil {
+		fmt.Println("create call error:", err)
+		return
+	}
+	active, err := f.chatRepo.GetActiveCallForUser(f.sender.ID)
+	check("caller busy", err == nil && active.ID == call.ID, err)
+
+	call.Status, call.EndedAt = payloads.CallEnded, &now
+	ended, _ := f.chatRepo.UpdateCallStatus(call, payloads.CallOngoing)
+	_, err = f.chatRepo.GetActiveCallForUser(f.sender.ID)
+	check("ended call frees caller", ended && err != nil)
+}
@@ -0,0 +1,32 @@
+package test
+
+import (
+	"coolvibes/helpers"
+	"coolvibes/types"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// testChatDisappearing kaybolan mesajların süre dolunca sweeper'ı beklemeden
+// gizlenmesini dener
+func testChatDisappearing(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	f := newChatFixture(db, snowFlakeNode)
+	if f == nil {
+		return
+	}
+
+	kept := sendChatMessage(f.chatRepo, f.chat, &f.sender, "stays", nil)
+	seconds := int64(1)
+	f.chatRepo.SetDisappearAfter(f.chat.ID, &seconds)
+	expiring := sendChatMessage(f.chatRepo, f.chat, &f.sender, "gone soon", nil)
+	if kept == nil || expiring == nil {
+		return
+	}
+
+	time.Sleep(2 * time.Second)
+	page, _ := f.chatRepo.GetMessagesByChatID(f.receiver.ID, f.chat.ID, types.MessagePage{Limit: 10})
+	check("expired message hidden", findMessage(page.Messages, expiring) == nil && findMessage(page.Messages, kept) != nil)
+	_, err := f.chatRepo.FindMessageByPublicID(f.chat.ID, expiring.PublicID)
+	check("expired message not found", err != nil)
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"coolvibes/helpers"
+	"coolvibes/models/chat"
+	"coolvibes/models/post"
+	"coolvibes/models/utils"
+	"coolvibes/types"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// testChatEdit mesaj düzenleme revizyonlarını, "benden sil" ve "herkesten sil" akışlarını dener
+func testChatEdit(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	f := newChatFixture(db, snowFlakeNode)
+	if f == nil {
+		return
+	}
+
+	edited := sendChatMessage(f.chatRepo, f.chat, &f.sender, "first draft", nil)
+	hidden := sendChatMessage(f.chatRepo, f.chat, &f.sender, "hide me", nil)
+	deleted := sendChatMessage(f.chatRepo, f.chat, &f.sender, "delete me", nil)
+	if edited == nil || hidden == nil || deleted == nil {
+		return
+	}
+
+	err := f.chatRepo.EditMessage(edited, f.sender.ID, utils.MakeLocalizedString("en", "final"))
+	revisions, _ := f.chatRepo.GetMessageRevisions(edited.ID)
+	check("edit keeps revision", err == nil && len(revisions) == 1 && (*revisions[0].Content)["en"] == "first draft", err, revisions)
+
+	f.chatRepo.HideMessageForUser(hidden.ID, f.receiver.ID)
+	forReceiver, _ := f.chatRepo.GetMessagesByChatID(f.receiver.ID, f.chat.ID, types.MessagePage{Limit: 10})
+	forSender, _ := f.chatRepo.GetMessagesByChatID(f.sender.ID, f.chat.ID, types.MessagePage{Limit: 10})
+	check("hidden for receiver only", findMessage(forReceiver.Messages, hidden) == nil && findMessage(forSender.Messages, hidden) != nil)
+
+	err = f.chatRepo.TombstoneMessage(deleted, f.sender.ID)
+	forReceiver, _ = f.chatRepo.GetMessagesByChatID(f.receiver.ID, f.chat.ID, types.MessagePage{Limit: 10})
+	tombstone := findMessage(forReceiver.Messages, deleted)
+	check("tombstone stays in chat", err == nil && tombstone != nil && tombstone.Content == nil && chat.IsTombstoned(tombstone), err)
+
+	f.chatRepo.TombstoneMessage(edited, f.sender.ID)
+	revisions, _ = f.chatRepo.GetMessageRevisions(edited.ID)
+	check("tombstone drops revisions", len(revisions) == 0, revisions)
+}
+
+// testChatLinkPreview silinen veya düzenlenen mesaja geç gelen önizlemenin
+// yazılmamasını ve silinen mesajda önizleme kalmamasını dener
+func testChatLinkPreview(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	f := newChatFixture(db, snowFlakeNode)
+	if f == nil {
+		return
+	}
+	preview := map[string]any{"url": "https://example.com", "title": "Example"}
+
+	deleted := sendChatMessage(f.chatRepo, f.chat, &f.sender, "see https://example.com", nil)
+	edited := sendChatMessage(f.chatRepo, f.chat, &f.sender, "see https://example.com", nil)
+	if deleted == nil || edited == nil {
+		return
+	}
+	stored, err := f.postRepo.SetLinkPreview(deleted, preview)
+	check("store link preview", err == nil && stored, err)
+
+	snapshot := *deleted
+	withPreview, err := f.postRepo.GetPostByID(deleted.ID)
+	if err != nil {
+		fmt.Println("get message error:", err)
+		return
+	}
+	f.chatRepo.TombstoneMessage(withPreview, f.sender.ID)
+	reloaded, err := f.postRepo.GetPostByID(deleted.ID)
+	hasPreview := true
+	if err == nil {
+		_, hasPreview = reloaded.GetExtra(post.ExtraLinkPreview)
+	}
+	check("tombstone drops link preview", err == nil && !hasPreview, err)
+	stored, _ = f.postRepo.SetLinkPreview(&snapshot, preview)
+	check("late preview skips tombstone", !stored)
+
+	snapshot = *edited
+	f.chatRepo.EditMessage(edited, f.sender.ID, utils.MakeLocalizedString("en", "no links now"))
+	stored, _ = f.postRepo.SetLinkPreview(&snapshot, preview)
+	check("late preview skips edited message", !stored)
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models/chat"
+	"coolvibes/models/utils"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testChatGroups grup rollerini, sahiplik devrini ve sistem mesajlarını dener
+func testChatGroups(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	chatRepo, _ := newChatRepo(db, snowFlakeNode)
+	owner := faker.CreateUser(db, snowFlakeNode)
+	member := faker.CreateUser(db, snowFlakeNode)
+	admin := faker.CreateUser(db, snowFlakeNode)
+
+	group, err := chatRepo.CreateGroupChat(owner.ID, []uuid.UUID{member.ID, admin.ID}, utils.MakeLocalizedString("en", "group"), nil)
+	if err != nil {
+		fmt.Println("create group error:", err)
+		return
+	}
+	creator, err := chatRepo.GetParticipant(group.ID, owner.ID)
+	check("creator is owner", err == nil && creator.Role == chat.RoleOwner, err)
+
+	chatRepo.UpdateParticipantRole(group.ID, admin.ID, chat.RoleAdmin)
+	successor, err := chatRepo.GetSuccessor(group.ID, owner.ID)
+	check("admin succeeds owner", err == nil && successor.UserID == admin.ID, err)
+
+	err = chatRepo.TransferOwnership(group.ID, owner.ID, admin.ID)
+	newOwner, _ := chatRepo.GetParticipant(group.ID, admin.ID)
+	oldOwner, _ := chatRepo.GetParticipant(group.ID, owner.ID)
+	check("transfer ownership", err == nil && newOwner != nil && newOwner.Role == chat.RoleOwner &&
+		oldOwner != nil && oldOwner.Role == chat.RoleAdmin, err)
+
+	message, err := chatRepo.AddSystemMessage(group.ID, admin.ID, chat.SystemEventMemberRemoved, "member removed",
+		map[string]any{chat.ExtraTargetID: member.ID})
+	check("system message", err == nil && message != nil && message.Extras != nil &&
+		(*message.Extras)[chat.ExtraSystemEvent] == string(chat.SystemEventMemberRemoved), err)
+
+	chatRepo.RemoveParticipant(group.ID, member.ID)
+	isParticipant, _ := chatRepo.IsParticipant(group.ID, member.ID)
+	check("removed member", !isParticipant)
+	err = chatRepo.AddParticipant(group.ID, member.ID, chat.RoleMember)
+	isParticipant, _ = chatRepo.IsParticipant(group.ID, member.ID)
+	check("re-invite member", err == nil && isParticipant, err)
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"coolvibes/helpers"
+	"coolvibes/models/post"
+	"coolvibes/types"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// testChatMessages mesajların PublicID cursor'larıyla sayfalanmasını dener
+func testChatMessages(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	f := newChatFixture(db, snowFlakeNode)
+	if f == nil {
+		return
+	}
+
+	var sent []*post.Post
+	for i := 0; i < 5; i++ {
+		message := sendChatMessage(f.chatRepo, f.chat, &f.sender, fmt.Sprint("message ", i), nil)
+		if message == nil {
+			return
+		}
+		sent = append(sent, message)
+	}
+
+	latest, err := f.chatRepo.GetMessagesByChatID(f.receiver.ID, f.chat.ID, types.MessagePage{Limit: 2})
+	check("latest messages", err == nil && len(latest.Messages) == 2 &&
+		latest.Messages[0].ID == sent[3].ID && latest.Messages[1].ID == sent[4].ID &&
+		latest.NextCursor != nil && latest.PrevCursor == nil, err, latest.NextCursor, latest.PrevCursor)
+
+	older, err := f.chatRepo.GetMessagesByChatID(f.receiver.ID, f.chat.ID, types.MessagePage{Limit: 2, Before: cursorValue(latest.NextCursor)})
+	check("older page", err == nil && len(older.Messages) == 2 && older.Messages[0].ID == sent[1].ID && older.PrevCursor != nil, err)
+
+	oldest, err := f.chatRepo.GetMessagesByChatID(f.receiver.ID, f.chat.ID, types.MessagePage{Limit: 2, Before: cursorValue(older.NextCursor)})
+	check("oldest page", err == nil && len(oldest.Messages) == 1 && oldest.NextCursor == nil, err)
+
+	newer, err := f.chatRepo.GetMessagesByChatID(f.receiver.ID, f.chat.ID, types.MessagePage{Limit: 10, After: &sent[2].PublicID})
+	check("newer page", err == nil && len(newer.Messages) == 2 && newer.Messages[0].ID == sent[3].ID && newer.PrevCursor == nil, err)
+
+	around, err := f.chatRepo.GetMessagesByChatID(f.receiver.ID, f.chat.ID, types.MessagePage{Limit: 3, Around: &sent[2].PublicID})
+	check("around message", err == nil && len(around.Messages) == 3 &&
+		around.Messages[0].ID == sent[1].ID && around.Messages[2].ID == sent[3].ID &&
+		around.NextCursor != nil && around.PrevCursor != nil, err)
+}
@@ -0,0 +1,41 @@
+package test
+
+import (
+	"coolvibes/helpers"
+	"coolvibes/models/chat"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// testChatReplies silinen mesajı alıntılayan cevapların önizlemesinin
+// boşaltılmasını dener
+func testChatReplies(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	f := newChatFixture(db, snowFlakeNode)
+	if f == nil {
+		return
+	}
+
+	original := sendChatMessage(f.chatRepo, f.chat, &f.sender, "secret plan", nil)
+	if original == nil {
+		return
+	}
+	reply := sendChatMessage(f.chatRepo, f.chat, &f.receiver, "sounds good", map[string][]string{
+		"reply_to": {strconv.FormatInt(original.PublicID, 10)},
+	})
+	if reply == nil {
+		return
+	}
+
+	f.chatRepo.TombstoneMessage(original, f.sender.ID)
+	reloaded, err := f.chatRepo.FindMessageByPublicID(f.chat.ID, reply.PublicID)
+	if err != nil {
+		fmt.Println("find reply error:", err)
+		return
+	}
+	preview, _ := reloaded.GetExtra(chat.ExtraReplyTo)
+	quoted, _ := preview.(map[string]any)
+	content, _ := quoted["content"].(map[string]any)
+	check("tombstone redacts reply preview", quoted != nil && len(content) == 0 && quoted["deleted"] == true, preview)
+}
@@ -0,0 +1,33 @@
+package test
+
+import (
+	"coolvibes/faker"
+	"coolvibes/helpers"
+
+	"gorm.io/gorm"
+)
+
+// testChatRequests eşleşmeyenlerden gelen sohbetin istek kutusuna düşmesini
+// ve kabul edilince gelen kutusuna geçmesini dener
+func testChatRequests(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	chatRepo, _ := newChatRepo(db, snowFlakeNode)
+	sender := faker.CreateUser(db, snowFlakeNode)
+	recipient := faker.CreateUser(db, snowFlakeNode)
+	chatObj := openPrivateChat(chatRepo, sender, recipient, true)
+	if chatObj == nil {
+		return
+	}
+
+	requests, _ := chatRepo.GetChatRequestsByUserID(recipient.ID)
+	inbox, _ := chatRepo.GetChatsByUserID(recipient.ID, false)
+	check("request inbox", containsChat(requests, chatObj.ID) && !containsChat(inbox, chatObj.ID))
+	senderInbox, _ := chatRepo.GetChatsByUserID(sender.ID, false)
+	check("sender sees chat", containsChat(senderInbox, chatObj.ID))
+
+	err := chatRepo.AcceptChatRequest(chatObj.ID, recipient.ID)
+	requests, _ = chatRepo.GetChatRequestsByUserID(recipient.ID)
+	inbox, _ = chatRepo.GetChatsByUserID(recipient.ID, false)
+	check("accept request", err == nil && !containsChat(requests, chatObj.ID) && containsChat(inbox, chatObj.ID), err)
+	participant, _ := chatRepo.GetParticipant(chatObj.ID, recipient.ID)
+	check("accepted at", participant != nil && participant.RequestAcceptedAt != nil)
+}
@@ -0,0 +1,39 @@
+package test
+
+import (
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/types"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// testChatSearch mesaj aramasını ve snippet'in HTML'e kaçırılmasını dener
+func testChatSearch(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	f := newChatFixture(db, snowFlakeNode)
+	if f == nil {
+		return
+	}
+	outsider := faker.CreateUser(db, snowFlakeNode)
+
+	word := fmt.Sprintf("needle%d", snowFlakeNode.Generate().Int64())
+	message := sendChatMessage(f.chatRepo, f.chat, &f.sender, `<img src=x onerror="alert(1)"> `+word, nil)
+	hidden := sendChatMessage(f.chatRepo, f.chat, &f.sender, "hidden "+word, nil)
+	if message == nil || hidden == nil {
+		return
+	}
+	f.chatRepo.HideMessageForUser(hidden.ID, f.receiver.ID)
+
+	result, err := f.chatRepo.SearchMessages(f.receiver.ID, types.MessageSearch{Query: word, Limit: 10})
+	check("search finds message", err == nil && len(result.Hits) == 1 && result.Hits[0].Message.ID == message.ID, err, len(result.Hits))
+	if len(result.Hits) == 1 {
+		snippet := result.Hits[0].Snippet
+		check("snippet escaped", !strings.Contains(snippet, "<img") && strings.Contains(snippet, "&lt;img") &&
+			strings.Contains(snippet, "<mark>"+word+"</mark>"), snippet)
+	}
+
+	result, err = f.chatRepo.SearchMessages(outsider.ID, types.MessageSearch{Query: word, Limit: 10})
+	check("search only own chats", err == nil && len(result.Hits) == 0, err, len(result.Hits))
+}
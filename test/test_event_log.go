@@ -0,0 +1,59 @@
+package test
+
+import (
+	"coolvibes/services/socket"
+
+	"github.com/google/uuid"
+)
+
+// testEventLog yeniden bağlanan istemcinin kaçırdığı event'lerin replay'ini ve
+// tam senkronizasyon gerektiren durumları dener
+func testEventLog() {
+	log := socket.NewEventLog(2)
+	userID := uuid.New()
+	build := func(seq int64) (string, error) { return "payload", nil }
+
+	type replayResult struct {
+		events    []socket.UserEvent
+		epoch     string
+		seq       int64
+		truncated bool
+	}
+	replay := func(epoch string, lastSeq int64) replayResult {
+		var result replayResult
+		log.Replay(userID, epoch, lastSeq, func(events []socket.UserEvent, currentEpoch string, currentSeq int64, truncated bool) {
+			result = replayResult{events, currentEpoch, currentSeq, truncated}
+		})
+		return result
+	}
+
+	first := replay("", 0)
+	check("first connect has no replay", len(first.events) == 0 && first.seq == 0 && !first.truncated && first.epoch != "", first)
+
+	for i := 0; i < 3; i++ {
+		log.Publish(userID, "chat.message", build, nil)
+	}
+	resumed := replay(first.epoch, 2)
+	check("replay after last_seq", len(resumed.events) == 1 && resumed.events[0].Seq == 3 && !resumed.truncated, resumed)
+
+	// Log en fazla 2 event tutar
+	within := replay(first.epoch, 1)
+	check("replay within limit", len(within.events) == 2 && within.events[0].Seq == 2 && !within.truncated, within)
+	log.Publish(userID, "chat.message", build, nil)
+	dropped := replay(first.epoch, 1)
+	check("replay truncated by limit", dropped.truncated && len(dropped.events) == 2 && dropped.events[0].Seq == 3, dropped)
+
+	ahead := replay(first.epoch, 10)
+	check("client ahead of server", ahead.truncated && len(ahead.events) == 0, ahead)
+	reset := replay("old-epoch", 2)
+	check("epoch mismatch", reset.truncated && len(reset.events) == 0, reset)
+	legacy := replay("", 3)
+	check("legacy client without epoch", !legacy.truncated && len(legacy.events) == 1, legacy)
+
+	kept := log.EvictIdle(0, func(uuid.UUID) bool { return true })
+	check("connected stream kept", kept == 0, kept)
+	evicted := log.EvictIdle(0, func(uuid.UUID) bool { return false })
+	check("idle stream evicted", evicted == 1 && log.CurrentSeq(userID) == 0, evicted)
+	afterEvict := replay(first.epoch, 4)
+	check("resync after eviction", afterEvict.truncated && afterEvict.epoch != first.epoch, afterEvict)
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"coolvibes/services/ranking"
+	"coolvibes/types"
+	"fmt"
+	"time"
+)
+
+// testFeedRanking "Senin İçin" sıralayıcısını sabit tohumlu sentetik veri
+// üzerinde değerlendirir ve tek sinyalli sıralayıcılarla karşılaştırır
+func testFeedRanking() {
+	cases := ranking.SeedDataset(ranking.SeedConfig{Seed: 42, Viewers: 200, Candidates: 150, Authors: 60})
+	const k = 10
+
+	linear := ranking.Evaluate(ranking.NewLinearRanker(ranking.DefaultWeights()), cases, k)
+	chronological := ranking.Evaluate(ranking.ChronologicalRanker{}, cases, k)
+	fmt.Printf("%+v\n%+v\n", linear, chronological)
+	check("ranker beats chronological", linear.NDCG > chronological.NDCG && linear.Precision > chronological.Precision)
+
+	for _, signal := range []string{ranking.SignalVelocity, ranking.SignalAffinity, ranking.SignalProximity, ranking.SignalLanguage, ranking.SignalFreshness} {
+		weights := ranking.DefaultWeights()
+		weights.Velocity, weights.Affinity, weights.Proximity, weights.Language, weights.Freshness = 0, 0, 0, 0, 0
+		switch signal {
+		case ranking.SignalVelocity:
+			weights.Velocity = 1
+		case ranking.SignalAffinity:
+			weights.Affinity = 1
+		case ranking.SignalProximity:
+			weights.Proximity = 1
+		case ranking.SignalLanguage:
+			weights.Language = 1
+		case ranking.SignalFreshness:
+			weights.Freshness = 1
+		}
+		report := ranking.Evaluate(ranking.NewLinearRanker(weights), cases, k)
+		fmt.Printf("only %-10s ndcg@%d=%.3f precision@%d=%.3f\n", signal, k, report.NDCG, k, report.Precision)
+	}
+
+	again := ranking.Evaluate(ranking.NewLinearRanker(ranking.DefaultWeights()), ranking.SeedDataset(ranking.SeedConfig{Seed: 42, Viewers: 200, Candidates: 150, Authors: 60}), k)
+	check("seeded data is deterministic", again == linear, again)
+
+	weights, err := ranking.LoadWeights(`{"proximity": 0.5}`)
+	check("weights override", err == nil && weights.Proximity == 0.5 && weights.Velocity == ranking.DefaultWeights().Velocity, weights, err)
+	_, err = ranking.LoadWeights(`{"proximity":`)
+	check("invalid weights", err != nil, err)
+
+	// Yakın ve izleyicinin dilindeki post, aynı etkileşime sahip uzak ve yabancı dildeki posttan önde
+	now := time.Now()
+	near, far := 2000.0, 900000.0
+	ranked := ranking.NewLinearRanker(ranking.DefaultWeights()).Rank(ranking.Viewer{Language: "tr"}, []types.FeedCandidate{
+		{PublicID: 1, CreatedAt: now.Add(-time.Hour), Engagement: 10, DistanceMeters: &far, Languages: "en"},
+		{PublicID: 2, CreatedAt: now.Add(-time.Hour), Engagement: 10, DistanceMeters: &near, Languages: "tr"},
+	}, now)
+	check("proximity and language", ranked[0].PublicID == 2, ranked[0].Signals, ranked[1].Signals)
+}
@@ -0,0 +1,51 @@
+package test
+
+import (
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/repositories"
+	services "coolvibes/services/user"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testForYouCursor "Senin İçin" cursor'ının çözülmesini dener
+func testForYouCursor() {
+	snapshotID := uuid.New()
+	cursor := services.EncodeForYouCursor(snapshotID, 20)
+	id, offset, err := services.DecodeForYouCursor(cursor)
+	check("for you cursor round trip", err == nil && id == snapshotID && offset == 20, err, cursor)
+
+	for _, invalid := range []string{"", "1700000000:10", snapshotID.String(), snapshotID.String() + ":-1", snapshotID.String() + ":x"} {
+		_, _, err := services.DecodeForYouCursor(invalid)
+		check("invalid for you cursor "+invalid, errors.Is(err, services.ErrInvalidFeedCursor), err)
+	}
+}
+
+// testForYouSnapshot sıralanmış listenin saklanmasını, sadece sahibine
+// dönmesini ve eski listelerin temizlenmesini dener
+func testForYouSnapshot(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	postRepo, _ := newPostRepos(db, snowFlakeNode)
+	feedRepo := repositories.NewFeedRepository(db, postRepo)
+	viewer := faker.CreateUser(db, snowFlakeNode)
+	other := faker.CreateUser(db, snowFlakeNode)
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	first, err := feedRepo.SaveForYouSnapshot(viewer.ID, ids, time.Hour)
+	if err != nil {
+		check("save for you snapshot", false, err)
+		return
+	}
+	loaded, err := feedRepo.GetForYouSnapshot(first.ID, viewer.ID)
+	check("snapshot keeps order", err == nil && loaded != nil && len(loaded.PostIDs) == 3 && loaded.PostIDs[2] == ids[2].String(), err)
+	foreign, err := feedRepo.GetForYouSnapshot(first.ID, other.ID)
+	check("snapshot owned by viewer", err == nil && foreign == nil, err)
+
+	// Sıfır ömürle kaydedilen yeni liste öncekini siler
+	second, _ := feedRepo.SaveForYouSnapshot(viewer.ID, ids[:1], 0)
+	loaded, _ = feedRepo.GetForYouSnapshot(first.ID, viewer.ID)
+	check("old snapshot pruned", second != nil && loaded == nil)
+}
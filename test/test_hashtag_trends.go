@@ -0,0 +1,78 @@
+package test
+
+import (
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/repositories"
+	"coolvibes/types"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// testHashtagTrends konum kapsamlı özetleri ve taban çizgisine göre sıralamayı dener
+func testHashtagTrends(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	postRepo, _ := newPostRepos(db, snowFlakeNode)
+
+	author := faker.CreateUser(db, snowFlakeNode)
+	id := snowFlakeNode.Generate().Int64()
+	tag := fmt.Sprintf("trend%d", id)
+
+	for i := 0; i < 3; i++ {
+		created := createPost(postRepo, &author, map[string][]string{
+			"content":                {"street festival #" + tag},
+			"audience":               {"public"},
+			"location[lat]":          {"41.0082"},
+			"location[lng]":          {"28.9784"},
+			"location[country_code]": {"tr"},
+			"location[city]":         {" Istanbul"},
+		})
+		if created == nil {
+			return
+		}
+	}
+
+	err := postRepo.RollupTrends(time.Now().Add(-time.Hour), repositories.TrendBaseline)
+	check("rollup trends", err == nil, err)
+	for scopeType, scope := range map[string]string{
+		types.TrendScopeGlobal:  "",
+		types.TrendScopeCountry: "TR",
+		types.TrendScopeCity:    "TR/istanbul",
+	} {
+		var count int64
+		db.Model(&models.HashtagTrendBucket{}).
+			Where("tag = ? AND scope_type = ? AND scope = ?", tag, scopeType, scope).
+			Select("COALESCE(SUM(count), 0)").Scan(&count)
+		check("rollup "+scopeType, count == 3, count)
+	}
+
+	scope, _ := repositories.TrendScopeKey(types.TrendScopeCity, "TR", "istanbul")
+	trends, err := postRepo.GetTrendingHashtags(types.TrendQuery{
+		ScopeType: types.TrendScopeCity, Scope: scope, Window: 24 * time.Hour, Limit: 100,
+	}, time.Now())
+	found := false
+	for _, t := range trends {
+		found = found || (t.Tag == tag && t.Count == 3)
+	}
+	check("city trend", err == nil && found, err, trends)
+
+	// Her gün aynı sayıda kullanılan etiket, yeni yükselen etiketin gerisinde kalır
+	country := fmt.Sprintf("Z%d", id)
+	now := time.Now()
+	for day := 0; day < 7; day++ {
+		db.Create(&models.HashtagTrendBucket{
+			Tag: "steady", ScopeType: types.TrendScopeCountry, Scope: country,
+			Bucket: now.Add(-time.Duration(day) * 24 * time.Hour).Truncate(time.Hour), Count: 20,
+		})
+	}
+	db.Create(&models.HashtagTrendBucket{
+		Tag: "rising", ScopeType: types.TrendScopeCountry, Scope: country,
+		Bucket: now.Truncate(time.Hour), Count: 20,
+	})
+	trends, err = postRepo.GetTrendingHashtags(types.TrendQuery{
+		ScopeType: types.TrendScopeCountry, Scope: country, Window: 24 * time.Hour, Limit: 10,
+	}, now)
+	check("rising beats steady", err == nil && len(trends) == 2 && trends[0].Tag == "rising" && trends[1].Baseline > 0, err, trends)
+}
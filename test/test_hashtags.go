@@ -0,0 +1,79 @@
+package test
+
+import (
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models/post"
+	"coolvibes/repositories"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// testHashtags etiket sayfasını ve takip edilen etiketlerin takip akışına
+// girdiğini dener
+func testHashtags(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	postRepo, _ := newPostRepos(db, snowFlakeNode)
+	feedRepo := repositories.NewFeedRepository(db, postRepo)
+
+	tag, ok := helpers.NormalizeHashtag(" #GoLang ")
+	check("normalize hashtag", ok && tag == "golang", tag)
+	_, ok = helpers.NormalizeHashtag("two words")
+	check("reject invalid hashtag", !ok)
+
+	author := faker.CreateUser(db, snowFlakeNode)
+	viewer := faker.CreateUser(db, snowFlakeNode)
+	id := snowFlakeNode.Generate().Int64()
+	tag = fmt.Sprintf("page%d", id)
+	related := fmt.Sprintf("related%d", id)
+
+	create := func(content string, audience string) *post.Post {
+		return createPost(postRepo, &author, map[string][]string{
+			"content":  {content},
+			"audience": {audience},
+		})
+	}
+	first := create("first #"+strings.ToUpper(tag)+" #"+related, "public")
+	second := create("second #"+tag, "public")
+	third := create("third #"+tag, "public")
+	hidden := create("hidden #"+tag, "private")
+	if first == nil || second == nil || third == nil || hidden == nil {
+		return
+	}
+
+	// Sohbet mesajına formdan eklenen etiket sayfaya ve sayılara girmez
+	chatRepo, _ := newChatRepo(db, snowFlakeNode)
+	if dm := openPrivateChat(chatRepo, author, viewer, false); dm != nil {
+		sendChatMessage(chatRepo, dm, &author, "chat", map[string][]string{"hashtags[]": {tag, related}})
+	}
+
+	page, err := postRepo.GetHashtagPosts(tag, 2, nil, nil)
+	check("hashtag first page", err == nil && len(page.Posts) == 2 && page.Posts[0].ID == third.ID, err, len(page.Posts))
+	if page.NextCursor != nil {
+		var cursor int64
+		fmt.Sscan(*page.NextCursor, &cursor)
+		next, err := postRepo.GetHashtagPosts(tag, 10, &cursor, nil)
+		check("hashtag next page", err == nil && len(next.Posts) == 1 && next.Posts[0].ID == first.ID, err, len(next.Posts))
+	}
+
+	info, err := postRepo.GetHashtagInfo(tag)
+	check("hashtag post count", err == nil && info.PostCount == 3 && info.FirstSeen != nil, err, info.PostCount)
+	check("related hashtags", len(info.RelatedTags) == 1 && info.RelatedTags[0].Tag == related && info.RelatedTags[0].Count == 1, info.RelatedTags)
+
+	timeline, _ := feedRepo.GetFollowingTimeline(viewer.ID, 50, nil)
+	check("unfollowed hashtag not in feed", !containsPost(timeline.Posts, second.ID))
+
+	err = postRepo.FollowHashtag(viewer.ID, tag)
+	following, _ := postRepo.IsFollowingHashtag(viewer.ID, tag)
+	check("follow hashtag", err == nil && following, err)
+	timeline, _ = feedRepo.GetFollowingTimeline(viewer.ID, 50, nil)
+	check("followed hashtag in feed", containsPost(timeline.Posts, first.ID) && containsPost(timeline.Posts, second.ID) && !containsPost(timeline.Posts, hidden.ID))
+	check("last feed page has no cursor", timeline.NextCursor == nil, timeline.NextCursor)
+	lastFollow, err := feedRepo.LastFollowAt(viewer.ID)
+	check("hashtag follow triggers feed rebuild", err == nil && lastFollow != nil, err)
+
+	postRepo.UnfollowHashtag(viewer.ID, tag)
+	timeline, _ = feedRepo.GetFollowingTimeline(viewer.ID, 50, nil)
+	check("unfollow hashtag", !containsPost(timeline.Posts, second.ID))
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/models/chat"
+	"coolvibes/models/post"
+	"coolvibes/repositories"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func check(name string, ok bool, detail ...any) {
+	status := "PASS"
+	if !ok {
+		status = "FAIL"
+	}
+	fmt.Println(status, name, fmt.Sprint(detail...))
+}
+
+// newPostRepos test senaryoları için post ve etkileşim depoları
+func newPostRepos(db *gorm.DB, snowFlakeNode *helpers.Node) (*repositories.PostRepository, *repositories.EngagementRepository) {
+	engagementRepo := repositories.NewEngagementRepository(db)
+	userRepo := repositories.NewUserRepository(db, snowFlakeNode, engagementRepo)
+	mediaRepo := repositories.NewMediaRepository(db, snowFlakeNode)
+	return repositories.NewPostRepository(db, snowFlakeNode, mediaRepo, userRepo), engagementRepo
+}
+
+// newChatRepo test senaryoları için sohbet deposu
+func newChatRepo(db *gorm.DB, snowFlakeNode *helpers.Node) (*repositories.ChatRepository, *repositories.PostRepository) {
+	postRepo, _ := newPostRepos(db, snowFlakeNode)
+	return repositories.NewChatRepository(db, snowFlakeNode, postRepo, repositories.NewNotificationRepository(db, snowFlakeNode)), postRepo
+}
+
+// chatFixture iki yeni kullanıcı arasındaki özel sohbet
+type chatFixture struct {
+	chatRepo *repositories.ChatRepository
+	postRepo *repositories.PostRepository
+	sender   models.User
+	receiver models.User
+	chat     *chat.Chat
+}
+
+// newChatFixture iki kullanıcı oluşturup aralarında özel sohbet açar.
+// Sohbet açılamazsa nil döner.
+func newChatFixture(db *gorm.DB, snowFlakeNode *helpers.Node) *chatFixture {
+	f := &chatFixture{
+		sender:   faker.CreateUser(db, snowFlakeNode),
+		receiver: faker.CreateUser(db, snowFlakeNode),
+	}
+	f.chatRepo, f.postRepo = newChatRepo(db, snowFlakeNode)
+	f.chat = openPrivateChat(f.chatRepo, f.sender, f.receiver, false)
+	if f.chat == nil {
+		return nil
+	}
+	return f
+}
+
+// openPrivateChat iki kullanıcı arasında özel sohbet açar
+func openPrivateChat(chatRepo *repositories.ChatRepository, sender, receiver models.User, isRequest bool) *chat.Chat {
+	chatObj, err := chatRepo.CreatePrivateChat(sender.ID, receiver.ID, isRequest)
+	if err != nil {
+		fmt.Println("create chat error:", err)
+		return nil
+	}
+	return chatObj
+}
+
+// sendChatMessage sohbete düz metin mesajı gönderir
+func sendChatMessage(chatRepo *repositories.ChatRepository, chatObj *chat.Chat, author *models.User, content string, extra map[string][]string) *post.Post {
+	request := map[string][]string{
+		"chat_id": {chatObj.ID.String()},
+		"content": {content},
+	}
+	for k, v := range extra {
+		request[k] = v
+	}
+	message, err := chatRepo.AddMessageToChat(request, nil, author)
+	if err != nil {
+		fmt.Println("send message error:", err)
+	}
+	return message
+}
+
+// createPost formdaki alanlarla bir post oluşturur
+func createPost(postRepo *repositories.PostRepository, author *models.User, form map[string][]string) *post.Post {
+	created, err := postRepo.CreateContentablePost(form, nil, author, "post", nil)
+	if err != nil {
+		fmt.Println("create post error:", err)
+		return nil
+	}
+	return created
+}
+
+func cursorValue(cursor *string) *int64 {
+	if cursor == nil {
+		return nil
+	}
+	value, err := strconv.ParseInt(*cursor, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+func findMessage(messages []post.Post, message *post.Post) *post.Post {
+	for i := range messages {
+		if messages[i].ID == message.ID {
+			return &messages[i]
+		}
+	}
+	return nil
+}
+
+func containsChat(chats []chat.Chat, chatID uuid.UUID) bool {
+	for _, c := range chats {
+		if c.ID == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPost(posts []post.Post, id uuid.UUID) bool {
+	for _, p := range posts {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
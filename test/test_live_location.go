@@ -0,0 +1,59 @@
+package test
+
+import (
+	"coolvibes/helpers"
+	"coolvibes/models/chat"
+	"coolvibes/repositories"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// testValidCoordinates konum doğrulamasının sınırlarını dener
+func testValidCoordinates() {
+	check("valid coordinates", repositories.ValidCoordinates(41.0082, 28.9784))
+	check("coordinate bounds", repositories.ValidCoordinates(-90, 180) && repositories.ValidCoordinates(90, -180))
+	check("latitude out of range", !repositories.ValidCoordinates(90.0001, 10))
+	check("longitude out of range", !repositories.ValidCoordinates(10, -180.0001))
+	check("null island rejected", !repositories.ValidCoordinates(0, 0))
+	check("equator allowed", repositories.ValidCoordinates(0, 32.5))
+}
+
+// testLiveLocation durdurulan canlı konum oturumunun güncelleme almamasını ve
+// mesajın son konumla kapanmasını dener
+func testLiveLocation(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	f := newChatFixture(db, snowFlakeNode)
+	if f == nil {
+		return
+	}
+
+	now := time.Now()
+	session := &chat.LiveLocation{
+		ChatID: f.chat.ID, UserID: f.sender.ID, StartedAt: now, ExpiresAt: now.Add(time.Hour),
+		Latitude: 41.0082, Longitude: 28.9784, UpdatedAt: now.Add(-time.Minute),
+	}
+	message, err := f.chatRepo.StartLiveLocation(f.chat, &f.sender, session)
+	if err != nil {
+		fmt.Println("start live location error:", err)
+		return
+	}
+	active, _ := f.chatRepo.GetActiveLiveLocations(f.chat.ID)
+	check("live location active", len(active) == 1 && active[0].MessageID == message.ID, active)
+
+	err = f.chatRepo.StopLiveLocation(session.ID)
+	active, _ = f.chatRepo.GetActiveLiveLocations(f.chat.ID)
+	stopped, _ := f.chatRepo.GetLiveLocation(session.ID)
+	check("live location stopped", err == nil && len(active) == 0 && stopped != nil && !stopped.IsActive(time.Now()), err)
+	updated, _ := f.chatRepo.UpdateLiveLocation(session, 0)
+	check("stopped session ignores updates", !updated)
+
+	reloaded, err := f.chatRepo.FindMessageByPublicID(f.chat.ID, message.PublicID)
+	if err != nil {
+		fmt.Println("find message error:", err)
+		return
+	}
+	live, _ := reloaded.GetExtra(chat.ExtraLiveLocation)
+	state, _ := live.(map[string]any)
+	check("message marked not live", state != nil && state["live"] == false, live)
+}
@@ -86,4 +86,31 @@ func testMatchesDetails(db *gorm.DB, snowFlakeNode *helpers.Node) {
 
 func StartTest(db *gorm.DB, snowFlakeNode *helpers.Node) {
 	testMatchesDetails(db, snowFlakeNode)
+	testUnfurl()
+	testEventLog()
+	testTypingTracker()
+	testReceiptStatus()
+	testVoiceWaveform()
+	testValidCoordinates()
+	testFeedRanking()
+	testForYouCursor()
+	testChatMessages(db, snowFlakeNode)
+	testChatGroups(db, snowFlakeNode)
+	testChatEdit(db, snowFlakeNode)
+	testChatLinkPreview(db, snowFlakeNode)
+	testChatReplies(db, snowFlakeNode)
+	testChatRequests(db, snowFlakeNode)
+	testChatSearch(db, snowFlakeNode)
+	testChatDisappearing(db, snowFlakeNode)
+	testChatCalls(db, snowFlakeNode)
+	testLiveLocation(db, snowFlakeNode)
+	testForYouSnapshot(db, snowFlakeNode)
+	testPostAudience(db, snowFlakeNode)
+	testPostEdit(db, snowFlakeNode)
+	testPostDelete(db, snowFlakeNode)
+	testPostRepost(db, snowFlakeNode)
+	testPostThread(db, snowFlakeNode)
+	testPostSchedule(db, snowFlakeNode)
+	testHashtagTrends(db, snowFlakeNode)
+	testHashtags(db, snowFlakeNode)
 }
@@ -0,0 +1,211 @@
+package test
+
+import (
+	"context"
+	"coolvibes/constants"
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/models/media"
+	"coolvibes/models/post"
+	"coolvibes/models/utils"
+	"coolvibes/repositories"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testPostAudience her kitle (audience) için anonim, yabancı, takipçi,
+// karşılıklı takipçi ve yazar izleyicilerinin gördüklerini doğrular
+func testPostAudience(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	ctx := context.Background()
+	postRepo, engagementRepo := newPostRepos(db, snowFlakeNode)
+
+	author := faker.CreateUser(db, snowFlakeNode)
+	follower := faker.CreateUser(db, snowFlakeNode)
+	mutual := faker.CreateUser(db, snowFlakeNode)
+	stranger := faker.CreateUser(db, snowFlakeNode)
+
+	follow := func(from, to models.User) {
+		if _, err := engagementRepo.ToggleEngagement(ctx, from.ID, to.ID, models.EngagementKindFollowing, from.ID, models.EngagementContentableTypeUser); err != nil {
+			fmt.Println("follow error:", err)
+		}
+	}
+	follow(follower, author)
+	follow(mutual, author)
+	follow(author, mutual)
+
+	audiences := []constants.PrivacyLevel{
+		constants.PrivacyPublic,
+		constants.PrivacyFollowersOnly,
+		constants.PrivacyMutualsOnly,
+		constants.PrivacyFriendsOnly,
+		constants.PrivacyPrivate,
+	}
+
+	// Her kitle için bir ana post, yazarın kendi postuna yanıtı ve bir medya
+	roots := map[constants.PrivacyLevel]*post.Post{}
+	replies := map[constants.PrivacyLevel]*post.Post{}
+	medias := map[constants.PrivacyLevel]uuid.UUID{}
+	for _, audience := range audiences {
+		root := createPost(postRepo, &author, map[string][]string{
+			"content":  {"audience test " + string(audience)},
+			"audience": {string(audience)},
+		})
+		if root == nil {
+			return
+		}
+		roots[audience] = root
+
+		reply := createPost(postRepo, &author, map[string][]string{
+			"content":      {"reply " + string(audience)},
+			"audience":     {string(constants.PrivacyPublic)},
+			"parentPostId": {fmt.Sprint(root.PublicID)},
+		})
+		if reply == nil {
+			return
+		}
+		replies[audience] = reply
+
+		medias[audience] = createAudienceMedia(db, snowFlakeNode, author.ID, root.ID)
+	}
+
+	_, err := postRepo.CreateContentablePost(map[string][]string{
+		"content":  {"invalid audience"},
+		"audience": {"everyone"},
+	}, nil, &author, "post", nil)
+	check("invalid audience rejected", errors.Is(err, repositories.ErrInvalidAudience), err)
+
+	// Yabancı gizli posta yanıt veremez
+	_, err = postRepo.CreateContentablePost(map[string][]string{
+		"content":      {"sneaky reply"},
+		"parentPostId": {fmt.Sprint(roots[constants.PrivacyPrivate].PublicID)},
+	}, nil, &stranger, "post", nil)
+	check("reply to hidden post rejected", err != nil, err)
+
+	type viewer struct {
+		name string
+		id   *uuid.UUID
+	}
+	viewers := []viewer{
+		{"anonymous", nil},
+		{"stranger", &stranger.ID},
+		{"follower", &follower.ID},
+		{"mutual", &mutual.ID},
+		{"author", &author.ID},
+	}
+
+	expected := map[string]map[constants.PrivacyLevel]bool{
+		"anonymous": {constants.PrivacyPublic: true},
+		"stranger":  {constants.PrivacyPublic: true},
+		"follower":  {constants.PrivacyPublic: true, constants.PrivacyFollowersOnly: true},
+		"mutual": {
+			constants.PrivacyPublic:        true,
+			constants.PrivacyFollowersOnly: true,
+			constants.PrivacyMutualsOnly:   true,
+			constants.PrivacyFriendsOnly:   true,
+		},
+		"author": {
+			constants.PrivacyPublic:        true,
+			constants.PrivacyFollowersOnly: true,
+			constants.PrivacyMutualsOnly:   true,
+			constants.PrivacyFriendsOnly:   true,
+			constants.PrivacyPrivate:       true,
+		},
+	}
+
+	cursor := int64(math.MaxInt64)
+	for _, v := range viewers {
+		userPosts, err := postRepo.GetUserPosts(author.ID, &cursor, 50, v.id)
+		if err != nil {
+			fmt.Println("GetUserPosts error:", err)
+			return
+		}
+		userReplies, err := postRepo.GetUserPostReplies(author.ID, &cursor, 50, v.id)
+		if err != nil {
+			fmt.Println("GetUserPostReplies error:", err)
+			return
+		}
+		userMedias, _, err := postRepo.GetUserMedias(author.ID, &cursor, 50, v.id)
+		if err != nil {
+			fmt.Println("GetUserMedias error:", err)
+			return
+		}
+		timeline, err := postRepo.GetTimeline(200, nil, v.id)
+		if err != nil {
+			fmt.Println("GetTimeline error:", err)
+			return
+		}
+
+		for _, audience := range audiences {
+			want := expected[v.name][audience]
+			name := fmt.Sprintf("%s/%s", v.name, audience)
+
+			detail, err := postRepo.GetPostByPublicID(roots[audience].PublicID, v.id)
+			check(name+" GetPostByID", (err == nil) == want, err)
+			if want && err == nil {
+				// Yanıt herkese açık olsa da yalnızca görülebilen post altında listelenir
+				check(name+" thread replies", len(detail.Children) == 1)
+			}
+
+			check(name+" GetUserPosts", containsPost(userPosts, roots[audience].ID) == want)
+			check(name+" GetUserPostReplies", containsPost(userReplies, replies[audience].ID) == want)
+			check(name+" GetTimeline", containsPost(timeline.Posts, roots[audience].ID) == want)
+
+			hasMedia := false
+			for _, m := range userMedias {
+				hasMedia = hasMedia || m.Media.ID == medias[audience]
+			}
+			check(name+" GetUserMedias", hasMedia == want)
+		}
+	}
+
+	// Sohbet mesajları herkese açık postlar gibi kaydedilse de post olarak okunamaz
+	chatRepo, _ := newChatRepo(db, snowFlakeNode)
+	dm := openPrivateChat(chatRepo, author, mutual, false)
+	if dm == nil {
+		return
+	}
+	message := sendChatMessage(chatRepo, dm, &author, "direct message", nil)
+	if message == nil {
+		return
+	}
+	for _, v := range viewers {
+		_, err := postRepo.GetPostByPublicID(message.PublicID, v.id)
+		check(v.name+" chat message not a post", errors.Is(err, repositories.ErrPostNotFound), err)
+		canView, _ := postRepo.CanViewPost(message.ID, v.id)
+		check(v.name+" cannot reply to chat message", !canView)
+	}
+}
+
+// createAudienceMedia posta dosya yüklemeden bağlı bir medya kaydı ekler
+func createAudienceMedia(db *gorm.DB, snowFlakeNode *helpers.Node, userID, postID uuid.UUID) uuid.UUID {
+	file := utils.FileMetadata{
+		ID:          uuid.New(),
+		URL:         "https://cdn.example.com/audience.jpg",
+		StoragePath: "test/audience.jpg",
+		MimeType:    "image/jpeg",
+		Size:        1,
+		Name:        "audience.jpg",
+	}
+	if err := db.Create(&file).Error; err != nil {
+		fmt.Println("create file error:", err)
+	}
+	m := media.Media{
+		ID:        uuid.New(),
+		PublicID:  snowFlakeNode.Generate().Int64(),
+		FileID:    file.ID,
+		OwnerID:   postID,
+		OwnerType: media.OwnerPost,
+		UserID:    userID,
+		Role:      media.RolePost,
+		IsPublic:  true,
+	}
+	if err := db.Create(&m).Error; err != nil {
+		fmt.Println("create media error:", err)
+	}
+	return m.ID
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/repositories"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// testPostDelete silinen postun yanıtlarıyla birlikte tombstone olduğunu ve
+// ilişkili kayıtların temizlendiğini doğrular
+func testPostDelete(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	ctx := context.Background()
+	postRepo, engagementRepo := newPostRepos(db, snowFlakeNode)
+
+	author := faker.CreateUser(db, snowFlakeNode)
+	replier := faker.CreateUser(db, snowFlakeNode)
+	tag := fmt.Sprintf("deleteme%d", snowFlakeNode.Generate().Int64())
+
+	root := createPost(postRepo, &author, map[string][]string{
+		"content": {"to be deleted #" + tag},
+	})
+	if root == nil {
+		return
+	}
+	reply := createPost(postRepo, &replier, map[string][]string{
+		"content":      {"reply #" + tag},
+		"parentPostId": {fmt.Sprint(root.PublicID)},
+	})
+	other := createPost(postRepo, &replier, map[string][]string{
+		"content":      {"second reply"},
+		"parentPostId": {fmt.Sprint(root.PublicID)},
+	})
+	if reply == nil || other == nil {
+		return
+	}
+	if _, err := engagementRepo.ToggleEngagement(ctx, replier.ID, author.ID, models.EngagementKindLikeReceived, root.ID, models.EngagementContentableTypePost); err != nil {
+		fmt.Println("like error:", err)
+	}
+	if _, err := engagementRepo.ToggleEngagement(ctx, author.ID, replier.ID, models.EngagementKindLikeReceived, reply.ID, models.EngagementContentableTypePost); err != nil {
+		fmt.Println("like error:", err)
+	}
+
+	// Tek yanıt silinince üst postun yorum sayacı düşer
+	if _, err := postRepo.DeletePost(other, replier.ID); err != nil {
+		fmt.Println("delete reply error:", err)
+		return
+	}
+	check("comment count decremented", commentCount(db, root) == 1, commentCount(db, root))
+	// Aynı yazarın ilk yanıtının kaydı değil, silinen yanıtın kaydı kalkar
+	replyDetail, _ := reply.GetExtra(post.ExtraParentEngagementID)
+	var details int64
+	db.Model(&models.EngagementDetail{}).Where("id = ?", replyDetail).Count(&details)
+	check("other reply's comment kept", details == 1, replyDetail)
+
+	// Post eski bir saatte özetlenmiş olsun; artımlı hesap o saate dönmez
+	var buckets int64
+	db.Model(&models.Hashtag{}).Where("taggable_id = ?", root.ID).Update("created_at", time.Now().Add(-3*time.Hour))
+	postRepo.RollupTrends(time.Now().Add(-4*time.Hour), repositories.TrendBaseline)
+	db.Model(&models.HashtagTrendBucket{}).Where("tag = ?", strings.ToLower(tag)).Count(&buckets)
+	check("rolled up before delete", buckets >= 2, buckets)
+
+	deleted, err := postRepo.DeletePost(root, author.ID)
+	check("delete post", err == nil && len(deleted) == 2, err, deleted)
+
+	_, err = postRepo.FindPostByPublicID(root.PublicID)
+	check("deleted post hidden", err != nil)
+	_, err = postRepo.FindPostByPublicID(reply.PublicID)
+	check("reply hidden", err != nil)
+
+	var tombstone post.Post
+	db.Unscoped().First(&tombstone, "id = ?", reply.ID)
+	deletedWith, _ := tombstone.GetExtra(post.ExtraDeletedWith)
+	check("reply tombstoned, not removed", tombstone.DeletedAt.Valid && deletedWith == root.ID.String(), deletedWith)
+
+	var hashtags, engagements int64
+	db.Model(&models.Hashtag{}).Where("taggable_id = ?", root.ID).Count(&hashtags)
+	db.Model(&models.Engagement{}).Where("contentable_id = ?", root.ID).Count(&engagements)
+	check("hashtags removed", hashtags == 0)
+	check("engagements removed", engagements == 0)
+	db.Model(&models.Hashtag{}).Where("taggable_id = ?", reply.ID).Count(&hashtags)
+	db.Model(&models.Engagement{}).Where("contentable_id = ?", reply.ID).Count(&engagements)
+	check("reply hashtags kept", hashtags == 1, hashtags)
+	check("reply engagements kept", engagements == 1, engagements)
+
+	postRepo.RollupRecentTrends(repositories.TrendBaseline)
+	db.Model(&models.HashtagTrendBucket{}).Where("tag = ?", strings.ToLower(tag)).Count(&buckets)
+	check("deleted post not trending", buckets == 0, buckets)
+
+	cursor := int64(math.MaxInt64)
+	posts, _ := postRepo.GetUserPosts(author.ID, &cursor, 50, &author.ID)
+	check("deleted post not in profile", !containsPost(posts, root.ID))
+	timeline, _ := postRepo.GetTimeline(200, nil, nil)
+	check("deleted post not in timeline", !containsPost(timeline.Posts, root.ID))
+}
+
+func commentCount(db *gorm.DB, p *post.Post) float64 {
+	var engagement models.Engagement
+	if err := db.Where("contentable_id = ? AND contentable_type = ?", p.ID, models.EngagementContentableTypePost).First(&engagement).Error; err != nil {
+		return -1
+	}
+	counts := map[string]float64{}
+	json.Unmarshal(engagement.Counts, &counts)
+	return counts[models.EngagementCountKeys[models.EngagementKindComment].CountKey]
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// testPostEdit hashtag/mention çıkarmayı ve düzenleme revizyonlarını dener
+func testPostEdit(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	tags := helpers.ExtractHashtags("Pride #Istanbul, #pride and #istanbul again; a&#39; not#tag", []string{"#Love"})
+	check("extract hashtags", strings.Join(tags, ",") == "Love,Istanbul,pride", tags)
+
+	mentions := helpers.ExtractMentions("hi @jane.doe. and @bob, mail me at me@example.com", nil)
+	check("extract mentions", strings.Join(mentions, ",") == "jane.doe,bob", mentions)
+
+	postRepo, _ := newPostRepos(db, snowFlakeNode)
+
+	author := faker.CreateUser(db, snowFlakeNode)
+	mentioned := faker.CreateUser(db, snowFlakeNode)
+
+	created := createPost(postRepo, &author, map[string][]string{
+		"content": {"first version #before"},
+	})
+	if created == nil {
+		return
+	}
+
+	added, err := postRepo.UpdateContentablePost(created, map[string][]string{
+		"content": {"second version #after @" + mentioned.UserName},
+	}, nil, &author)
+	check("edit post", err == nil, err)
+	check("new mention returned", len(added) == 1 && added[0] == mentioned.ID, added)
+	check("edited marker", created.EditedAt != nil)
+
+	var hashtags []models.Hashtag
+	db.Where("taggable_id = ? AND taggable_type = ?", created.ID, "post").Find(&hashtags)
+	check("hashtags re-extracted", len(hashtags) == 1 && hashtags[0].Tag == "after", hashtags)
+
+	// Aynı mention tekrar bildirilmez
+	added, err = postRepo.UpdateContentablePost(created, map[string][]string{
+		"title": {"now with a title"},
+	}, nil, &author)
+	check("edit title keeps mentions", err == nil && len(added) == 0, err, added)
+
+	revisions, err := postRepo.GetPostRevisions(created.ID)
+	check("revision history", err == nil && len(revisions) == 2 && revisions[0].Revision == 1 && revisions[1].Revision == 2, err)
+	if len(revisions) == 2 {
+		check("first revision keeps old content", (*revisions[0].Content)[author.DefaultLanguage] == "first version #before")
+		check("first revision keeps old hashtags", len(revisions[0].Hashtags) == 1 && revisions[0].Hashtags[0] == "before", revisions[0].Hashtags)
+	}
+}
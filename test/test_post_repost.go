@@ -0,0 +1,95 @@
+package test
+
+import (
+	"coolvibes/constants"
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/repositories"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// testPostRepost repost ve alıntı sayaçlarını ve orijinal silindiğinde
+// repostların nasıl gösterildiğini dener
+func testPostRepost(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	postRepo, _ := newPostRepos(db, snowFlakeNode)
+
+	author := faker.CreateUser(db, snowFlakeNode)
+	reposter := faker.CreateUser(db, snowFlakeNode)
+
+	original := createPost(postRepo, &author, map[string][]string{
+		"content":  {"worth sharing"},
+		"audience": {string(constants.PrivacyPublic)},
+	})
+	if original == nil {
+		return
+	}
+
+	repost, err := postRepo.CreateRepost(original, &reposter)
+	check("create repost", err == nil && repost.PostKind == post.PostTypeRepost, err)
+	if err != nil {
+		return
+	}
+	quote, err := postRepo.CreateContentablePost(map[string][]string{
+		"content":     {"my take"},
+		"quotePostId": {fmt.Sprint(original.PublicID)},
+	}, nil, &reposter, "post", nil)
+	check("create quote", err == nil && quote.PostKind == post.PostTypeQuote, err)
+	if err != nil {
+		return
+	}
+	check("repost counted", engagementCount(db, original, models.EngagementKindRepost) == 1)
+	check("quote counted", engagementCount(db, original, models.EngagementKindQuote) == 1)
+
+	// Repostun repostu orijinali hedefler
+	target, err := postRepo.ResolveRepostTarget(repost.PublicID)
+	check("repost resolves to original", err == nil && target.ID == original.ID, err)
+
+	private := createPost(postRepo, &author, map[string][]string{
+		"content":  {"only for followers"},
+		"audience": {string(constants.PrivacyFollowersOnly)},
+	})
+	if private != nil {
+		_, err = postRepo.CreateRepost(private, &author)
+		check("non-public repost rejected", errors.Is(err, repositories.ErrRepostNotAllowed), err)
+	}
+
+	timeline, _ := postRepo.GetUserPosts(reposter.ID, nil, 10, nil)
+	var shared *post.Post
+	for i := range timeline {
+		if timeline[i].ID == repost.ID {
+			shared = &timeline[i]
+		}
+	}
+	check("repost attributed to reposter", shared != nil && shared.AuthorID == reposter.ID)
+	check("repost carries original", shared != nil && shared.RepostOf != nil && shared.RepostOf.ID == original.ID)
+
+	if _, err := postRepo.DeletePost(original, author.ID); err != nil {
+		fmt.Println("delete post error:", err)
+		return
+	}
+	_, err = postRepo.FindPostByID(repost.ID)
+	check("repost removed with original", err != nil)
+
+	timeline, _ = postRepo.GetUserPosts(reposter.ID, nil, 10, nil)
+	quoteStillShown := false
+	for _, p := range timeline {
+		if p.ID == quote.ID {
+			quoteStillShown = p.RepostOf == nil && p.RepostUnavailable
+		}
+	}
+	check("quote shows unavailable original", quoteStillShown)
+}
+
+func engagementCount(db *gorm.DB, p *post.Post, kind models.EngagementKind) int64 {
+	var count int64
+	db.Model(&models.EngagementDetail{}).
+		Joins("JOIN engagements ON engagements.id = engagement_details.engagement_id").
+		Where("engagements.contentable_id = ? AND engagement_details.kind = ?", p.ID, kind).
+		Count(&count)
+	return count
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/repositories"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// testPostSchedule taslakların gizli kaldığını ve yan etkilerin yayın anında
+// çalıştığını dener
+func testPostSchedule(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	postRepo, _ := newPostRepos(db, snowFlakeNode)
+
+	author := faker.CreateUser(db, snowFlakeNode)
+	mentioned := faker.CreateUser(db, snowFlakeNode)
+
+	draft, err := postRepo.CreateContentablePost(map[string][]string{
+		"content":    {"coming soon #draft @" + mentioned.UserName},
+		"hashtags[]": {"Extra"},
+		"status":     {repositories.PostStatusDraft},
+	}, nil, &author, "post", nil)
+	check("create draft", err == nil && !draft.Published, err)
+	if err != nil {
+		return
+	}
+
+	var hashtags int64
+	db.Model(&models.Hashtag{}).Where("taggable_id = ?", draft.ID).Count(&hashtags)
+	check("draft not indexed", hashtags == 0, hashtags)
+	visible, _ := postRepo.CanViewPost(draft.ID, &author.ID)
+	check("draft hidden from reads", !visible)
+	drafts, _ := postRepo.GetDrafts(author.ID, 10, nil)
+	check("draft listed", containsPost(drafts.Posts, draft.ID))
+
+	oldPublicID := draft.PublicID
+	mentions, err := postRepo.PublishPost(draft)
+	check("publish draft", err == nil && draft.Published && draft.PublicID != oldPublicID, err)
+	check("mentions resolved at publish", len(mentions) == 1 && mentions[0] == mentioned.ID, mentions)
+	db.Model(&models.Hashtag{}).Where("taggable_id = ?", draft.ID).Count(&hashtags)
+	check("hashtags indexed at publish", hashtags == 2, hashtags)
+	visible, _ = postRepo.CanViewPost(draft.ID, nil)
+	check("published post visible", visible)
+
+	_, err = postRepo.PublishPost(draft)
+	check("publish only once", errors.Is(err, repositories.ErrPostAlreadyPublished), err)
+
+	_, err = postRepo.CreateContentablePost(map[string][]string{
+		"content":      {"too late"},
+		"status":       {repositories.PostStatusScheduled},
+		"scheduled_at": {time.Now().Add(-time.Hour).Format(time.RFC3339)},
+	}, nil, &author, "post", nil)
+	check("past schedule rejected", errors.Is(err, repositories.ErrInvalidSchedule), err)
+
+	scheduled, err := postRepo.CreateContentablePost(map[string][]string{
+		"content":      {"later"},
+		"status":       {repositories.PostStatusScheduled},
+		"scheduled_at": {time.Now().Add(time.Hour).Format(time.RFC3339)},
+	}, nil, &author, "post", nil)
+	check("create scheduled", err == nil && scheduled.ScheduledAt != nil, err)
+	if err != nil {
+		return
+	}
+	list, _ := postRepo.GetScheduledPosts(author.ID)
+	check("scheduled listed", containsPost(list, scheduled.ID))
+	due, _ := postRepo.GetDueScheduledPosts(1000)
+	check("future post not due", !containsPost(due, scheduled.ID))
+
+	db.Model(&post.Post{}).Where("id = ?", scheduled.ID).Update("scheduled_at", time.Now().Add(-time.Minute))
+	due, _ = postRepo.GetDueScheduledPosts(1000)
+	check("due post picked up", containsPost(due, scheduled.ID))
+
+	err = postRepo.SetSchedule(scheduled, nil)
+	due, _ = postRepo.GetDueScheduledPosts(1000)
+	check("cancel keeps draft", err == nil && !containsPost(due, scheduled.ID), err)
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"coolvibes/faker"
+	"coolvibes/helpers"
+	"coolvibes/models"
+	"coolvibes/models/post"
+	"coolvibes/types"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testPostThread ata zincirini ve yanıtların dal başına sayfalanmasını dener
+func testPostThread(db *gorm.DB, snowFlakeNode *helpers.Node) {
+	postRepo, engagementRepo := newPostRepos(db, snowFlakeNode)
+
+	author := faker.CreateUser(db, snowFlakeNode)
+	replier := faker.CreateUser(db, snowFlakeNode)
+
+	reply := func(parent *post.Post, content string) *post.Post {
+		return createPost(postRepo, &replier, map[string][]string{
+			"content":      {content},
+			"parentPostId": {fmt.Sprint(parent.PublicID)},
+		})
+	}
+
+	root := createPost(postRepo, &author, map[string][]string{
+		"content": {"start of a conversation"},
+	})
+	if root == nil {
+		return
+	}
+	first, second, third := reply(root, "first"), reply(root, "second"), reply(root, "third")
+	if first == nil || second == nil || third == nil {
+		return
+	}
+	nested := reply(first, "nested")
+	deepest := reply(nested, "deepest")
+	if deepest == nil {
+		return
+	}
+
+	ancestors, err := postRepo.GetPostAncestors(deepest, nil)
+	check("ancestor chain", err == nil && len(ancestors) == 3 &&
+		ancestors[0].ID == root.ID && ancestors[1].ID == first.ID && ancestors[2].ID == nested.ID, err, len(ancestors))
+
+	// En çok etkileşim alan yanıt başa gelir
+	engagementRepo.ToggleEngagement(context.Background(), author.ID, replier.ID, models.EngagementKindLikeReceived, second.ID, models.EngagementContentableTypePost)
+	replies, err := postRepo.GetReplies(types.ReplyQuery{ParentIDs: []uuid.UUID{root.ID}, Sort: types.ReplySortTop, PerParent: 2}, nil)
+	check("top replies", err == nil && len(replies) == 2 && replies[0].ID == second.ID, err, replies)
+
+	if len(replies) == 2 {
+		last := replies[1]
+		more, err := postRepo.GetReplies(types.ReplyQuery{
+			ParentIDs: []uuid.UUID{root.ID},
+			Sort:      types.ReplySortTop,
+			PerParent: 2,
+			After:     &types.ReplyCursor{Score: last.Score, PublicID: last.PublicID},
+		}, nil)
+		check("load more replies", err == nil && len(more) == 1 && more[0].ID != second.ID && more[0].ID != last.ID, err, more)
+	}
+
+	oldest, err := postRepo.GetReplies(types.ReplyQuery{ParentIDs: []uuid.UUID{root.ID, first.ID}, Sort: types.ReplySortOldest, PerParent: 1}, nil)
+	perBranch := map[uuid.UUID]uuid.UUID{}
+	for _, r := range oldest {
+		perBranch[r.ParentID] = r.ID
+	}
+	check("limit per branch", err == nil && len(oldest) == 2 && perBranch[root.ID] == first.ID && perBranch[first.ID] == nested.ID, err, oldest)
+}
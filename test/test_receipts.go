@@ -0,0 +1,23 @@
+package test
+
+import "coolvibes/models/chat"
+
+// testReceiptStatus mesaj iletim durumunun alıcı sayılarından hesaplanmasını dener
+func testReceiptStatus() {
+	cases := []struct {
+		name                        string
+		recipients, delivered, seen int64
+		want                        chat.MessageStatus
+	}{
+		{"no recipients", 0, 0, 0, chat.Pending},
+		{"not delivered", 2, 0, 0, chat.Pending},
+		{"partially delivered", 3, 2, 1, chat.Pending},
+		{"delivered to all", 3, 3, 1, chat.Delivered},
+		{"seen by all", 3, 3, 3, chat.Seen},
+		{"left participant counted", 2, 3, 3, chat.Seen},
+	}
+	for _, c := range cases {
+		got := chat.ReceiptStatus(c.recipients, c.delivered, c.seen)
+		check("receipt "+c.name, got == c.want, got)
+	}
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	services "coolvibes/services/user"
+	"coolvibes/types"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type typingEvent struct {
+	userID  uuid.UUID
+	typing  bool
+	users   int
+	summary string
+}
+
+// testTypingTracker yazıyor bildirimlerinin throttle ve otomatik süre dolumunu dener
+func testTypingTracker() {
+	var mu sync.Mutex
+	var events []typingEvent
+	tracker := services.NewTypingTracker(func(chatID, userID uuid.UUID, typing bool, users []types.TypingUser, summary string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, typingEvent{userID, typing, len(users), summary})
+	})
+	published := func() []typingEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]typingEvent(nil), events...)
+	}
+
+	chatID := uuid.New()
+	ali := types.TypingUser{ID: uuid.New(), UserName: "ali"}
+	ayse := types.TypingUser{ID: uuid.New(), UserName: "ayse", DisplayName: "Ayşe"}
+
+	tracker.Start(chatID, ali)
+	tracker.Start(chatID, ali)
+	check("typing throttled", len(published()) == 1 && published()[0].summary == "ali is typing", published())
+
+	tracker.Start(chatID, ayse)
+	got := published()
+	check("second user typing", len(got) == 2 && got[1].summary == "ali and Ayşe are typing", got)
+
+	tracker.Stop(chatID, ali.ID)
+	tracker.Stop(chatID, ali.ID)
+	got = published()
+	check("stop once", len(got) == 3 && !got[2].typing && got[2].users == 1, got)
+
+	// Ayşe tekrar yazmazsa süresi dolar
+	time.Sleep(7 * time.Second)
+	got = published()
+	check("typing expires", len(got) == 4 && got[3].userID == ayse.ID && !got[3].typing && got[3].users == 0, got)
+
+	crowd := uuid.New()
+	for _, name := range []string{"a", "b", "c", "d"} {
+		tracker.Start(crowd, types.TypingUser{ID: uuid.New(), UserName: name})
+		time.Sleep(time.Millisecond)
+	}
+	got = published()
+	check("typing summary with others", got[len(got)-1].summary == "a, b and 2 others are typing", got[len(got)-1])
+}
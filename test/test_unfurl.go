@@ -0,0 +1,127 @@
+package test
+
+import (
+	"context"
+	"coolvibes/services/unfurl"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+const fixtureOpenGraph = `<!doctype html>
+<html><head>
+<title>Fallback title</title>
+<meta property="og:title" content="Pride &amp; Joy">
+<meta property="og:description" content="An   OpenGraph
+	description">
+<meta property="og:image" content="/images/cover.jpg">
+<meta property="og:site_name" content="Fixture">
+<meta property="og:type" content="article">
+<link rel="canonical" href="/articles/1">
+</head><body><meta property="og:title" content="ignored body tag"></body></html>`
+
+const fixtureTwitter = `<html><head>
+<meta name="twitter:card" content="summary_large_image">
+<meta name='twitter:title' content='Twitter title'>
+<meta name="twitter:description" content="Twitter description">
+<meta name="twitter:image" content="https://cdn.example.com/t.png">
+</head></html>`
+
+const fixtureOEmbedPage = `<html><head>
+<title>Video page</title>
+<link rel="alternate" type="application/json+oembed" href="/oembed.json">
+</head></html>`
+
+const fixtureOEmbed = `{"type":"video","title":"oEmbed title","author_name":"Fixture Author",
+"provider_name":"FixtureTube","thumbnail_url":"https://cdn.example.com/thumb.jpg",
+"thumbnail_width":480,"thumbnail_height":360,"html":"<script>alert(1)</script>"}`
+
+func newUnfurlFixtureServer() *httptest.Server {
+	mux := http.NewServeMux()
+	html := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, body)
+		}
+	}
+	mux.HandleFunc("/og", html(fixtureOpenGraph))
+	mux.HandleFunc("/twitter", html(fixtureTwitter))
+	mux.HandleFunc("/video", html(fixtureOEmbedPage))
+	mux.HandleFunc("/oembed.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, fixtureOEmbed)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		// Metadata boyut sınırının ötesinde kalır
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><head>"+strings.Repeat(" ", 64*1024)+`<meta property="og:title" content="too far"></head></html>`)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Second)
+		html(fixtureOpenGraph)(w, r)
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte{0x89, 'P', 'N', 'G'})
+	})
+	mux.HandleFunc("/count", func(w http.ResponseWriter, r *http.Request) {
+		fixtureHits++
+		html(fixtureOpenGraph)(w, r)
+	})
+	return httptest.NewServer(mux)
+}
+
+var fixtureHits int
+
+// testUnfurl bağlantı önizlemelerini yerel fixture sunucusuna karşı dener
+func testUnfurl() {
+	server := newUnfurlFixtureServer()
+	defer server.Close()
+
+	cfg := unfurl.DefaultConfig()
+	cfg.AllowPrivateNetworks = true // fixture 127.0.0.1 üzerinde
+	cfg.MaxBodyBytes = 16 * 1024
+	cfg.Timeout = time.Second
+	u := unfurl.New(cfg)
+	ctx := context.Background()
+
+	p, err := u.Unfurl(ctx, server.URL+"/og")
+	check("opengraph", err == nil && p.Title == "Pride & Joy" && p.Description == "An OpenGraph description" &&
+		p.Image == server.URL+"/images/cover.jpg" && p.CanonicalURL == server.URL+"/articles/1", p, err)
+
+	p, err = u.Unfurl(ctx, server.URL+"/twitter")
+	check("twitter card", err == nil && p.Title == "Twitter title" && p.TwitterCard == "summary_large_image" &&
+		p.Image == "https://cdn.example.com/t.png", p, err)
+
+	p, err = u.Unfurl(ctx, server.URL+"/video")
+	check("oembed", err == nil && p.Title == "Video page" && p.AuthorName == "Fixture Author" &&
+		p.SiteName == "FixtureTube" && p.Type == "video" && p.ImageWidth == "480", p, err)
+
+	_, err = u.Unfurl(ctx, server.URL+"/large")
+	check("size limit", errors.Is(err, unfurl.ErrNoMetadata), err)
+
+	_, err = u.Unfurl(ctx, server.URL+"/slow")
+	check("timeout", err != nil, err)
+
+	_, err = u.Unfurl(ctx, server.URL+"/image")
+	check("non html", errors.Is(err, unfurl.ErrNotHTML), err)
+
+	_, err = u.Unfurl(ctx, "file:///etc/passwd")
+	check("scheme", errors.Is(err, unfurl.ErrInvalidURL), err)
+
+	u.Unfurl(ctx, server.URL+"/count")
+	u.Unfurl(ctx, server.URL+"/count#fragment")
+	check("cache", fixtureHits == 1, fixtureHits)
+
+	// Varsayılan ayarlarla yerel ve iç ağ adresleri engellenir
+	strict := unfurl.New(unfurl.DefaultConfig())
+	_, err = strict.Unfurl(ctx, server.URL+"/og")
+	check("ssrf loopback", errors.Is(err, unfurl.ErrBlockedAddress), err)
+	_, err = strict.Unfurl(ctx, "http://169.254.169.254/latest/meta-data/")
+	check("ssrf metadata ip", errors.Is(err, unfurl.ErrBlockedAddress), err)
+
+	check("first url", unfurl.FirstURL("see https://example.com/a?b=1. thanks") == "https://example.com/a?b=1")
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"bytes"
+	"coolvibes/models/utils"
+	"coolvibes/repositories"
+	"encoding/binary"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+)
+
+func pcm(samples ...int16) []byte {
+	out := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(s))
+	}
+	return out
+}
+
+// uploadedFile içeriği ve istemcinin bildirdiği tipiyle yüklenmiş bir dosya
+func uploadedFile(content []byte, contentType string) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="files"; filename="upload"`)
+	header.Set("Content-Type", contentType)
+	part, _ := writer.CreatePart(header)
+	part.Write(content)
+	writer.Close()
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		return nil
+	}
+	return form.File["files"][0]
+}
+
+// testVoiceWaveform sesli mesaj dalga formunun hesaplanmasını ve ses
+// varyantlarının veritabanı alanına yazılıp okunmasını dener
+func testVoiceWaveform() {
+	peaks, err := repositories.WaveformPeaks(pcm(100, -200, 50, 0, 0, 0, 400, -400), 4)
+	check("waveform normalized", err == nil && fmt.Sprint(peaks) == "[50 12 0 100]", err, peaks)
+
+	peaks, err = repositories.WaveformPeaks(pcm(0, 0, 0, 0), 2)
+	check("silent waveform", err == nil && fmt.Sprint(peaks) == "[0 0]", err, peaks)
+
+	peaks, err = repositories.WaveformPeaks(pcm(-32768, 10), 64)
+	check("short recording", err == nil && fmt.Sprint(peaks) == "[100 0]", err, peaks)
+
+	_, err = repositories.WaveformPeaks(nil, 64)
+	check("empty recording", err != nil)
+
+	variants := utils.FileVariants{Audio: &utils.AudioVariants{
+		Opus:     &utils.VariantInfo{URL: "/voice_opus.ogg", Format: "ogg"},
+		Waveform: []int{10, 100},
+	}}
+	value, err := variants.Value()
+	var scanned utils.FileVariants
+	if err == nil {
+		err = scanned.Scan(value)
+	}
+	check("audio variants round trip", err == nil && scanned.Audio != nil && scanned.Audio.Opus.URL == "/voice_opus.ogg" &&
+		fmt.Sprint(scanned.Audio.Waveform) == "[10 100]", err)
+
+	// Dosya tipi istemcinin bildirdiği tipten değil içerikten belirlenir
+	mp3 := append([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"), make([]byte, 32)...)
+	check("sniff audio sent as file", repositories.DetectMimeType(uploadedFile(mp3, "application/octet-stream")) == "audio/mpeg")
+	m4a := append([]byte("\x00\x00\x00\x18ftypM4A \x00\x00\x00\x00mp42isom"), make([]byte, 32)...)
+	check("voice in mp4 container", repositories.DetectMimeType(uploadedFile(m4a, "audio/mp4")) == "audio/mp4")
+	check("fake voice header", repositories.DetectMimeType(uploadedFile([]byte("plain text"), "audio/mpeg")) != "audio/mpeg")
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"coolvibes/models/post"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TypingUser, sohbette o an yazmakta olan kullanıcı
+type TypingUser struct {
+	ID          uuid.UUID `json:"id"`
+	PublicID    int64     `json:"public_id"`
+	UserName    string    `json:"username"`
+	DisplayName string    `json:"displayname"`
+}
+
+// MessagePage chat.fetch_messages için cursor parametreleri (PublicID)
+type MessagePage struct {
+	Limit  int
+	Before *int64
+	After  *int64
+	Around *int64
+}
+
+type MessagesResult struct {
+	Messages   []post.Post `json:"messages"`
+	NextCursor *string     `json:"next_cursor"` // daha eski mesajlar (before)
+	PrevCursor *string     `json:"prev_cursor"` // daha yeni mesajlar (after)
+}
+
+// MessageSearch chat.search filtreleri
+type MessageSearch struct {
+	Query          string
+	ChatID         *uuid.UUID
+	SenderID       *uuid.UUID
+	From           *time.Time
+	To             *time.Time
+	AttachmentType string // image, video, audio, file
+	Limit          int
+	Before         *int64 // PublicID cursor'ı, sonuçlar yeniden eskiye
+}
+
+type MessageSearchHit struct {
+	Message post.Post `json:"message"`
+	Snippet string    `json:"snippet"` // HTML'e kaçırılmış metin, eşleşen kelimeler <mark> ile işaretli
+	Rank    float64   `json:"rank"`
+}
+
+type MessageSearchResult struct {
+	Hits       []MessageSearchHit `json:"hits"`
+	NextCursor *string            `json:"next_cursor"`
+}
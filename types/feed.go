@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeedCandidate "Senin İçin" akışına aday post ve skorlamada kullanılan sinyaller
+type FeedCandidate struct {
+	PostID    uuid.UUID `json:"post_id"`
+	PublicID  int64     `json:"public_id,string"`
+	AuthorID  uuid.UUID `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Source    string    `json:"source"` // engagement, hashtag
+
+	Engagement        float64  `json:"engagement"`         // Engagement.Counts ağırlıklı toplamı
+	RecentEngagements int64    `json:"recent_engagements"` // Son saatlerdeki etkileşim sayısı
+	Affinity          float64  `json:"affinity"`           // İzleyicinin yazarla geçmiş etkileşimleri
+	Following         bool     `json:"following"`
+	DistanceMeters    *float64 `json:"distance_meters,omitempty"` // Post (yoksa yazar) konumuna uzaklık
+	Languages         string   `json:"languages"`                 // İçerik dilleri, virgülle ayrılmış
+}
+
+// RankedCandidate skorlanmış aday; Signals her sinyalin normalize değeri
+type RankedCandidate struct {
+	FeedCandidate
+	Score   float64            `json:"score"`
+	Signals map[string]float64 `json:"signals"`
+}
+
+// FeedCandidateQuery aday üretimi parametreleri
+type FeedCandidateQuery struct {
+	Since         time.Time // Bu tarihten eski postlar aday olmaz
+	PoolSize      int       // Her kaynaktan en fazla aday
+	VelocitySince time.Time // Hız sinyali için sayılan etkileşimlerin başlangıcı
+	AffinitySince time.Time // Yakınlık ve ilgi alanı (hashtag) için geçmiş
+}
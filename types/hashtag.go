@@ -1,6 +1,48 @@
 package types
 
-type HashtagStats struct {
+import "time"
+
+// Trend kapsamları; ülke ve şehir postun konumundan alınır
+const (
+	TrendScopeGlobal  = "global"
+	TrendScopeCountry = "country"
+	TrendScopeCity    = "city"
+)
+
+// TrendQuery trend hesabı parametreleri
+type TrendQuery struct {
+	ScopeType string
+	Scope     string        // Ülke kodu ya da "ülke/şehir"; global için boş
+	Window    time.Duration // Şimdiki kullanım hızının ölçüldüğü pencere
+	Limit     int
+}
+
+// HashtagTrend penceredeki kullanım sayısı, önceki pencerelerin ortalaması ve
+// ortalamadan sapmanın z-skoru
+type HashtagTrend struct {
+	Tag      string  `json:"tag"`
+	Count    int64   `json:"count"`
+	Baseline float64 `json:"baseline"`
+	Score    float64 `json:"score"`
+}
+
+// HashtagInfo hashtag sayfasının bilgileri; sayılar herkese açık postlardan hesaplanır
+type HashtagInfo struct {
+	Tag         string           `json:"tag"`
+	PostCount   int64            `json:"post_count"`
+	FirstSeen   *time.Time       `json:"first_seen,omitempty"`
+	RelatedTags []RelatedHashtag `json:"related_tags"`
+	Following   bool             `json:"following"`
+}
+
+// RelatedHashtag etiketle aynı postlarda kullanılan başka bir etiket
+type RelatedHashtag struct {
 	Tag   string `json:"tag"`
 	Count int64  `json:"count"`
 }
+
+// HashtagPage etiket sayfası; etiket bilgileri yalnızca ilk sayfada döner
+type HashtagPage struct {
+	TimelineResult
+	Hashtag *HashtagInfo `json:"hashtag,omitempty"`
+}
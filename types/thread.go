@@ -0,0 +1,45 @@
+package types
+
+import (
+	"coolvibes/models/post"
+
+	"github.com/google/uuid"
+)
+
+// ReplySort yanıtların sıralaması
+type ReplySort string
+
+const (
+	ReplySortTop    ReplySort = "top"    // Etkileşime göre
+	ReplySortNewest ReplySort = "newest" // Yeniden eskiye
+	ReplySortOldest ReplySort = "oldest" // Eskiden yeniye
+)
+
+// ThreadResult postun kökten başlayan ata zinciri ve yanıt ağacı
+type ThreadResult struct {
+	Ancestors  []post.Post `json:"ancestors"`
+	Post       *post.Post  `json:"post"`
+	NextCursor *string     `json:"next_cursor"` // Odak postun sonraki yanıt sayfası
+}
+
+// ReplyCursor bir dalda son gösterilen yanıtın konumu
+type ReplyCursor struct {
+	Score    float64
+	PublicID int64
+}
+
+// ReplyQuery bir seviyedeki yanıtları getirme parametreleri
+type ReplyQuery struct {
+	ParentIDs []uuid.UUID
+	Sort      ReplySort
+	After     *ReplyCursor // Yalnızca tek dal sayfalanırken kullanılır
+	PerParent int          // Her üst post için en fazla yanıt
+}
+
+// ThreadReply yanıtın ağaçtaki yeri ve sıralama skoru
+type ThreadReply struct {
+	ID       uuid.UUID
+	ParentID uuid.UUID
+	PublicID int64
+	Score    float64
+}